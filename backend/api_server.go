@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"todo-app/backend/internal/api"
+)
+
+// startAPI serves the REST API next to the window when TODOAPP_HTTP_ADDR is
+// set. It returns nil when the API is disabled.
+func startAPI(addr string, uc api.Usecases) *http.Server {
+	if addr == "" {
+		return nil
+	}
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           api.NewServer(uc).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("REST API listening on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("REST API stopped: %v", err)
+		}
+	}()
+	return srv
+}
+
+func stopAPI(srv *http.Server) {
+	if srv == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("REST API shutdown: %v", err)
+	}
+}
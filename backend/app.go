@@ -2,615 +2,1106 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
 
-	"github.com/lib/pq"
-	_ "github.com/lib/pq"
+	"todo-app/backend/internal/dto"
+	"todo-app/backend/internal/markdown"
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+	"todo-app/backend/internal/usecase"
+
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
-	ctx context.Context
-	db  *sql.DB
+	ctx        context.Context
+	session    session
+	auth       usecase.AuthUsecase
+	tasks      usecase.TaskUsecase
+	categories usecase.CategoryUsecase
+	priorities usecase.PriorityUsecase
+	tags       usecase.TagUsecase
+	stats      usecase.StatsUsecase
+	views      usecase.ViewUsecase
+	trash      usecase.TrashUsecase
 }
 
-func NewApp(db *sql.DB) *App {
-	return &App{db: db}
+func NewApp(auth usecase.AuthUsecase, tasks usecase.TaskUsecase, categories usecase.CategoryUsecase, priorities usecase.PriorityUsecase, tags usecase.TagUsecase, stats usecase.StatsUsecase, views usecase.ViewUsecase, trash usecase.TrashUsecase) *App {
+	return &App{session: session{auth: auth}, auth: auth, tasks: tasks, categories: categories, priorities: priorities, tags: tags, stats: stats, views: views, trash: trash}
 }
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-type TaskDTO struct {
-	ID          int64    `json:"id"`
-	Title       string   `json:"title"`
-	Priority    string   `json:"priority"`
-	Completed   bool     `json:"completed"`
-	CreatedAt   string   `json:"createdAt"`
-	CompletedAt *string  `json:"completedAt,omitempty"`
-	DueDate     *string  `json:"dueDate,omitempty"`
-	CategoryID  *int64   `json:"categoryId,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
-}
+type TaskDTO dto.Task
 
 type SubtaskDTO struct {
-	ID        int64  `json:"id"`
-	TaskID    int64  `json:"taskId"`
-	Title     string `json:"title"`
-	Completed bool   `json:"completed"`
-	CreatedAt string `json:"createdAt"`
+	ID        int64   `json:"id"`
+	TaskID    int64   `json:"taskId"`
+	Title     string  `json:"title"`
+	Completed bool    `json:"completed"`
+	CreatedAt string  `json:"createdAt"`
+	Version   int64   `json:"version"`
+	DeletedAt *string `json:"deletedAt,omitempty"`
+}
+
+type UserDTO struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
 }
 
 type CategoryDTO struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	ParentID   *int64  `json:"parentId,omitempty"`
+	Position   int     `json:"position"`
+	Color      string  `json:"color"`
+	Icon       string  `json:"icon"`
+	TaskCount  int     `json:"taskCount"`
+	TotalCount int     `json:"totalCount"`
+	Version    int64   `json:"version"`
+	DeletedAt  *string `json:"deletedAt,omitempty"`
 }
 
-type StatsDTO struct {
-	Total     int64 `json:"total"`
-	Active    int64 `json:"active"`
-	Completed int64 `json:"completed"`
-	Overdue   int64 `json:"overdue"`
+// ConflictDTO is the error a binding returns when an edit was based on an
+// outdated version. Wails hands the frontend only the error message, so the
+// message is this struct as JSON, with the stored state in whichever of
+// Task, Subtask, Category, Tag and Priority the edit was for.
+type ConflictDTO struct {
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+	Task     *TaskDTO     `json:"task,omitempty"`
+	Subtask  *SubtaskDTO  `json:"subtask,omitempty"`
+	Category *CategoryDTO `json:"category,omitempty"`
+	Tag      *TagDTO      `json:"tag,omitempty"`
+	Priority *PriorityDTO `json:"priority,omitempty"`
 }
 
-func sPtr(t *time.Time) *string {
-	if t == nil {
+func (c *ConflictDTO) Error() string {
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+type StatsDTO dto.Stats
+
+type PriorityDTO dto.Priority
+
+type OccurrenceDTO dto.Occurrence
+
+type TaskFilterDTO dto.TaskFilter
+
+type SearchResultDTO dto.SearchResult
+
+type TaskPageDTO dto.TaskPage
+
+type ViewDTO dto.View
+
+type TaskPatchDTO dto.TaskPatch
+
+type TagDTO struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Color   string `json:"color"`
+	Count   int    `json:"count"`
+	Version int64  `json:"version"`
+}
+
+// TrashDTO lists deleted items that can still be restored, most recently
+// deleted first.
+type TrashDTO struct {
+	Tasks      []TaskDTO     `json:"tasks"`
+	Subtasks   []SubtaskDTO  `json:"subtasks"`
+	Categories []CategoryDTO `json:"categories"`
+}
+
+// HistoryDTO names the changes Undo and Redo would revert or repeat next;
+// an empty name means there is nothing to do.
+type HistoryDTO struct {
+	Undo string `json:"undo"`
+	Redo string `json:"redo"`
+}
+
+func toTaskDTO(t *models.Task) TaskDTO {
+	return TaskDTO(dto.FromTask(t))
+}
+
+func toTaskDTOs(ts []models.Task) []TaskDTO {
+	var res []TaskDTO
+	for i := range ts {
+		res = append(res, toTaskDTO(&ts[i]))
+	}
+	return res
+}
+
+func toSubtaskDTO(s *models.Subtask) SubtaskDTO {
+	return SubtaskDTO{
+		ID:        s.ID,
+		TaskID:    s.TaskID,
+		Title:     s.Title,
+		Completed: s.Completed,
+		CreatedAt: s.CreatedAt.UTC().Format(time.RFC3339),
+		Version:   s.Version,
+		DeletedAt: formatTime(s.DeletedAt),
+	}
+}
+
+func toCategoryDTO(c models.Category) CategoryDTO {
+	return CategoryDTO{
+		ID:         c.ID,
+		Name:       c.Name,
+		ParentID:   c.ParentID,
+		Position:   c.Position,
+		Color:      c.Color,
+		Icon:       c.Icon,
+		TaskCount:  c.TaskCount,
+		TotalCount: c.TotalCount,
+		Version:    c.Version,
+		DeletedAt:  formatTime(c.DeletedAt),
+	}
+}
+
+func toTagDTO(t models.Tag) TagDTO {
+	return TagDTO{ID: t.ID, Name: t.Name, Color: t.Color, Count: t.Count, Version: t.Version}
+}
+
+func toTagDTOs(ts []models.Tag) []TagDTO {
+	res := []TagDTO{}
+	for _, t := range ts {
+		res = append(res, toTagDTO(t))
+	}
+	return res
+}
+
+// optionalID maps the 0 that bindings pass for "none" to nil.
+func optionalID(id int64) *int64 {
+	if id == 0 {
 		return nil
 	}
-	v := t.UTC().Format(time.RFC3339)
-	return &v
+	return &id
 }
 
-func parseRFC3339OrNil(s string) (*time.Time, error) {
-	if strings.TrimSpace(s) == "" {
-		return nil, nil
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
 	}
-	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		return &t, nil
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
+
+// bindErr turns a service.ConflictError into a ConflictDTO.
+func bindErr(err error) error {
+	var ce *service.ConflictError
+	if !errors.As(err, &ce) {
+		return err
 	}
-	if t, err := time.Parse("2006-01-02T15:04", s); err == nil {
-		loc := time.Now().Location()
-		u := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
-		return &u, nil
+	res := &ConflictDTO{Code: "conflict", Message: ce.Error()}
+	switch cur := ce.Current.(type) {
+	case *models.Task:
+		t := toTaskDTO(cur)
+		res.Task = &t
+	case *models.Subtask:
+		st := toSubtaskDTO(cur)
+		res.Subtask = &st
+	case models.Category:
+		c := toCategoryDTO(cur)
+		res.Category = &c
+	case models.Tag:
+		t := toTagDTO(cur)
+		res.Tag = &t
+	case models.Priority:
+		p := PriorityDTO(dto.FromPriority(cur))
+		res.Priority = &p
+	}
+	return res
+}
+
+func taskResult(t *models.Task, err error) (TaskDTO, error) {
+	if err != nil {
+		return TaskDTO{}, bindErr(err)
 	}
-	if t, err := time.Parse("2006-01-02", s); err == nil {
-		loc := time.Now().Location()
-		u := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-		return &u, nil
+	return toTaskDTO(t), nil
+}
+
+func (a *App) confirm(title, message string) (bool, error) {
+	sel, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:    "question",
+		Title:   title,
+		Message: message,
+	})
+	if err != nil {
+		return false, err
 	}
-	return nil, errors.New("invalid date format")
+	return strings.ToLower(sel) == "yes" || strings.ToLower(sel) == "ok", nil
 }
 
-const userID int64 = 1
+func (a *App) Register(email, password string) (UserDTO, error) {
+	if _, err := a.auth.Register(email, password); err != nil {
+		return UserDTO{}, err
+	}
+	return a.Login(email, password)
+}
 
-func scanTask(rows *sql.Rows) (TaskDTO, error) {
-	var id int64
-	var title, priority string
-	var completed bool
-	var createdAt time.Time
-	var completedAt sql.NullTime
-	var dueAt sql.NullTime
-	var categoryID sql.NullInt64
-	var tags []sql.NullString
-	err := rows.Scan(&id, &title, &priority, &completed, &createdAt, &completedAt, &dueAt, &categoryID, pq.Array(&tags))
+func (a *App) Login(email, password string) (UserDTO, error) {
+	sess, err := a.auth.Login(email, password)
 	if err != nil {
-		return TaskDTO{}, err
+		return UserDTO{}, err
 	}
-	var t []string
-	for _, v := range tags {
-		if v.Valid {
-			t = append(t, v.String)
-		}
+	a.clearHistory()
+	if old := a.session.clear(); old != "" {
+		_ = a.auth.Logout(old)
 	}
-	var cid *int64
-	if categoryID.Valid {
-		v := categoryID.Int64
-		cid = &v
-	}
-	return TaskDTO{
-		ID:        id,
-		Title:     title,
-		Priority:  priority,
-		Completed: completed,
-		CreatedAt: createdAt.UTC().Format(time.RFC3339),
-		CompletedAt: func() *string {
-			if completedAt.Valid {
-				return sPtr(&completedAt.Time)
-			}
-			return nil
-		}(),
-		DueDate: func() *string {
-			if dueAt.Valid {
-				return sPtr(&dueAt.Time)
-			}
-			return nil
-		}(),
-		CategoryID: cid,
-		Tags:       t,
-	}, nil
+	a.session.set(sess)
+	return UserDTO{ID: sess.User.ID, Email: sess.User.Email}, nil
+}
+
+func (a *App) Logout() error {
+	a.clearHistory()
+	return a.auth.Logout(a.session.clear())
+}
+
+// clearHistory drops the signed-in user's undo history as their session ends.
+func (a *App) clearHistory() {
+	if uid, err := a.session.userID(); err == nil {
+		a.tasks.ClearHistory(uid)
+	}
+}
+
+func (a *App) CurrentUser() *UserDTO {
+	if _, err := a.session.userID(); err != nil {
+		return nil
+	}
+	u := a.session.user()
+	if u == nil {
+		return nil
+	}
+	return &UserDTO{ID: u.ID, Email: u.Email}
 }
 
 func (a *App) GetTasks(filter string) ([]TaskDTO, error) {
-	q := `
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks
-where user_id = $1
-`
-	switch filter {
-	case "active":
-		q += " and completed = false"
-	case "completed":
-		q += " and completed = true"
-	case "overdue":
-		q += " and completed = false and due_at is not null and due_at < now()"
-	case "today":
-		q += " and due_at::date = current_date"
-	case "week":
-		q += " and due_at >= date_trunc('week', now()) and due_at < date_trunc('week', now()) + interval '1 week'"
-	}
-	q += " order by created_at desc, id desc"
-	rows, err := a.db.Query(q, userID)
+	return a.GetSortedTasks(filter, "")
+}
+
+// GetSortedTasks is GetTasks ordered by sort: "created" or "" (newest
+// first), "manual", "due", "priority" or "title".
+func (a *App) GetSortedTasks(filter, sort string) ([]TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var res []TaskDTO
-	for rows.Next() {
-		t, err := scanTask(rows)
-		if err != nil {
-			return nil, err
-		}
-		res = append(res, t)
+	ts, err := a.tasks.GetTasks(uid, filter, sort)
+	if err != nil {
+		return nil, err
 	}
-	return res, nil
+	return toTaskDTOs(ts), nil
+}
+
+// GetTasksPage is GetTasks one page at a time. Pass "" as cursor for the
+// first page and a returned Next or Prev to move; limit 0 means the default.
+func (a *App) GetTasksPage(filter, cursor string, limit int) (TaskPageDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskPageDTO{}, err
+	}
+	p, err := a.tasks.ListTasks(uid, filter, cursor, limit)
+	if err != nil {
+		return TaskPageDTO{}, err
+	}
+	return TaskPageDTO(dto.FromTaskPage(p)), nil
 }
 
 func (a *App) SearchTasks(query string) ([]TaskDTO, error) {
-	q := `
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks
-where user_id=$1 and (title ilike $2 or coalesce(description,'') ilike $2)
-order by created_at desc, id desc
-`
-	rows, err := a.db.Query(q, userID, "%"+strings.TrimSpace(query)+"%")
+	uid, err := a.session.userID()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var res []TaskDTO
-	for rows.Next() {
-		t, err := scanTask(rows)
-		if err != nil {
-			return nil, err
-		}
-		res = append(res, t)
+	ts, err := a.tasks.SearchTasks(uid, query)
+	if err != nil {
+		return nil, err
+	}
+	return toTaskDTOs(ts), nil
+}
+
+// SearchTasksRanked is SearchTasks with relevance ranks and highlighted
+// titles and description snippets for the results list.
+func (a *App) SearchTasksRanked(query string) ([]SearchResultDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	rs, err := a.tasks.Search(uid, query)
+	if err != nil {
+		return nil, err
+	}
+	var res []SearchResultDTO
+	for i := range rs {
+		res = append(res, SearchResultDTO(dto.FromSearchResult(&rs[i])))
 	}
 	return res, nil
 }
 
-func (a *App) AddTask(title, priority, dueISO string) (TaskDTO, error) {
-	if strings.TrimSpace(title) == "" {
-		return TaskDTO{}, errors.New("title is required")
+// QueryTasks returns the tasks matching every field set in filter. The same
+// filters are available as text through SearchTasks, e.g.
+// `tag:work priority:high due<2026-11-01 -is:done "exact phrase"`.
+func (a *App) QueryTasks(filter TaskFilterDTO) ([]TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
 	}
-	switch priority {
-	case "high", "medium", "low":
-	default:
-		priority = "medium"
+	f, err := dto.TaskFilter(filter).ToModel()
+	if err != nil {
+		return nil, err
 	}
-	due, err := parseRFC3339OrNil(dueISO)
+	ts, err := a.tasks.QueryTasks(uid, f)
 	if err != nil {
-		return TaskDTO{}, err
+		return nil, err
 	}
-	var id int64
-	var retTitle, retPriority string
-	var completed bool
-	var createdAt time.Time
-	var completedAt sql.NullTime
-	var dueAt sql.NullTime
-	var categoryID sql.NullInt64
-	var tags []sql.NullString
-	err = a.db.QueryRow(`
-insert into tasks (user_id, title, priority, completed, created_at, due_at, tags)
-values ($1,$2,$3,false,now(),$4,$5)
-returning id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-`, userID, strings.TrimSpace(title), priority, due, pq.Array([]string{})).Scan(&id, &retTitle, &retPriority, &completed, &createdAt, &completedAt, &dueAt, &categoryID, pq.Array(&tags))
+	return toTaskDTOs(ts), nil
+}
+
+func (a *App) AddTask(title, priority, dueISO string) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	var t []string
-	for _, v := range tags {
-		if v.Valid {
-			t = append(t, v.String)
-		}
-	}
-	var cid *int64
-	if categoryID.Valid {
-		v := categoryID.Int64
-		cid = &v
-	}
-	return TaskDTO{
-		ID:        id,
-		Title:     retTitle,
-		Priority:  retPriority,
-		Completed: completed,
-		CreatedAt: createdAt.UTC().Format(time.RFC3339),
-		CompletedAt: func() *string {
-			if completedAt.Valid {
-				return sPtr(&completedAt.Time)
-			}
-			return nil
-		}(),
-		DueDate: func() *string {
-			if dueAt.Valid {
-				return sPtr(&dueAt.Time)
-			}
-			return nil
-		}(),
-		CategoryID: cid,
-		Tags:       t,
-	}, nil
-}
-
-func (a *App) ToggleTask(id int64) (TaskDTO, error) {
-	tx, err := a.db.Begin()
+	due, err := dto.ParseDate(dueISO)
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	defer tx.Rollback()
-	var completed bool
-	if err := tx.QueryRow(`select completed from tasks where id=$1 and user_id=$2`, id, userID).Scan(&completed); err != nil {
+	return taskResult(a.tasks.AddTask(uid, models.CreateTaskInput{
+		Title:    title,
+		Priority: priority,
+		DueDate:  due,
+	}))
+}
+
+// AddTaskWithDescription is AddTask with a Markdown description.
+func (a *App) AddTaskWithDescription(title, description, priority, dueISO string) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
 		return TaskDTO{}, err
 	}
-	if completed {
-		if _, err := tx.Exec(`update tasks set completed=false, completed_at=null where id=$1 and user_id=$2`, id, userID); err != nil {
-			return TaskDTO{}, err
-		}
-	} else {
-		if _, err := tx.Exec(`update tasks set completed=true, completed_at=now() where id=$1 and user_id=$2`, id, userID); err != nil {
-			return TaskDTO{}, err
-		}
-		var rr sql.NullString
-		var due sql.NullTime
-		var ttl, prio string
-		if err := tx.QueryRow(`select title, priority, repeat_rule, due_at from tasks where id=$1 and user_id=$2`, id, userID).Scan(&ttl, &prio, &rr, &due); err == nil {
-			if rr.Valid && due.Valid {
-				next := due.Time
-				switch strings.ToLower(rr.String) {
-				case "daily":
-					next = next.Add(24 * time.Hour)
-				case "weekly":
-					next = next.AddDate(0, 0, 7)
-				case "monthly":
-					next = next.AddDate(0, 1, 0)
-				default:
-				}
-				if _, err := tx.Exec(`insert into tasks (user_id, title, priority, completed, created_at, due_at, repeat_rule, tags, category_id) select user_id, $1, $2, false, now(), $3, repeat_rule, tags, category_id from tasks where id=$4`, ttl, prio, next, id); err != nil {
-					return TaskDTO{}, err
-				}
-			}
-		}
+	due, err := dto.ParseDate(dueISO)
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	row := tx.QueryRow(`
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks where id=$1 and user_id=$2
-`, id, userID)
-	var r TaskDTO
-	{
-		var rid int64
-		var title, priority string
-		var rcompleted bool
-		var createdAt time.Time
-		var completedAt sql.NullTime
-		var dueAt sql.NullTime
-		var categoryID sql.NullInt64
-		var tags []sql.NullString
-		if err := row.Scan(&rid, &title, &priority, &rcompleted, &createdAt, &completedAt, &dueAt, &categoryID, pq.Array(&tags)); err != nil {
-			return TaskDTO{}, err
-		}
-		var t []string
-		for _, v := range tags {
-			if v.Valid {
-				t = append(t, v.String)
-			}
-		}
-		var cid *int64
-		if categoryID.Valid {
-			v := categoryID.Int64
-			cid = &v
-		}
-		r = TaskDTO{
-			ID:        rid,
-			Title:     title,
-			Priority:  priority,
-			Completed: rcompleted,
-			CreatedAt: createdAt.UTC().Format(time.RFC3339),
-			CompletedAt: func() *string {
-				if completedAt.Valid {
-					return sPtr(&completedAt.Time)
-				}
-				return nil
-			}(),
-			DueDate: func() *string {
-				if dueAt.Valid {
-					return sPtr(&dueAt.Time)
-				}
-				return nil
-			}(),
-			CategoryID: cid,
-			Tags:       t,
-		}
+	return taskResult(a.tasks.AddTask(uid, models.CreateTaskInput{
+		Title:       title,
+		Description: description,
+		Priority:    priority,
+		DueDate:     due,
+	}))
+}
+
+// ToggleTask and the other task edits below that take a version fail with
+// a ConflictDTO unless version is 0 or the task's current version.
+func (a *App) ToggleTask(id, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	if err := tx.Commit(); err != nil {
+	return taskResult(a.tasks.ToggleTask(uid, id, version))
+}
+
+// MoveTask drops a task between beforeID, the task shown right above it in
+// the manual order, and afterID, the one right below; 0 at either end.
+func (a *App) MoveTask(id, beforeID, afterID int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
 		return TaskDTO{}, err
 	}
-	return r, nil
+	return taskResult(a.tasks.MoveTask(uid, id, beforeID, afterID))
 }
 
-func (a *App) DeleteTask(id int64) error {
-	sel, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    "question",
-		Title:   "Delete task",
-		Message: "Delete this task permanently?",
-	})
+func (a *App) DeleteTask(id, version int64) error {
+	uid, err := a.session.userID()
 	if err != nil {
 		return err
 	}
-	if strings.ToLower(sel) != "yes" && strings.ToLower(sel) != "ok" {
-		return nil
+	ok, err := a.confirm("Delete task", "Move this task to the trash?")
+	if err != nil || !ok {
+		return err
 	}
-	_, err = a.db.Exec(`delete from tasks where id=$1 and user_id=$2`, id, userID)
-	return err
+	return bindErr(a.tasks.DeleteTask(uid, id, version))
 }
 
 func (a *App) ClearCompleted() (int64, error) {
-	sel, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    "question",
-		Title:   "Clear completed",
-		Message: "Delete all completed tasks?",
-	})
+	uid, err := a.session.userID()
 	if err != nil {
 		return 0, err
 	}
-	if strings.ToLower(sel) != "yes" && strings.ToLower(sel) != "ok" {
-		return 0, nil
+	ok, err := a.confirm("Clear completed", "Archive all completed tasks?")
+	if err != nil || !ok {
+		return 0, err
 	}
-	res, err := a.db.Exec(`delete from tasks where user_id=$1 and completed=true`, userID)
+	return a.tasks.ClearCompleted(uid)
+}
+
+// UnarchiveTask brings an archived task back into the task lists; GetTasks
+// lists archived tasks under the "archived" filter.
+func (a *App) UnarchiveTask(id, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
-		return 0, err
+		return TaskDTO{}, err
 	}
-	n, _ := res.RowsAffected()
-	return n, nil
+	return taskResult(a.tasks.Unarchive(uid, id, version))
 }
 
-func (a *App) UpdateTask(id int64, title, priority, dueISO string) (TaskDTO, error) {
-	if strings.TrimSpace(title) == "" {
-		return TaskDTO{}, errors.New("title is required")
+// Undo reverts the last task change made in this session and returns its
+// name.
+func (a *App) Undo() (string, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return "", err
 	}
-	switch priority {
-	case "high", "medium", "low":
-	default:
-		priority = "medium"
+	return a.tasks.Undo(uid)
+}
+
+func (a *App) Redo() (string, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return "", err
+	}
+	return a.tasks.Redo(uid)
+}
+
+func (a *App) GetHistory() (HistoryDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return HistoryDTO{}, err
 	}
-	due, err := parseRFC3339OrNil(dueISO)
+	undo, redo := a.tasks.HistoryLabels(uid)
+	return HistoryDTO{Undo: undo, Redo: redo}, nil
+}
+
+func (a *App) UpdateTask(id int64, title, priority, dueISO string, version int64) (TaskDTO, error) {
+	return a.UpdateOccurrence(id, title, priority, dueISO, service.ScopeThis, version)
+}
+
+// PatchTask changes only the fields set in patch; see TaskPatchDTO for how
+// to clear a field. Invalid fields are reported together in the error.
+func (a *App) PatchTask(id int64, patch TaskPatchDTO) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	_, err = a.db.Exec(`update tasks set title=$1, priority=$2, due_at=$3 where id=$4 and user_id=$5`, strings.TrimSpace(title), priority, due, id, userID)
+	in, err := dto.TaskPatch(patch).ToModel(id)
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	rows, err := a.db.Query(`
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks where id=$1 and user_id=$2
-`, id, userID)
+	return taskResult(a.tasks.PatchTask(uid, in))
+}
+
+// UpdateOccurrence edits an occurrence of a repeating task. scope "this"
+// changes only that occurrence; "future" also applies the edit to every
+// occurrence after it.
+func (a *App) UpdateOccurrence(id int64, title, priority, dueISO, scope string, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	defer rows.Close()
-	if rows.Next() {
-		return scanTask(rows)
+	due, err := dto.ParseDate(dueISO)
+	if err != nil {
+		return TaskDTO{}, err
+	}
+	t, err := a.tasks.GetTask(uid, id)
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	return TaskDTO{}, errors.New("not found")
+	t.Title = title
+	t.Priority = priority
+	t.DueDate = due
+	t.Version = version
+	return taskResult(a.tasks.UpdateOccurrence(uid, *t, scope))
 }
 
 func (a *App) GetStats() (StatsDTO, error) {
-	var s StatsDTO
-	if err := a.db.QueryRow(`select count(*) from tasks where user_id=$1`, userID).Scan(&s.Total); err != nil {
-		return s, err
+	uid, err := a.session.userID()
+	if err != nil {
+		return StatsDTO{}, err
 	}
-	if err := a.db.QueryRow(`select count(*) from tasks where user_id=$1 and completed=false`, userID).Scan(&s.Active); err != nil {
-		return s, err
+	s, err := a.stats.Snapshot(uid)
+	if err != nil {
+		return StatsDTO{}, err
 	}
-	if err := a.db.QueryRow(`select count(*) from tasks where user_id=$1 and completed=true`, userID).Scan(&s.Completed); err != nil {
-		return s, err
+	return StatsDTO(dto.FromStats(s)), nil
+}
+
+func (a *App) SetTaskTags(id int64, tags []string, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	if err := a.db.QueryRow(`select count(*) from tasks where user_id=$1 and completed=false and due_at is not null and due_at < now()`, userID).Scan(&s.Overdue); err != nil {
-		return s, err
+	return taskResult(a.tasks.SetTags(uid, id, tags, version))
+}
+
+func (a *App) SetTaskDescription(id int64, description string, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskDTO{}, err
+	}
+	return taskResult(a.tasks.SetDescription(uid, id, description, version))
+}
+
+// ConvertChecklist moves the "- [ ]" items of a task's description into its
+// subtasks.
+func (a *App) ConvertChecklist(id, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	return s, nil
+	return taskResult(a.tasks.ConvertChecklist(uid, id, version))
 }
 
-func (a *App) SetTaskTags(id int64, tags []string) (TaskDTO, error) {
-	_, err := a.db.Exec(`update tasks set tags=$1 where id=$2 and user_id=$3`, pq.Array(tags), id, userID)
+// PreviewDescription renders Markdown the way descriptionHtml is rendered,
+// for a live preview while editing.
+func (a *App) PreviewDescription(src string) string {
+	return markdown.Render(src)
+}
+
+// SetRepeatRule makes a task repeat. rule is an iCalendar RRULE
+// ("FREQ=WEEKLY;BYDAY=MO,TH") or a phrase such as "every weekday" or
+// "last Friday of the month"; the task must have a due date.
+func (a *App) SetRepeatRule(id int64, rule string, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	rows, err := a.db.Query(`
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks where id=$1 and user_id=$2
-`, id, userID)
+	return taskResult(a.tasks.SetRepeatRule(uid, id, rule, version))
+}
+
+func (a *App) ClearRepeatRule(id, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	defer rows.Close()
-	if rows.Next() {
-		return scanTask(rows)
+	return taskResult(a.tasks.ClearRepeatRule(uid, id, version))
+}
+
+// SkipOccurrence drops an open occurrence of a repeating task and returns the
+// one that replaces it, or nil when the series has no more.
+func (a *App) SkipOccurrence(id, version int64) (*TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	next, err := a.tasks.SkipOccurrence(uid, id, version)
+	if err != nil || next == nil {
+		return nil, bindErr(err)
 	}
-	return TaskDTO{}, errors.New("not found")
+	res := toTaskDTO(next)
+	return &res, nil
+}
+
+func (a *App) GetSeriesHistory(seriesID int64) ([]OccurrenceDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	history, err := a.tasks.GetSeriesHistory(uid, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	var res []OccurrenceDTO
+	for i := range history {
+		res = append(res, OccurrenceDTO(dto.FromOccurrence(&history[i])))
+	}
+	return res, nil
 }
 
+// GetCategories lists the category tree depth first: each category is
+// followed by its subcategories.
 func (a *App) GetCategories() ([]CategoryDTO, error) {
-	rows, err := a.db.Query(`select id, name from categories where user_id=$1 order by name`, userID)
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	cs, err := a.categories.List(uid)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 	var res []CategoryDTO
-	for rows.Next() {
-		var c CategoryDTO
-		if err := rows.Scan(&c.ID, &c.Name); err != nil {
-			return nil, err
-		}
-		res = append(res, c)
+	for _, c := range cs {
+		res = append(res, toCategoryDTO(c))
 	}
 	return res, nil
 }
 
 func (a *App) AddCategory(name string) (CategoryDTO, error) {
-	if strings.TrimSpace(name) == "" {
-		return CategoryDTO{}, errors.New("name is required")
+	uid, err := a.session.userID()
+	if err != nil {
+		return CategoryDTO{}, err
 	}
-	var id int64
-	if err := a.db.QueryRow(`insert into categories (user_id, name, created_at) values ($1,$2,now()) returning id`, userID, strings.TrimSpace(name)).Scan(&id); err != nil {
+	c, err := a.categories.Create(uid, models.Category{Name: name})
+	if err != nil {
 		return CategoryDTO{}, err
 	}
-	return CategoryDTO{ID: id, Name: strings.TrimSpace(name)}, nil
+	return toCategoryDTO(c), nil
 }
 
-func (a *App) DeleteCategory(id int64) error {
-	_, err := a.db.Exec(`delete from categories where id=$1 and user_id=$2`, id, userID)
-	return err
+// CreateCategory adds a category as the last subcategory of parentID, or at
+// the top level when parentID is 0. color is empty or "#rrggbb".
+func (a *App) CreateCategory(parentID int64, name, color, icon string) (CategoryDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return CategoryDTO{}, err
+	}
+	c, err := a.categories.Create(uid, models.Category{Name: name, ParentID: optionalID(parentID), Color: color, Icon: icon})
+	if err != nil {
+		return CategoryDTO{}, err
+	}
+	return toCategoryDTO(c), nil
 }
 
-func (a *App) AssignCategory(taskID, categoryID int64) (TaskDTO, error) {
-	_, err := a.db.Exec(`update tasks set category_id=$1 where id=$2 and user_id=$3`, categoryID, taskID, userID)
+// UpdateCategory renames and restyles a category; it fails with a
+// ConflictDTO unless version is 0 or the category's current version.
+func (a *App) UpdateCategory(id int64, name, color, icon string, version int64) (CategoryDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
-		return TaskDTO{}, err
+		return CategoryDTO{}, err
 	}
-	rows, err := a.db.Query(`
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks where id=$1 and user_id=$2
-`, taskID, userID)
+	c, err := a.categories.Update(uid, models.Category{ID: id, Name: name, Color: color, Icon: icon, Version: version})
 	if err != nil {
-		return TaskDTO{}, err
+		return CategoryDTO{}, bindErr(err)
 	}
-	defer rows.Close()
-	if rows.Next() {
-		return scanTask(rows)
+	return toCategoryDTO(c), nil
+}
+
+// MoveCategory makes a category the subcategory of parentID, or top-level
+// when parentID is 0, at position among its new siblings. It has the same
+// version check as UpdateCategory.
+func (a *App) MoveCategory(id, parentID int64, position int, version int64) (CategoryDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return CategoryDTO{}, err
+	}
+	c, err := a.categories.Move(uid, id, optionalID(parentID), position, version)
+	if err != nil {
+		return CategoryDTO{}, bindErr(err)
 	}
-	return TaskDTO{}, errors.New("not found")
+	return toCategoryDTO(c), nil
 }
 
-func (a *App) ClearCategory(taskID int64) (TaskDTO, error) {
-	_, err := a.db.Exec(`update tasks set category_id=null where id=$1 and user_id=$2`, taskID, userID)
+// RenameCategory fails with a ConflictDTO unless version is 0 or the
+// category's current version.
+func (a *App) RenameCategory(id int64, name string, version int64) (CategoryDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
-		return TaskDTO{}, err
+		return CategoryDTO{}, err
+	}
+	c, err := a.categories.Rename(uid, id, name, version)
+	if err != nil {
+		return CategoryDTO{}, bindErr(err)
+	}
+	return toCategoryDTO(c), nil
+}
+
+// DeleteCategory moves a category to the trash. tasks is "move", "clear" or
+// "delete": its tasks move to the category moveTo, lose their category, or
+// go to the trash too.
+func (a *App) DeleteCategory(id int64, tasks string, moveTo, version int64) error {
+	uid, err := a.session.userID()
+	if err != nil {
+		return err
+	}
+	if tasks == models.CategoryDeleteTasks {
+		ok, err := a.confirm("Delete category", "Move this category and its tasks to the trash?")
+		if err != nil || !ok {
+			return err
+		}
+	}
+	return bindErr(a.categories.Delete(uid, id, tasks, optionalID(moveTo), version))
+}
+
+// GetPriorities lists the levels of the priority scale, highest weight
+// first, with the number of tasks at each.
+func (a *App) GetPriorities() ([]PriorityDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
 	}
-	rows, err := a.db.Query(`
-select id, title, priority, completed, created_at, completed_at, due_at, category_id, tags
-from tasks where id=$1 and user_id=$2
-`, taskID, userID)
+	ps, err := a.priorities.List(uid)
+	if err != nil {
+		return nil, err
+	}
+	res := []PriorityDTO{}
+	for _, p := range ps {
+		res = append(res, PriorityDTO(dto.FromPriority(p)))
+	}
+	return res, nil
+}
+
+// CreatePriority adds a level to the priority scale. name is what tasks and
+// queries use; a higher weight sorts first. isDefault makes it the level of
+// tasks created without a priority.
+func (a *App) CreatePriority(name, label, color string, weight int, isDefault bool) (PriorityDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return PriorityDTO{}, err
+	}
+	p, err := a.priorities.Create(uid, models.Priority{Name: name, Label: label, Color: color, Weight: weight, Default: isDefault})
+	if err != nil {
+		return PriorityDTO{}, err
+	}
+	return PriorityDTO(dto.FromPriority(p)), nil
+}
+
+// UpdatePriority changes a level; a new name is applied to every task. It
+// fails with a ConflictDTO unless version is 0 or the stored one.
+func (a *App) UpdatePriority(id int64, name, label, color string, weight int, isDefault bool, version int64) (PriorityDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return PriorityDTO{}, err
+	}
+	p, err := a.priorities.Update(uid, models.Priority{ID: id, Name: name, Label: label, Color: color, Weight: weight, Default: isDefault, Version: version})
+	if err != nil {
+		return PriorityDTO{}, bindErr(err)
+	}
+	return PriorityDTO(dto.FromPriority(p)), nil
+}
+
+// DeletePriority removes a level; its tasks move to the level moveTo.
+func (a *App) DeletePriority(id, moveTo, version int64) error {
+	uid, err := a.session.userID()
+	if err != nil {
+		return err
+	}
+	return bindErr(a.priorities.Delete(uid, id, moveTo, version))
+}
+
+// GetTags lists every tag by name, with the number of tasks using it.
+func (a *App) GetTags() ([]TagDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := a.tags.Tags(uid)
+	if err != nil {
+		return nil, err
+	}
+	return toTagDTOs(ts), nil
+}
+
+// SuggestTags autocompletes a tag name: up to limit tags starting with
+// prefix, most used first. limit 0 means service.DefaultSuggestLimit.
+func (a *App) SuggestTags(prefix string, limit int) ([]TagDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := a.tags.Suggest(uid, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toTagDTOs(ts), nil
+}
+
+// CreateTag adds a tag; color is empty or "#rrggbb".
+func (a *App) CreateTag(name, color string) (TagDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TagDTO{}, err
+	}
+	t, err := a.tags.Create(uid, name, color)
+	if err != nil {
+		return TagDTO{}, err
+	}
+	return toTagDTO(t), nil
+}
+
+// UpdateTag renames and recolors a tag; a new name is applied to every task.
+func (a *App) UpdateTag(id int64, name, color string, version int64) (TagDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TagDTO{}, err
+	}
+	t, err := a.tags.Update(uid, id, name, color, version)
+	if err != nil {
+		return TagDTO{}, bindErr(err)
+	}
+	return toTagDTO(t), nil
+}
+
+// MergeTags retags every task tagged from with into and deletes from;
+// version is from's.
+func (a *App) MergeTags(from, into, version int64) (TagDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TagDTO{}, err
+	}
+	t, err := a.tags.Merge(uid, from, into, version)
+	if err != nil {
+		return TagDTO{}, bindErr(err)
+	}
+	return toTagDTO(t), nil
+}
+
+// DeleteTag removes a tag from every task.
+func (a *App) DeleteTag(id, version int64) error {
+	uid, err := a.session.userID()
+	if err != nil {
+		return err
+	}
+	ok, err := a.confirm("Delete tag", "Remove this tag from all tasks?")
+	if err != nil || !ok {
+		return err
+	}
+	return bindErr(a.tags.Delete(uid, id, version))
+}
+
+// GetViews lists the built-in views (Inbox, Today, Upcoming, Someday) and
+// then the user's saved views in their order.
+func (a *App) GetViews() ([]ViewDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	vs, err := a.views.List(uid)
+	if err != nil {
+		return nil, err
+	}
+	var res []ViewDTO
+	for i := range vs {
+		res = append(res, ViewDTO(dto.FromView(&vs[i])))
+	}
+	return res, nil
+}
+
+func viewResult(v *models.SavedView, err error) (ViewDTO, error) {
+	if err != nil {
+		return ViewDTO{}, err
+	}
+	return ViewDTO(dto.FromView(v)), nil
+}
+
+func (a *App) CreateView(name string, filter TaskFilterDTO) (ViewDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return ViewDTO{}, err
+	}
+	f, err := dto.TaskFilter(filter).ToModel()
+	if err != nil {
+		return ViewDTO{}, err
+	}
+	return viewResult(a.views.Create(uid, name, f))
+}
+
+func (a *App) UpdateView(id int64, name string, filter TaskFilterDTO) (ViewDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return ViewDTO{}, err
+	}
+	f, err := dto.TaskFilter(filter).ToModel()
+	if err != nil {
+		return ViewDTO{}, err
+	}
+	return viewResult(a.views.Update(uid, id, name, f))
+}
+
+func (a *App) RenameView(id int64, name string) (ViewDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return ViewDTO{}, err
+	}
+	return viewResult(a.views.Rename(uid, id, name))
+}
+
+// ReorderViews takes the ids of all saved views in their new order.
+func (a *App) ReorderViews(ids []int64) error {
+	uid, err := a.session.userID()
+	if err != nil {
+		return err
+	}
+	return a.views.Reorder(uid, ids)
+}
+
+func (a *App) DeleteView(id int64) error {
+	uid, err := a.session.userID()
+	if err != nil {
+		return err
+	}
+	return a.views.Delete(uid, id)
+}
+
+// GetViewTasks evaluates a view against the current tasks.
+func (a *App) GetViewTasks(id int64) ([]TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := a.views.Tasks(uid, id)
+	if err != nil {
+		return nil, err
+	}
+	return toTaskDTOs(ts), nil
+}
+
+func (a *App) AssignCategory(taskID, categoryID, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return TaskDTO{}, err
 	}
-	defer rows.Close()
-	if rows.Next() {
-		return scanTask(rows)
+	return taskResult(a.tasks.AssignCategory(uid, taskID, &categoryID, version))
+}
+
+func (a *App) ClearCategory(taskID, version int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	return TaskDTO{}, errors.New("not found")
+	return taskResult(a.tasks.AssignCategory(uid, taskID, nil, version))
 }
 
 func (a *App) GetSubtasks(taskID int64) ([]SubtaskDTO, error) {
-	rows, err := a.db.Query(`select id, task_id, title, completed, created_at from subtasks where user_id=$1 and task_id=$2 order by id`, userID, taskID)
+	uid, err := a.session.userID()
+	if err != nil {
+		return nil, err
+	}
+	ss, err := a.tasks.GetSubtasks(uid, taskID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 	var res []SubtaskDTO
-	for rows.Next() {
-		var s SubtaskDTO
-		var created time.Time
-		if err := rows.Scan(&s.ID, &s.TaskID, &s.Title, &s.Completed, &created); err != nil {
-			return nil, err
-		}
-		s.CreatedAt = created.UTC().Format(time.RFC3339)
-		res = append(res, s)
+	for i := range ss {
+		res = append(res, toSubtaskDTO(&ss[i]))
 	}
 	return res, nil
 }
 
 func (a *App) AddSubtask(taskID int64, title string) (SubtaskDTO, error) {
-	if strings.TrimSpace(title) == "" {
-		return SubtaskDTO{}, errors.New("title is required")
+	uid, err := a.session.userID()
+	if err != nil {
+		return SubtaskDTO{}, err
 	}
-	var id int64
-	var created time.Time
-	if err := a.db.QueryRow(`insert into subtasks (user_id, task_id, title, completed, created_at) values ($1,$2,$3,false,now()) returning id, created_at`, userID, taskID, strings.TrimSpace(title)).Scan(&id, &created); err != nil {
+	s, err := a.tasks.AddSubtask(uid, taskID, title)
+	if err != nil {
 		return SubtaskDTO{}, err
 	}
-	return SubtaskDTO{ID: id, TaskID: taskID, Title: strings.TrimSpace(title), Completed: false, CreatedAt: created.UTC().Format(time.RFC3339)}, nil
+	return toSubtaskDTO(s), nil
 }
 
-func (a *App) ToggleSubtask(id int64) (SubtaskDTO, error) {
-	tx, err := a.db.Begin()
+// UpdateSubtask fails with a ConflictDTO unless version is 0 or the
+// subtask's current version.
+func (a *App) UpdateSubtask(id int64, title string, completed bool, version int64) (SubtaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
 		return SubtaskDTO{}, err
 	}
-	defer tx.Rollback()
-	var completed bool
-	var taskID int64
-	if err := tx.QueryRow(`select completed, task_id from subtasks where id=$1 and user_id=$2`, id, userID).Scan(&completed, &taskID); err != nil {
-		return SubtaskDTO{}, err
-	}
-	if _, err := tx.Exec(`update subtasks set completed=$1 where id=$2 and user_id=$3`, !completed, id, userID); err != nil {
-		return SubtaskDTO{}, err
+	s, err := a.tasks.UpdateSubtask(uid, models.Subtask{ID: id, Title: title, Completed: completed, Version: version})
+	if err != nil {
+		return SubtaskDTO{}, bindErr(err)
 	}
-	var s SubtaskDTO
-	var created time.Time
-	if err := tx.QueryRow(`select id, task_id, title, completed, created_at from subtasks where id=$1`, id).Scan(&s.ID, &s.TaskID, &s.Title, &s.Completed, &created); err != nil {
+	return toSubtaskDTO(s), nil
+}
+
+// ToggleSubtask has the same version check as UpdateSubtask.
+func (a *App) ToggleSubtask(id, version int64) (SubtaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
 		return SubtaskDTO{}, err
 	}
-	s.CreatedAt = created.UTC().Format(time.RFC3339)
-	if err := tx.Commit(); err != nil {
-		return SubtaskDTO{}, err
+	s, err := a.tasks.ToggleSubtask(uid, id, version)
+	if err != nil {
+		return SubtaskDTO{}, bindErr(err)
 	}
-	return s, nil
+	return toSubtaskDTO(s), nil
 }
 
-func (a *App) DeleteSubtask(id int64) error {
-	_, err := a.db.Exec(`delete from subtasks where id=$1 and user_id=$2`, id, userID)
-	return err
+func (a *App) DeleteSubtask(id, version int64) error {
+	uid, err := a.session.userID()
+	if err != nil {
+		return err
+	}
+	return bindErr(a.tasks.DeleteSubtask(uid, id, version))
 }
 
 func (a *App) BulkComplete(ids []int64) (int64, error) {
-	res, err := a.db.Exec(`update tasks set completed=true, completed_at=now() where user_id=$1 and completed=false and id = any($2)`, userID, pq.Array(ids))
+	uid, err := a.session.userID()
 	if err != nil {
 		return 0, err
 	}
-	n, _ := res.RowsAffected()
-	return n, nil
+	return a.tasks.BulkComplete(uid, ids)
 }
 
 func (a *App) BulkDelete(ids []int64) (int64, error) {
-	sel, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
-		Type:    "question",
-		Title:   "Delete selected",
-		Message: "Delete selected tasks?",
-	})
+	uid, err := a.session.userID()
 	if err != nil {
 		return 0, err
 	}
-	if strings.ToLower(sel) != "yes" && strings.ToLower(sel) != "ok" {
-		return 0, nil
+	ok, err := a.confirm("Delete selected", "Move selected tasks to the trash?")
+	if err != nil || !ok {
+		return 0, err
+	}
+	return a.tasks.BulkDelete(uid, ids)
+}
+
+func (a *App) GetTrash() (TrashDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TrashDTO{}, err
+	}
+	tr, err := a.trash.List(uid)
+	if err != nil {
+		return TrashDTO{}, err
+	}
+	res := TrashDTO{Tasks: toTaskDTOs(tr.Tasks), Subtasks: []SubtaskDTO{}, Categories: []CategoryDTO{}}
+	if res.Tasks == nil {
+		res.Tasks = []TaskDTO{}
+	}
+	for i := range tr.Subtasks {
+		res.Subtasks = append(res.Subtasks, toSubtaskDTO(&tr.Subtasks[i]))
+	}
+	for _, c := range tr.Categories {
+		res.Categories = append(res.Categories, toCategoryDTO(c))
+	}
+	return res, nil
+}
+
+// RestoreTask takes a task out of the trash, together with its subtasks.
+func (a *App) RestoreTask(id int64) (TaskDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return TaskDTO{}, err
 	}
-	res, err := a.db.Exec(`delete from tasks where user_id=$1 and id = any($2)`, userID, pq.Array(ids))
+	return taskResult(a.trash.RestoreTask(uid, id))
+}
+
+func (a *App) RestoreSubtask(id int64) (SubtaskDTO, error) {
+	uid, err := a.session.userID()
 	if err != nil {
+		return SubtaskDTO{}, err
+	}
+	st, err := a.trash.RestoreSubtask(uid, id)
+	if err != nil {
+		return SubtaskDTO{}, err
+	}
+	return toSubtaskDTO(st), nil
+}
+
+func (a *App) RestoreCategory(id int64) (CategoryDTO, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return CategoryDTO{}, err
+	}
+	c, err := a.trash.RestoreCategory(uid, id)
+	if err != nil {
+		return CategoryDTO{}, err
+	}
+	return toCategoryDTO(c), nil
+}
+
+// PurgeTrash permanently deletes everything in the trash.
+func (a *App) PurgeTrash() (int64, error) {
+	uid, err := a.session.userID()
+	if err != nil {
+		return 0, err
+	}
+	ok, err := a.confirm("Empty trash", "Permanently delete everything in the trash?")
+	if err != nil || !ok {
 		return 0, err
 	}
-	n, _ := res.RowsAffected()
-	return n, nil
+	return a.trash.Purge(uid)
 }
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"todo-app/backend/internal/bootstrap"
+	"todo-app/backend/internal/config"
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+func openTestDB(t *testing.T) (*sql.DB, config.Config) {
+	t.Helper()
+	cfg := config.Config{
+		DBConn:     "sqlite:" + filepath.Join(t.TempDir(), "todo.db"),
+		JWTSecret:  "test",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	}
+	db, err := bootstrap.OpenDB(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, cfg
+}
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	db, cfg := openTestDB(t)
+	return newAppOn(t, db, cfg)
+}
+
+func newAppOn(t *testing.T, db *sql.DB, cfg config.Config) *App {
+	t.Helper()
+	uc := bootstrap.NewUsecases(db, cfg)
+	a := NewApp(uc.Auth, uc.Tasks, uc.Categories, uc.Priorities, uc.Tags, uc.Stats, uc.Views, uc.Trash)
+	if _, err := a.Register("a@example.com", "password1"); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func wantConflict(t *testing.T, what string, err error) *ConflictDTO {
+	t.Helper()
+	var c *ConflictDTO
+	if !errors.As(err, &c) {
+		t.Fatalf("%s: got %v, want a ConflictDTO", what, err)
+	}
+	return c
+}
+
+// TestRepeatRuleNotRecorded checks that repeat rule changes, which also
+// change the series, stay out of the undo history.
+func TestRepeatRuleNotRecorded(t *testing.T) {
+	a := newTestApp(t)
+	task, err := a.AddTask("standup", "medium", "2025-01-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err = a.SetRepeatRule(task.ID, "daily", task.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ClearRepeatRule(task.ID, task.Version); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := a.GetHistory(); err != nil || h.Undo != "Add task" {
+		t.Fatalf("GetHistory = %+v, %v; want the add as the last step", h, err)
+	}
+}
+
+// TestStaleBindingEdits has two writers load the same task, subtask and
+// category version; the second write of each must be refused.
+func TestStaleBindingEdits(t *testing.T) {
+	a := newTestApp(t)
+	task, err := a.AddTask("report", "medium", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := task.Version
+
+	if _, err := a.SetTaskTags(task.ID, []string{"work"}, v); err != nil {
+		t.Fatal(err)
+	}
+	c := wantConflict(t, "stale description", func() error { _, err := a.SetTaskDescription(task.ID, "notes", v); return err }())
+	if c.Task == nil || len(c.Task.Tags) != 1 || c.Task.Description != "" {
+		t.Fatalf("conflict carries %+v, want the tagged task", c.Task)
+	}
+	_, err = a.UpdateTask(task.ID, "renamed", "high", "", v)
+	wantConflict(t, "stale update", err)
+	_, err = a.ToggleTask(task.ID, v)
+	wantConflict(t, "stale toggle", err)
+	_, err = a.ClearCategory(task.ID, v)
+	wantConflict(t, "stale category", err)
+	_, err = a.SetRepeatRule(task.ID, "daily", v)
+	wantConflict(t, "stale repeat rule", err)
+	_, err = a.ConvertChecklist(task.ID, v)
+	wantConflict(t, "stale checklist", err)
+
+	cur := c.Task.Version
+	if got, err := a.UpdateTask(task.ID, "renamed", "high", "", cur); err != nil || got.Title != "renamed" || len(got.Tags) != 1 {
+		t.Fatalf("update at the current version = %+v, %v", got, err)
+	}
+	if got, err := a.UpdateTask(task.ID, "forced", "low", "", 0); err != nil || got.Title != "forced" {
+		t.Fatalf("version 0 skips the check: %+v, %v", got, err)
+	}
+
+	st, err := a.AddSubtask(task.ID, "draft")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ToggleSubtask(st.ID, st.Version); err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.ToggleSubtask(st.ID, st.Version)
+	if c := wantConflict(t, "stale subtask toggle", err); c.Subtask == nil || !c.Subtask.Completed {
+		t.Fatalf("conflict carries %+v, want the completed subtask", c.Subtask)
+	}
+	wantConflict(t, "stale subtask delete", a.DeleteSubtask(st.ID, st.Version))
+
+	work, err := a.CreateCategory(0, "work", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home, err := a.CreateCategory(0, "home", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.MoveCategory(home.ID, work.ID, 0, home.Version); err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.MoveCategory(home.ID, 0, 0, home.Version)
+	if c := wantConflict(t, "stale category move", err); c.Category == nil || c.Category.ParentID == nil {
+		t.Fatalf("conflict carries %+v, want the moved category", c.Category)
+	}
+	wantConflict(t, "stale category delete", a.DeleteCategory(home.ID, models.CategoryClearTasks, 0, home.Version))
+
+	ps, err := a.GetPriorities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantConflict(t, "stale priority delete", a.DeletePriority(ps[0].ID, ps[1].ID, ps[0].Version+1))
+	tags, err := a.GetTags()
+	if err != nil || len(tags) != 1 {
+		t.Fatalf("tags = %+v, %v", tags, err)
+	}
+	errands, err := a.CreateTag("errands", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.MergeTags(tags[0].ID, errands.ID, tags[0].Version+1)
+	wantConflict(t, "stale tag merge", err)
+}
+
+// TestUndoHistoryPerClient edits a task through usecases built for another
+// client, as the REST API does; the window must not be able to undo it.
+func TestUndoHistoryPerClient(t *testing.T) {
+	db, cfg := openTestDB(t)
+	a := newAppOn(t, db, cfg)
+	uid, err := a.session.userID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := bootstrap.NewUsecases(db, cfg)
+	if _, err := api.Tasks.Session(1).AddTask(uid, models.CreateTaskInput{Title: "from the API", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Undo(); !errors.Is(err, service.ErrNothingToUndo) {
+		t.Fatalf("window undo of an API change: got %v, want ErrNothingToUndo", err)
+	}
+	if _, err := api.Tasks.Session(2).Undo(uid); !errors.Is(err, service.ErrNothingToUndo) {
+		t.Fatalf("undo from another API session: got %v, want ErrNothingToUndo", err)
+	}
+	if _, err := api.Tasks.Session(1).Undo(uid); err != nil {
+		t.Fatalf("API undo: %v", err)
+	}
+}
@@ -0,0 +1,603 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"todo-app/backend/internal/dto"
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+var filters = map[string]string{
+	"":          "",
+	"all":       "",
+	"active":    "active",
+	"completed": "completed",
+	"archived":  "archived",
+	"overdue":   "overdue",
+	"today":     "today",
+	"week":      "week",
+}
+
+func newFlags(name string, asJSON *bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(asJSON, "json", false, "print JSON")
+	return fs
+}
+
+// parseInterleaved lets flags follow positional arguments, as in
+// `todo add "Buy milk" --priority high`.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos, nil
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+func parseIDs(args []string) ([]int64, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%w: at least one task id is required", errUsage)
+	}
+	ids := make([]int64, 0, len(args))
+	for _, a := range args {
+		id, err := strconv.ParseInt(a, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: invalid task id %q", errUsage, a)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func splitTags(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func (c *cli) login(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("login", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: expected exactly one email", errUsage)
+	}
+	password, err := c.readPassword()
+	if err != nil {
+		return err
+	}
+	sess, err := c.uc.Auth.Login(pos[0], password)
+	if err != nil {
+		return err
+	}
+	if old, _ := loadSession(); old != nil {
+		_ = c.uc.Auth.Logout(old.RefreshToken)
+	}
+	if err := saveSession(sess); err != nil {
+		return err
+	}
+	return c.printUser(sess.User, asJSON)
+}
+
+func (c *cli) logout(args []string) error {
+	sess, err := loadSession()
+	if err != nil {
+		return err
+	}
+	if sess != nil {
+		if err := c.uc.Auth.Logout(sess.RefreshToken); err != nil {
+			return err
+		}
+	}
+	return removeSession()
+}
+
+func (c *cli) whoami(args []string) error {
+	var asJSON bool
+	if _, err := parseInterleaved(newFlags("whoami", &asJSON), args); err != nil {
+		return err
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	u, err := c.uc.Auth.GetProfile(uid)
+	if err != nil {
+		return err
+	}
+	return c.printUser(u, asJSON)
+}
+
+func (c *cli) add(args []string) error {
+	var asJSON bool
+	fs := newFlags("add", &asJSON)
+	priority := fs.String("priority", "", "priority level name; the default level when empty")
+	due := fs.String("due", "", "due date (YYYY-MM-DD, YYYY-MM-DDTHH:MM or RFC 3339)")
+	description := fs.String("description", "", "description in Markdown")
+	tags := fs.String("tags", "", "comma-separated tags")
+	category := fs.Int64("category", 0, "category id")
+	pos, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		return fmt.Errorf("%w: a title is required", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	dueAt, err := dto.ParseDate(*due)
+	if err != nil {
+		return err
+	}
+	in := models.CreateTaskInput{
+		Title:       strings.Join(pos, " "),
+		Description: *description,
+		Priority:    *priority,
+		DueDate:     dueAt,
+		Tags:        splitTags(*tags),
+	}
+	if *category > 0 {
+		in.CategoryID = category
+	}
+	t, err := c.uc.Tasks.AddTask(uid, in)
+	if err != nil {
+		return err
+	}
+	return c.printTask(t, asJSON)
+}
+
+func (c *cli) list(args []string) error {
+	var asJSON bool
+	fs := newFlags("list", &asJSON)
+	filter := fs.String("filter", "", "all, active, completed, archived, overdue, today or week")
+	order := fs.String("sort", "", "created, manual, due, priority or title")
+	pos, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 1 || (len(pos) == 1 && *filter != "") {
+		return fmt.Errorf("%w: expected at most one filter", errUsage)
+	}
+	if len(pos) == 1 {
+		*filter = pos[0]
+	}
+	f, ok := filters[strings.ToLower(*filter)]
+	if !ok {
+		return fmt.Errorf("%w: unknown filter %q", errUsage, *filter)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	ts, err := c.uc.Tasks.GetTasks(uid, f, strings.ToLower(*order))
+	if err != nil {
+		return err
+	}
+	return c.printTasks(ts, asJSON)
+}
+
+func (c *cli) search(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("search", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		return fmt.Errorf("%w: a query is required", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	ts, err := c.uc.Tasks.SearchTasks(uid, strings.Join(pos, " "))
+	if err != nil {
+		return err
+	}
+	return c.printTasks(ts, asJSON)
+}
+
+func (c *cli) views(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("views", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: views takes no arguments", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	vs, err := c.uc.Views.List(uid)
+	if err != nil {
+		return err
+	}
+	return c.printViews(vs, asJSON)
+}
+
+func (c *cli) categories(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("categories", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: categories takes no arguments", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	cs, err := c.uc.Categories.List(uid)
+	if err != nil {
+		return err
+	}
+	return c.printCategories(cs, asJSON)
+}
+
+func (c *cli) priorities(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("priorities", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: priorities takes no arguments", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	ps, err := c.uc.Priorities.List(uid)
+	if err != nil {
+		return err
+	}
+	return c.printPriorities(ps, asJSON)
+}
+
+// view lists the tasks of a view given by id or, ignoring case, by name.
+func (c *cli) view(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("view", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		return fmt.Errorf("%w: a view id or name is required", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	name := strings.Join(pos, " ")
+	id, err := strconv.ParseInt(name, 10, 64)
+	if err != nil {
+		vs, err := c.uc.Views.List(uid)
+		if err != nil {
+			return err
+		}
+		for _, v := range vs {
+			if strings.EqualFold(v.Name, name) {
+				id = v.ID
+				break
+			}
+		}
+		if id == 0 {
+			return fmt.Errorf("no view named %q", name)
+		}
+	}
+	ts, err := c.uc.Views.Tasks(uid, id)
+	if err != nil {
+		return err
+	}
+	return c.printTasks(ts, asJSON)
+}
+
+func (c *cli) done(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("done", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	n, err := c.uc.Tasks.BulkComplete(uid, ids)
+	if err != nil {
+		return err
+	}
+	return c.printCount("completed", n, asJSON)
+}
+
+func (c *cli) edit(args []string) error {
+	var asJSON bool
+	fs := newFlags("edit", &asJSON)
+	title := fs.String("title", "", "new title")
+	description := fs.String("description", "", `new description in Markdown; "" clears it`)
+	priority := fs.String("priority", "", "priority level name")
+	due := fs.String("due", "", `new due date; "" clears it`)
+	future := fs.Bool("future", false, "apply to this and all later occurrences of a repeating task")
+	pos, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected exactly one task id", errUsage)
+	}
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["title"] && !set["description"] && !set["priority"] && !set["due"] {
+		return fmt.Errorf("%w: nothing to change; pass --title, --description, --priority or --due", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	t, err := c.uc.Tasks.GetTask(uid, ids[0])
+	if err != nil {
+		return err
+	}
+	if set["title"] {
+		t.Title = *title
+	}
+	if set["description"] {
+		t.Description = *description
+	}
+	if set["priority"] {
+		t.Priority = *priority
+	}
+	if set["due"] {
+		if t.DueDate, err = dto.ParseDate(*due); err != nil {
+			return err
+		}
+	}
+	scope := service.ScopeThis
+	if *future {
+		scope = service.ScopeFuture
+	}
+	t, err = c.uc.Tasks.UpdateOccurrence(uid, *t, scope)
+	if err != nil {
+		return err
+	}
+	return c.printTask(t, asJSON)
+}
+
+// applyTagArgs replaces current with args, unless every arg is a +tag or -tag
+// edit, in which case those tags are added to or removed from current.
+func applyTagArgs(current, args []string) []string {
+	edit := len(args) > 0
+	for _, a := range args {
+		if !strings.HasPrefix(a, "+") && !strings.HasPrefix(a, "-") {
+			edit = false
+			break
+		}
+	}
+	if !edit {
+		return args
+	}
+	res := append([]string{}, current...)
+	for _, a := range args {
+		name := a[1:]
+		if a[0] == '+' {
+			res = append(res, name)
+			continue
+		}
+		kept := res[:0]
+		for _, t := range res {
+			if t != name {
+				kept = append(kept, t)
+			}
+		}
+		res = kept
+	}
+	return res
+}
+
+func (c *cli) tag(args []string) error {
+	var asJSON bool
+	fs := newFlags("tag", &asJSON)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	pos := fs.Args()
+	if len(pos) == 0 {
+		return fmt.Errorf("%w: a task id is required", errUsage)
+	}
+	ids, err := parseIDs(pos[:1])
+	if err != nil {
+		return err
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	t, err := c.uc.Tasks.GetTask(uid, ids[0])
+	if err != nil {
+		return err
+	}
+	t, err = c.uc.Tasks.SetTags(uid, t.ID, applyTagArgs(t.Tags, pos[1:]), t.Version)
+	if err != nil {
+		return err
+	}
+	return c.printTask(t, asJSON)
+}
+
+func (c *cli) repeat(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("repeat", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	if len(pos) < 2 {
+		return fmt.Errorf("%w: a task id and a rule are required", errUsage)
+	}
+	ids, err := parseIDs(pos[:1])
+	if err != nil {
+		return err
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	var t *models.Task
+	if rule := strings.Join(pos[1:], " "); rule == "none" {
+		t, err = c.uc.Tasks.ClearRepeatRule(uid, ids[0], 0)
+	} else {
+		t, err = c.uc.Tasks.SetRepeatRule(uid, ids[0], rule, 0)
+	}
+	if err != nil {
+		return err
+	}
+	return c.printTask(t, asJSON)
+}
+
+func (c *cli) checklist(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("checklist", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected exactly one task id", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	t, err := c.uc.Tasks.ConvertChecklist(uid, ids[0], 0)
+	if err != nil {
+		return err
+	}
+	return c.printTask(t, asJSON)
+}
+
+func (c *cli) skip(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("skip", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected exactly one task id", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	next, err := c.uc.Tasks.SkipOccurrence(uid, ids[0], 0)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		if asJSON {
+			return c.printJSON(nil)
+		}
+		_, err := fmt.Fprintln(c.stdout, "no more occurrences")
+		return err
+	}
+	return c.printTask(next, asJSON)
+}
+
+func (c *cli) history(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("history", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return fmt.Errorf("%w: expected exactly one task id", errUsage)
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	t, err := c.uc.Tasks.GetTask(uid, ids[0])
+	if err != nil {
+		return err
+	}
+	if t.SeriesID == nil {
+		return service.ErrNotRepeating
+	}
+	history, err := c.uc.Tasks.GetSeriesHistory(uid, *t.SeriesID)
+	if err != nil {
+		return err
+	}
+	return c.printHistory(history, asJSON)
+}
+
+func (c *cli) rm(args []string) error {
+	var asJSON bool
+	pos, err := parseInterleaved(newFlags("rm", &asJSON), args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	n, err := c.uc.Tasks.BulkDelete(uid, ids)
+	if err != nil {
+		return err
+	}
+	return c.printCount("deleted", n, asJSON)
+}
+
+func (c *cli) stats(args []string) error {
+	var asJSON bool
+	if _, err := parseInterleaved(newFlags("stats", &asJSON), args); err != nil {
+		return err
+	}
+	uid, err := c.userID()
+	if err != nil {
+		return err
+	}
+	s, err := c.uc.Stats.Snapshot(uid)
+	if err != nil {
+		return err
+	}
+	return c.printStats(dto.FromStats(s), asJSON)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseInterleaved(t *testing.T) {
+	var asJSON bool
+	fs := newFlags("add", &asJSON)
+	priority := fs.String("priority", "medium", "")
+	pos, err := parseInterleaved(fs, []string{"Buy", "--priority", "high", "milk", "--json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pos, []string{"Buy", "milk"}) || *priority != "high" || !asJSON {
+		t.Fatalf("pos=%v priority=%q json=%v", pos, *priority, asJSON)
+	}
+
+	if _, err := parseInterleaved(newFlags("add", &asJSON), []string{"--nope"}); !errors.Is(err, errUsage) {
+		t.Fatalf("unknown flag: got %v", err)
+	}
+}
+
+func TestApplyTagArgs(t *testing.T) {
+	cases := []struct {
+		current, args, want []string
+	}{
+		{[]string{"a", "b"}, []string{"c", "d"}, []string{"c", "d"}},
+		{[]string{"a", "b"}, []string{"+c", "-a"}, []string{"b", "c"}},
+		{[]string{"a"}, []string{"+b", "plain"}, []string{"+b", "plain"}},
+		{[]string{"a"}, nil, nil},
+	}
+	for _, c := range cases {
+		if got := applyTagArgs(c.current, c.args); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("applyTagArgs(%v, %v) = %v, want %v", c.current, c.args, got, c.want)
+		}
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	if _, err := parseIDs(nil); !errors.Is(err, errUsage) {
+		t.Fatal("expected usage error for no ids")
+	}
+	if _, err := parseIDs([]string{"1", "x"}); !errors.Is(err, errUsage) {
+		t.Fatal("expected usage error for non-numeric id")
+	}
+	ids, err := parseIDs([]string{"3", "5"})
+	if err != nil || !reflect.DeepEqual(ids, []int64{3, 5}) {
+		t.Fatalf("ids=%v err=%v", ids, err)
+	}
+}
@@ -0,0 +1,118 @@
+// Command todo manages tasks in the Todo App database from a terminal.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"todo-app/backend/internal/bootstrap"
+	"todo-app/backend/internal/config"
+)
+
+const usage = `usage: todo <command> [flags] [args]
+
+commands:
+  login <email>          log in and remember the session
+  logout                 forget the session
+  whoami                 show the logged-in user
+  add <title>            add a task (--description, --priority, --due, --tags, --category)
+  list [filter]          list tasks; filter: all, active, completed, archived, overdue, today, week (--sort manual, due, priority, title)
+  search <query>         search, e.g. 'tag:work priority:high due<2026-11-01 -is:done "exact phrase"'
+  views                  list built-in and saved views
+  categories             list the category tree with task counts
+  priorities             list the priority levels, highest first, with task counts
+  view <id|name>         list the tasks of a view, e.g. "todo view today"
+  done <id>...           mark tasks completed
+  edit <id>              change a task (--title, --description, --priority, --due; --future for later occurrences too)
+  checklist <id>         turn "- [ ]" lines of the description into subtasks
+  tag <id> <tag>...      replace tags, or add/remove with +tag / -tag
+  repeat <id> <rule>     repeat a task ("every weekday", "FREQ=MONTHLY;BYDAY=-1FR"); "none" stops
+  skip <id>              skip an occurrence of a repeating task and show the next one
+  history <id>           list past and open occurrences of a repeating task
+  rm <id>...             move tasks to the trash
+  stats                  show counters
+
+Every command accepts --json to print TaskDTO-shaped JSON instead of a table.
+Set TODOAPP_EMAIL and TODOAPP_PASSWORD to skip "todo login" in scripts.
+`
+
+type cli struct {
+	uc     bootstrap.Usecases
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command func(c *cli, args []string) error
+
+var commands = map[string]command{
+	"login":      (*cli).login,
+	"logout":     (*cli).logout,
+	"whoami":     (*cli).whoami,
+	"add":        (*cli).add,
+	"list":       (*cli).list,
+	"ls":         (*cli).list,
+	"search":     (*cli).search,
+	"views":      (*cli).views,
+	"categories": (*cli).categories,
+	"priorities": (*cli).priorities,
+	"view":       (*cli).view,
+	"done":       (*cli).done,
+	"edit":       (*cli).edit,
+	"checklist":  (*cli).checklist,
+	"tag":        (*cli).tag,
+	"repeat":     (*cli).repeat,
+	"skip":       (*cli).skip,
+	"history":    (*cli).history,
+	"rm":         (*cli).rm,
+	"stats":      (*cli).stats,
+}
+
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(os.Stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "todo: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		return 1
+	}
+	db, err := bootstrap.OpenDB(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		return 1
+	}
+	defer db.Close()
+	c := &cli{
+		uc:     bootstrap.NewUsecases(db, cfg),
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	if err := cmd(c, args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "todo %s: %v\n", args[0], err)
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "todo:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"todo-app/backend/internal/dto"
+	"todo-app/backend/internal/models"
+)
+
+func (c *cli) printJSON(v any) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) printTasks(ts []models.Task, asJSON bool) error {
+	if asJSON {
+		out := make([]dto.Task, 0, len(ts))
+		for i := range ts {
+			out = append(out, dto.FromTask(&ts[i]))
+		}
+		return c.printJSON(out)
+	}
+	if len(ts) == 0 {
+		fmt.Fprintln(c.stdout, "no tasks")
+		return nil
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDONE\tPRIORITY\tDUE\tTITLE\tTAGS")
+	for _, t := range ts {
+		done := ""
+		if t.Completed {
+			done = "x"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", t.ID, done, t.Priority, formatDue(t.DueDate), t.Title, strings.Join(t.Tags, ","))
+	}
+	return w.Flush()
+}
+
+func (c *cli) printTask(t *models.Task, asJSON bool) error {
+	if asJSON {
+		return c.printJSON(dto.FromTask(t))
+	}
+	return c.printTasks([]models.Task{*t}, false)
+}
+
+func (c *cli) printHistory(history []models.SeriesOccurrence, asJSON bool) error {
+	if asJSON {
+		out := make([]dto.Occurrence, 0, len(history))
+		for i := range history {
+			out = append(out, dto.FromOccurrence(&history[i]))
+		}
+		return c.printJSON(out)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OCCURRENCE\tSTATUS\tID\tTITLE")
+	for _, o := range history {
+		id := "-"
+		if o.TaskID != nil {
+			id = fmt.Sprint(*o.TaskID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formatDue(&o.OccurrenceAt), o.Status, id, o.Title)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printViews(vs []models.SavedView, asJSON bool) error {
+	if asJSON {
+		out := make([]dto.View, 0, len(vs))
+		for i := range vs {
+			out = append(out, dto.FromView(&vs[i]))
+		}
+		return c.printJSON(out)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME")
+	for _, v := range vs {
+		fmt.Fprintf(w, "%d\t%s\n", v.ID, v.Name)
+	}
+	return w.Flush()
+}
+
+// printCategories indents subcategories under their parent; cs is in the
+// depth-first order of CategoryService.List.
+func (c *cli) printCategories(cs []models.Category, asJSON bool) error {
+	if asJSON {
+		if cs == nil {
+			cs = []models.Category{}
+		}
+		return c.printJSON(cs)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tTASKS")
+	depth := map[int64]int{}
+	for _, cat := range cs {
+		if cat.ParentID != nil {
+			depth[cat.ID] = depth[*cat.ParentID] + 1
+		}
+		fmt.Fprintf(w, "%d\t%s%s\t%d\n", cat.ID, strings.Repeat("  ", depth[cat.ID]), cat.Name, cat.TotalCount)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printPriorities(ps []models.Priority, asJSON bool) error {
+	if asJSON {
+		if ps == nil {
+			ps = []models.Priority{}
+		}
+		return c.printJSON(ps)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tLABEL\tWEIGHT\tTASKS")
+	for _, p := range ps {
+		name := p.Name
+		if p.Default {
+			name += " (default)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\n", p.ID, name, p.Label, p.Weight, p.Count)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printCount(verb string, n int64, asJSON bool) error {
+	if asJSON {
+		return c.printJSON(map[string]int64{"count": n})
+	}
+	_, err := fmt.Fprintf(c.stdout, "%s %d task(s)\n", verb, n)
+	return err
+}
+
+func (c *cli) printStats(s dto.Stats, asJSON bool) error {
+	if asJSON {
+		return c.printJSON(s)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "total\t%d\nactive\t%d\ncompleted\t%d\noverdue\t%d\n", s.Total, s.Active, s.Completed, s.Overdue)
+	for _, p := range s.Priorities {
+		fmt.Fprintf(w, "priority %s\t%d\n", p.Name, p.Count)
+	}
+	return w.Flush()
+}
+
+func (c *cli) printUser(u models.UserPublic, asJSON bool) error {
+	if asJSON {
+		return c.printJSON(u)
+	}
+	_, err := fmt.Fprintf(c.stdout, "%s (id %d)\n", u.Email, u.ID)
+	return err
+}
+
+func formatDue(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
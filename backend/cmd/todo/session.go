@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+var errNotLoggedIn = errors.New(`not logged in: run "todo login <email>" or set TODOAPP_EMAIL and TODOAPP_PASSWORD`)
+
+func sessionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todo-app", "cli-session.json"), nil
+}
+
+func loadSession() (*models.Session, error) {
+	p, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s models.Session
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("corrupt session file %s: %w", p, err)
+	}
+	return &s, nil
+}
+
+func saveSession(s models.Session) error {
+	p, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, b, 0o600)
+}
+
+func removeSession() error {
+	p, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// userID resolves the caller: a saved session (refreshed when its access
+// token has expired) or, for scripts, credentials from the environment.
+func (c *cli) userID() (int64, error) {
+	sess, err := loadSession()
+	if err != nil {
+		return 0, err
+	}
+	if sess != nil {
+		u, err := c.uc.Auth.Verify(sess.AccessToken)
+		if err == nil {
+			return u.ID, nil
+		}
+		if !errors.Is(err, service.ErrTokenExpired) {
+			return 0, err
+		}
+		next, err := c.uc.Auth.Refresh(sess.RefreshToken)
+		if err != nil {
+			_ = removeSession()
+			return 0, err
+		}
+		if err := saveSession(next); err != nil {
+			return 0, err
+		}
+		return next.User.ID, nil
+	}
+	email, password := os.Getenv("TODOAPP_EMAIL"), os.Getenv("TODOAPP_PASSWORD")
+	if email == "" || password == "" {
+		return 0, errNotLoggedIn
+	}
+	s, err := c.uc.Auth.Login(email, password)
+	if err != nil {
+		return 0, err
+	}
+	// Nothing is persisted for environment logins, so drop the refresh token.
+	_ = c.uc.Auth.Logout(s.RefreshToken)
+	return s.User.ID, nil
+}
+
+func (c *cli) readPassword() (string, error) {
+	if p := os.Getenv("TODOAPP_PASSWORD"); p != "" {
+		return p, nil
+	}
+	fmt.Fprint(c.stderr, "Password: ")
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import "net/http"
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (s *Server) register(w http.ResponseWriter, r *http.Request) {
+	var in credentials
+	if !decode(w, r, &in) {
+		return
+	}
+	u, err := s.uc.Auth.Register(in.Email, in.Password)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	var in credentials
+	if !decode(w, r, &in) {
+		return
+	}
+	sess, err := s.uc.Auth.Login(in.Email, in.Password)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sess)
+}
+
+func (s *Server) refresh(w http.ResponseWriter, r *http.Request) {
+	var in refreshRequest
+	if !decode(w, r, &in) {
+		return
+	}
+	sess, err := s.uc.Auth.Refresh(in.RefreshToken)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sess)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	var in refreshRequest
+	if !decode(w, r, &in) {
+		return
+	}
+	if err := s.uc.Auth.Logout(in.RefreshToken); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) me(w http.ResponseWriter, r *http.Request, userID int64) {
+	u, err := s.uc.Auth.GetProfile(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
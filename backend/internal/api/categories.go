@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"todo-app/backend/internal/models"
+)
+
+func (s *Server) listCategories(w http.ResponseWriter, r *http.Request, userID int64) {
+	cs, err := s.uc.Categories.List(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, cs)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createCategory(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in struct {
+		Name     string `json:"name"`
+		ParentID *int64 `json:"parentId"`
+		Color    string `json:"color"`
+		Icon     string `json:"icon"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	c, err := s.uc.Categories.Create(userID, models.Category{Name: in.Name, ParentID: in.ParentID, Color: in.Color, Icon: in.Icon})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (s *Server) updateCategory(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Name    string `json:"name"`
+		Color   string `json:"color"`
+		Icon    string `json:"icon"`
+		Version int64  `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	c, err := s.uc.Categories.Update(userID, models.Category{ID: id, Name: in.Name, Color: in.Color, Icon: in.Icon, Version: in.Version})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) moveCategory(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		ParentID *int64 `json:"parentId"`
+		Position int    `json:"position"`
+		Version  int64  `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	c, err := s.uc.Categories.Move(userID, id, in.ParentID, in.Position, in.Version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteCategory(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	var moveTo *int64
+	if v := r.URL.Query().Get("to"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "bad_request", "invalid category id "+strconv.Quote(v))
+			return
+		}
+		moveTo = &n
+	}
+	if err := s.uc.Categories.Delete(userID, id, r.URL.Query().Get("tasks"), moveTo, version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) stats(w http.ResponseWriter, r *http.Request, userID int64) {
+	st, err := s.uc.Stats.Snapshot(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
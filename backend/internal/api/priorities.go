@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"todo-app/backend/internal/models"
+)
+
+type priorityInput struct {
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Color   string `json:"color"`
+	Weight  int    `json:"weight"`
+	Default bool   `json:"default"`
+	Version int64  `json:"version"`
+}
+
+func (in priorityInput) priority(id int64) models.Priority {
+	return models.Priority{ID: id, Name: in.Name, Label: in.Label, Color: in.Color, Weight: in.Weight, Default: in.Default, Version: in.Version}
+}
+
+func (s *Server) listPriorities(w http.ResponseWriter, r *http.Request, userID int64) {
+	ps, err := s.uc.Priorities.List(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, ps)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createPriority(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in priorityInput
+	if !decode(w, r, &in) {
+		return
+	}
+	p, err := s.uc.Priorities.Create(userID, in.priority(0))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (s *Server) updatePriority(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in priorityInput
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	p, err := s.uc.Priorities.Update(userID, in.priority(id))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) deletePriority(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	v := r.URL.Query().Get("to")
+	moveTo, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "to must name the priority id that takes over the tasks")
+		return
+	}
+	if err := s.uc.Priorities.Delete(userID, id, moveTo, version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,332 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo-app/backend/internal/query"
+	"todo-app/backend/internal/repository"
+	"todo-app/backend/internal/service"
+	"todo-app/backend/internal/usecase"
+)
+
+const (
+	maxBodyBytes = 1 << 20
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type Usecases struct {
+	Auth       usecase.AuthUsecase
+	Tasks      usecase.TaskUsecase
+	Categories usecase.CategoryUsecase
+	Priorities usecase.PriorityUsecase
+	Tags       usecase.TagUsecase
+	Stats      usecase.StatsUsecase
+	Views      usecase.ViewUsecase
+	Trash      usecase.TrashUsecase
+	Archive    usecase.ArchiveUsecase
+}
+
+type Server struct {
+	uc  Usecases
+	mux *http.ServeMux
+}
+
+func NewServer(uc Usecases) *Server {
+	s := &Server{uc: uc, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("POST /api/auth/register", s.register)
+	s.mux.HandleFunc("POST /api/auth/login", s.login)
+	s.mux.HandleFunc("POST /api/auth/refresh", s.refresh)
+	s.mux.HandleFunc("POST /api/auth/logout", s.logout)
+	s.mux.HandleFunc("GET /api/me", s.authed(s.me))
+
+	s.mux.HandleFunc("GET /api/tasks", s.authed(s.listTasks))
+	s.mux.HandleFunc("POST /api/tasks", s.authed(s.createTask))
+	s.mux.HandleFunc("POST /api/tasks/query", s.authed(s.queryTasks))
+	s.mux.HandleFunc("GET /api/tasks/page", s.authed(s.pageTasks))
+	s.mux.HandleFunc("DELETE /api/tasks/completed", s.authed(s.clearCompleted))
+	s.mux.HandleFunc("POST /api/tasks/bulk/complete", s.authed(s.bulkComplete))
+	s.mux.HandleFunc("POST /api/tasks/bulk/delete", s.authed(s.bulkDelete))
+	s.mux.HandleFunc("GET /api/tasks/{id}", s.authed(s.getTask))
+	s.mux.HandleFunc("PUT /api/tasks/{id}", s.authed(s.updateTask))
+	s.mux.HandleFunc("PATCH /api/tasks/{id}", s.authed(s.patchTask))
+	s.mux.HandleFunc("DELETE /api/tasks/{id}", s.authed(s.deleteTask))
+	s.mux.HandleFunc("POST /api/tasks/{id}/toggle", s.authed(s.toggleTask))
+	s.mux.HandleFunc("POST /api/tasks/{id}/unarchive", s.authed(s.unarchiveTask))
+	s.mux.HandleFunc("POST /api/tasks/{id}/move", s.authed(s.moveTask))
+	s.mux.HandleFunc("POST /api/tasks/{id}/checklist", s.authed(s.convertChecklist))
+	s.mux.HandleFunc("PUT /api/tasks/{id}/tags", s.authed(s.setTags))
+	s.mux.HandleFunc("PUT /api/tasks/{id}/category", s.authed(s.setCategory))
+	s.mux.HandleFunc("PUT /api/tasks/{id}/repeat", s.authed(s.setRepeatRule))
+	s.mux.HandleFunc("DELETE /api/tasks/{id}/repeat", s.authed(s.clearRepeatRule))
+	s.mux.HandleFunc("POST /api/tasks/{id}/skip", s.authed(s.skipOccurrence))
+	s.mux.HandleFunc("GET /api/tasks/{id}/subtasks", s.authed(s.listSubtasks))
+	s.mux.HandleFunc("POST /api/tasks/{id}/subtasks", s.authed(s.createSubtask))
+
+	s.mux.HandleFunc("GET /api/series/{id}/history", s.authed(s.seriesHistory))
+
+	s.mux.HandleFunc("POST /api/undo", s.authed(s.undo))
+	s.mux.HandleFunc("POST /api/redo", s.authed(s.redo))
+
+	s.mux.HandleFunc("PUT /api/subtasks/{id}", s.authed(s.updateSubtask))
+	s.mux.HandleFunc("POST /api/subtasks/{id}/toggle", s.authed(s.toggleSubtask))
+	s.mux.HandleFunc("DELETE /api/subtasks/{id}", s.authed(s.deleteSubtask))
+
+	s.mux.HandleFunc("GET /api/categories", s.authed(s.listCategories))
+	s.mux.HandleFunc("POST /api/categories", s.authed(s.createCategory))
+	s.mux.HandleFunc("PUT /api/categories/{id}", s.authed(s.updateCategory))
+	s.mux.HandleFunc("POST /api/categories/{id}/move", s.authed(s.moveCategory))
+	s.mux.HandleFunc("DELETE /api/categories/{id}", s.authed(s.deleteCategory))
+
+	s.mux.HandleFunc("GET /api/priorities", s.authed(s.listPriorities))
+	s.mux.HandleFunc("POST /api/priorities", s.authed(s.createPriority))
+	s.mux.HandleFunc("PUT /api/priorities/{id}", s.authed(s.updatePriority))
+	s.mux.HandleFunc("DELETE /api/priorities/{id}", s.authed(s.deletePriority))
+
+	s.mux.HandleFunc("GET /api/views", s.authed(s.listViews))
+	s.mux.HandleFunc("POST /api/views", s.authed(s.createView))
+	s.mux.HandleFunc("PUT /api/views/order", s.authed(s.reorderViews))
+	s.mux.HandleFunc("PUT /api/views/{id}", s.authed(s.updateView))
+	s.mux.HandleFunc("DELETE /api/views/{id}", s.authed(s.deleteView))
+	s.mux.HandleFunc("GET /api/views/{id}/tasks", s.authed(s.viewTasks))
+
+	s.mux.HandleFunc("GET /api/trash", s.authed(s.listTrash))
+	s.mux.HandleFunc("DELETE /api/trash", s.authed(s.purgeTrash))
+	s.mux.HandleFunc("POST /api/trash/tasks/{id}/restore", s.authed(s.restoreTask))
+	s.mux.HandleFunc("POST /api/trash/subtasks/{id}/restore", s.authed(s.restoreSubtask))
+	s.mux.HandleFunc("POST /api/trash/categories/{id}/restore", s.authed(s.restoreCategory))
+
+	s.mux.HandleFunc("GET /api/tags", s.authed(s.listTags))
+	s.mux.HandleFunc("GET /api/tags/all", s.authed(s.listTagDetails))
+	s.mux.HandleFunc("GET /api/tags/suggest", s.authed(s.suggestTags))
+	s.mux.HandleFunc("POST /api/tags", s.authed(s.createTag))
+	s.mux.HandleFunc("PUT /api/tags/{id}", s.authed(s.updateTag))
+	s.mux.HandleFunc("POST /api/tags/{id}/merge", s.authed(s.mergeTag))
+	s.mux.HandleFunc("DELETE /api/tags/{id}", s.authed(s.deleteTag))
+	s.mux.HandleFunc("GET /api/search", s.authed(s.search))
+	s.mux.HandleFunc("GET /api/stats", s.authed(s.stats))
+
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found", "no such endpoint")
+	})
+}
+
+type authedHandler func(w http.ResponseWriter, r *http.Request, userID int64)
+
+type sessionKey struct{}
+
+// authed resolves the bearer access token into the caller's user id, and
+// keeps the token's session in the request context for tasks.
+func (s *Server) authed(h authedHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(raw) == "" {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+			return
+		}
+		u, err := s.uc.Auth.Verify(strings.TrimSpace(raw))
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), sessionKey{}, u.SessionID)), u.ID)
+	}
+}
+
+// tasks returns the task usecase of the request's session, so that undo and
+// redo act on the caller's own commands and not on those of other clients
+// signed in as the same user.
+func (s *Server) tasks(r *http.Request) usecase.TaskUsecase {
+	id, _ := r.Context().Value(sessionKey{}).(int64)
+	return s.uc.Tasks.Session(id)
+}
+
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Position is the 1-based character offset of the offending token in an
+	// invalid search query.
+	Position int `json:"position,omitempty"`
+	// Fields maps each invalid input field to what is wrong with it.
+	Fields map[string]string `json:"fields,omitempty"`
+	// Current is the stored state an edit conflicted with.
+	Current any `json:"current,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("api: encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: msg}})
+}
+
+// writeErr maps domain errors onto HTTP statuses; anything unknown is logged
+// and reported as a bare 500 so internals don't leak to clients.
+func writeErr(w http.ResponseWriter, err error) {
+	var (
+		qe *query.Error
+		fe service.FieldErrors
+		ce *service.ConflictError
+	)
+	switch {
+	case errors.As(err, &ce):
+		writeJSON(w, http.StatusConflict, errorBody{Error: errorDetail{Code: "conflict", Message: ce.Error(), Current: ce.Current}})
+	case errors.As(err, &fe):
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: errorDetail{Code: "validation_failed", Message: fe.Error(), Fields: fe}})
+	case errors.As(err, &qe):
+		writeJSON(w, http.StatusBadRequest, errorBody{Error: errorDetail{Code: "invalid_query", Message: qe.Error(), Position: qe.Pos}})
+	case errors.Is(err, service.ErrInvalidCursor):
+		writeError(w, http.StatusBadRequest, "invalid_cursor", err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		writeError(w, http.StatusNotFound, "not_found", err.Error())
+	case errors.Is(err, service.ErrTitleRequired),
+		errors.Is(err, service.ErrNameRequired),
+		errors.Is(err, service.ErrInvalidRepeatRule),
+		errors.Is(err, service.ErrRepeatNeedsDueDate),
+		errors.Is(err, service.ErrNotRepeating),
+		errors.Is(err, service.ErrInvalidFilter),
+		errors.Is(err, service.ErrInvalidScope),
+		errors.Is(err, service.ErrInvalidOrder),
+		errors.Is(err, service.ErrInvalidEmail),
+		errors.Is(err, service.ErrWeakPassword),
+		errors.Is(err, service.ErrInvalidColor),
+		errors.Is(err, service.ErrSameTag),
+		errors.Is(err, service.ErrParentNotFound),
+		errors.Is(err, service.ErrCategoryCycle),
+		errors.Is(err, service.ErrInvalidDeletion),
+		errors.Is(err, service.ErrMoveTarget),
+		errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrInvalidMove),
+		errors.Is(err, service.ErrInvalidPriority),
+		errors.Is(err, service.ErrPriorityName),
+		errors.Is(err, service.ErrInvalidWeight),
+		errors.Is(err, service.ErrDefaultPriority),
+		errors.Is(err, service.ErrPriorityTarget):
+		writeError(w, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+	case errors.Is(err, service.ErrBuiltInView):
+		writeError(w, http.StatusForbidden, "forbidden", err.Error())
+	case errors.Is(err, service.ErrUserExists),
+		errors.Is(err, service.ErrTagExists),
+		errors.Is(err, service.ErrCategoryExists),
+		errors.Is(err, service.ErrPriorityExists),
+		errors.Is(err, service.ErrOccurrenceCompleted),
+		errors.Is(err, service.ErrNothingToUndo),
+		errors.Is(err, service.ErrNothingToRedo),
+		errors.Is(err, service.ErrHistoryStale):
+		writeError(w, http.StatusConflict, "conflict", err.Error())
+	case errors.Is(err, service.ErrTokenExpired):
+		writeError(w, http.StatusUnauthorized, "token_expired", err.Error())
+	case errors.Is(err, service.ErrInvalidToken),
+		errors.Is(err, service.ErrInvalidCredentials),
+		errors.Is(err, service.ErrSessionExpired):
+		writeError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+	default:
+		log.Printf("api: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal", "internal server error")
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "invalid id "+strconv.Quote(r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryVersion reads the ?version= that endpoints without a body require,
+// like needVersion.
+func queryVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	v := r.URL.Query().Get("version")
+	if v == "" {
+		return 0, needVersion(w, 0)
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "invalid version "+strconv.Quote(v))
+		return 0, false
+	}
+	return n, needVersion(w, n)
+}
+
+// needVersion answers 428 unless a write carries the version the client
+// loaded. Version 0, which skips the check, is left to internal callers so
+// that no API client overwrites a change it has not seen.
+func needVersion(w http.ResponseWriter, version int64) bool {
+	if version <= 0 {
+		writeError(w, http.StatusPreconditionRequired, "version_required", "the version of the item being changed is required")
+		return false
+	}
+	return true
+}
+
+type Page[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) (Page[T], bool) {
+	limit, offset := defaultLimit, 0
+	q := r.URL.Query()
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxLimit {
+			writeError(w, http.StatusBadRequest, "bad_request", "limit must be between 1 and "+strconv.Itoa(maxLimit))
+			return Page[T]{}, false
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "bad_request", "offset must be a non-negative integer")
+			return Page[T]{}, false
+		}
+		offset = n
+	}
+	p := Page[T]{Items: []T{}, Total: len(items), Limit: limit, Offset: offset}
+	if offset < len(items) {
+		p.Items = items[offset:min(offset+limit, len(items))]
+	}
+	return p, true
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/query"
+	"todo-app/backend/internal/repository"
+	"todo-app/backend/internal/service"
+	"todo-app/backend/internal/usecase"
+)
+
+type stubAuth struct {
+	usecase.AuthUsecase
+}
+
+func (stubAuth) Verify(accessToken string) (models.UserPublic, error) {
+	switch accessToken {
+	case "good":
+		return models.UserPublic{ID: 7, Email: "a@example.com"}, nil
+	case "old":
+		return models.UserPublic{}, service.ErrTokenExpired
+	}
+	return models.UserPublic{}, service.ErrInvalidToken
+}
+
+type stubTasks struct {
+	usecase.TaskUsecase
+	tasks []models.Task
+}
+
+func (s *stubTasks) Session(int64) usecase.TaskUsecase { return s }
+
+func (s *stubTasks) GetTasks(userID int64, filter, sort string) ([]models.Task, error) {
+	var res []models.Task
+	for _, t := range s.tasks {
+		if t.UserID == userID {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (s *stubTasks) SearchTasks(userID int64, q string) ([]models.Task, error) {
+	if _, err := query.Parse(q, time.Now(), []string{"high", "medium", "low"}); err != nil {
+		return nil, err
+	}
+	return s.GetTasks(userID, "", "")
+}
+
+func (s *stubTasks) GetTask(userID, id int64) (*models.Task, error) {
+	for _, t := range s.tasks {
+		if t.UserID == userID && t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (s *stubTasks) AddTask(userID int64, in models.CreateTaskInput) (*models.Task, error) {
+	if strings.TrimSpace(in.Title) == "" {
+		return nil, service.ErrTitleRequired
+	}
+	t := models.Task{ID: int64(len(s.tasks) + 1), UserID: userID, Title: in.Title}
+	s.tasks = append(s.tasks, t)
+	return &t, nil
+}
+
+func (s *stubTasks) PatchTask(userID int64, in models.UpdateTaskInput) (*models.Task, error) {
+	t, err := s.GetTask(userID, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Title != nil && strings.TrimSpace(*in.Title) == "" {
+		return nil, service.FieldErrors{"title": "is required"}
+	}
+	if in.Title != nil {
+		t.Title = *in.Title
+	}
+	return t, nil
+}
+
+func newTestServer(tasks *stubTasks) http.Handler {
+	return NewServer(Usecases{Auth: stubAuth{}, Tasks: tasks}).Handler()
+}
+
+func do(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body is not JSON: %q", rec.Body.String())
+	}
+	return body.Error.Code
+}
+
+func TestAuthRequired(t *testing.T) {
+	h := newTestServer(&stubTasks{})
+	cases := []struct {
+		token string
+		code  string
+	}{
+		{"", "unauthorized"},
+		{"forged", "unauthorized"},
+		{"old", "token_expired"},
+	}
+	for _, c := range cases {
+		rec := do(h, http.MethodGet, "/api/tasks", c.token, "")
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status %d", c.token, rec.Code)
+		}
+		if got := errorCode(t, rec); got != c.code {
+			t.Errorf("token %q: code %q, want %q", c.token, got, c.code)
+		}
+	}
+}
+
+func TestListTasksPaginates(t *testing.T) {
+	tasks := &stubTasks{}
+	for i := 1; i <= 5; i++ {
+		tasks.tasks = append(tasks.tasks, models.Task{ID: int64(i), UserID: 7, Title: fmt.Sprint("t", i)})
+	}
+	tasks.tasks = append(tasks.tasks, models.Task{ID: 6, UserID: 8, Title: "someone else's"})
+	h := newTestServer(tasks)
+
+	rec := do(h, http.MethodGet, "/api/tasks?limit=2&offset=3", "good", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var page Page[models.Task]
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 5 || len(page.Items) != 2 || page.Items[0].ID != 4 {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	rec = do(h, http.MethodGet, "/api/tasks?limit=0", "good", "")
+	if rec.Code != http.StatusBadRequest || errorCode(t, rec) != "bad_request" {
+		t.Fatalf("limit=0: status %d", rec.Code)
+	}
+}
+
+func TestTaskErrorsAreJSON(t *testing.T) {
+	h := newTestServer(&stubTasks{})
+
+	rec := do(h, http.MethodGet, "/api/tasks/42", "good", "")
+	if rec.Code != http.StatusNotFound || errorCode(t, rec) != "not_found" {
+		t.Fatalf("missing task: status %d", rec.Code)
+	}
+	rec = do(h, http.MethodGet, "/api/tasks/abc", "good", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad id: status %d", rec.Code)
+	}
+	rec = do(h, http.MethodPost, "/api/tasks", "good", `{"title":"  "}`)
+	if rec.Code != http.StatusUnprocessableEntity || errorCode(t, rec) != "validation_failed" {
+		t.Fatalf("empty title: status %d", rec.Code)
+	}
+	rec = do(h, http.MethodPost, "/api/tasks", "good", `{"title":"x","bogus":1}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown field: status %d", rec.Code)
+	}
+	rec = do(h, http.MethodPost, "/api/tasks", "good", `{"title":"write report"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestInvalidQueryPointsAtToken(t *testing.T) {
+	h := newTestServer(&stubTasks{})
+	rec := do(h, http.MethodGet, "/api/tasks?q=tag:work+due<soon", "good", "")
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusBadRequest || body.Error.Code != "invalid_query" || body.Error.Position != 10 {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestPatchTaskReportsFields(t *testing.T) {
+	h := newTestServer(&stubTasks{tasks: []models.Task{{ID: 1, UserID: 7, Title: "draft", Version: 1}}})
+	rec := do(h, http.MethodPatch, "/api/tasks/1", "good", `{"title":" ","version":1}`)
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity || body.Error.Code != "validation_failed" || body.Error.Fields["title"] != "is required" {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+	rec = do(h, http.MethodPatch, "/api/tasks/1", "good", `{"title":"final","version":1}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"title":"final"`) {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+func TestWritesRequireVersion(t *testing.T) {
+	h := newTestServer(&stubTasks{tasks: []models.Task{{ID: 1, UserID: 7, Title: "draft", Version: 1}}})
+	for _, c := range []struct{ method, path, body string }{
+		{http.MethodPut, "/api/tasks/1", `{"title":"final"}`},
+		{http.MethodPatch, "/api/tasks/1", `{"title":"final"}`},
+		{http.MethodPatch, "/api/tasks/1", `{"title":"final","version":0}`},
+		{http.MethodDelete, "/api/tasks/1", ""},
+		{http.MethodPost, "/api/tasks/1/toggle?version=0", ""},
+		{http.MethodPut, "/api/tasks/1/tags", `{"tags":["work"]}`},
+		{http.MethodDelete, "/api/subtasks/1", ""},
+		{http.MethodPost, "/api/categories/1/move", `{"position":0}`},
+		{http.MethodPost, "/api/tags/1/merge", `{"into":2}`},
+	} {
+		rec := do(h, c.method, c.path, "good", c.body)
+		if rec.Code != http.StatusPreconditionRequired || errorCode(t, rec) != "version_required" {
+			t.Errorf("%s %s %s: status %d, body %s", c.method, c.path, c.body, rec.Code, rec.Body)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request, userID int64) {
+	tags, err := s.uc.Tags.List(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, tags)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) listTagDetails(w http.ResponseWriter, r *http.Request, userID int64) {
+	tags, err := s.uc.Tags.Tags(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, tags)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) suggestTags(w http.ResponseWriter, r *http.Request, userID int64) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "bad_request", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+	tags, err := s.uc.Tags.Suggest(userID, r.URL.Query().Get("prefix"), limit)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in struct {
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	t, err := s.uc.Tags.Create(userID, in.Name, in.Color)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) updateTag(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Name    string `json:"name"`
+		Color   string `json:"color"`
+		Version int64  `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	t, err := s.uc.Tags.Update(userID, id, in.Name, in.Color, in.Version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) mergeTag(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Into    int64 `json:"into"`
+		Version int64 `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	t, err := s.uc.Tags.Merge(userID, id, in.Into, in.Version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteTag(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	if err := s.uc.Tags.Delete(userID, id, version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
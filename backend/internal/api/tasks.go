@@ -0,0 +1,556 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+type taskInput struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"dueDate"`
+	RepeatRule  *string    `json:"repeatRule"`
+	CategoryID  *int64     `json:"categoryId"`
+	Tags        []string   `json:"tags"`
+	// Version is required on update and must match the stored task's.
+	Version int64 `json:"version"`
+}
+
+type idsInput struct {
+	IDs []int64 `json:"ids"`
+}
+
+type countResult struct {
+	Count int64 `json:"count"`
+}
+
+// historyResult names the change an undo or redo acted on.
+type historyResult struct {
+	Label string `json:"label"`
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request, userID int64) {
+	var (
+		ts  []models.Task
+		err error
+	)
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		ts, err = s.tasks(r).SearchTasks(userID, q)
+	} else {
+		ts, err = s.tasks(r).GetTasks(userID, r.URL.Query().Get("filter"), r.URL.Query().Get("sort"))
+	}
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, ts)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// pageTasks serves keyset pages: ?filter=&cursor=&limit=, where cursor is
+// the next or prev of an earlier response.
+func (s *Server) pageTasks(w http.ResponseWriter, r *http.Request, userID int64) {
+	q := r.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > service.MaxPageSize {
+			writeError(w, http.StatusBadRequest, "bad_request", "limit must be between 1 and "+strconv.Itoa(service.MaxPageSize))
+			return
+		}
+		limit = n
+	}
+	page, err := s.tasks(r).ListTasks(userID, q.Get("filter"), q.Get("cursor"), limit)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, userID int64) {
+	res, err := s.tasks(r).Search(userID, r.URL.Query().Get("q"))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, res)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) queryTasks(w http.ResponseWriter, r *http.Request, userID int64) {
+	var f models.TaskFilter
+	if !decode(w, r, &f) {
+		return
+	}
+	ts, err := s.tasks(r).QueryTasks(userID, f)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, ts)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in taskInput
+	if !decode(w, r, &in) {
+		return
+	}
+	t, err := s.tasks(r).AddTask(userID, models.CreateTaskInput{
+		Title:       in.Title,
+		Description: in.Description,
+		Priority:    in.Priority,
+		DueDate:     in.DueDate,
+		RepeatRule:  in.RepeatRule,
+		CategoryID:  in.CategoryID,
+		Tags:        in.Tags,
+	})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) getTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	t, err := s.tasks(r).GetTask(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in taskInput
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	t, err := s.tasks(r).GetTask(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	t.Title = in.Title
+	t.Description = in.Description
+	t.Priority = in.Priority
+	t.DueDate = in.DueDate
+	t.RepeatRule = in.RepeatRule
+	t.CategoryID = in.CategoryID
+	t.Tags = in.Tags
+	t.Version = in.Version
+	updated, err := s.tasks(r).UpdateOccurrence(userID, *t, r.URL.Query().Get("scope"))
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) patchTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in models.UpdateTaskInput
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	in.ID = id
+	t, err := s.tasks(r).PatchTask(userID, in)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	if err := s.tasks(r).DeleteTask(userID, id, version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) toggleTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	t, err := s.tasks(r).ToggleTask(userID, id, version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) convertChecklist(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	t, err := s.tasks(r).ConvertChecklist(userID, id, version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) setTags(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Tags    []string `json:"tags"`
+		Version int64    `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	t, err := s.tasks(r).SetTags(userID, id, in.Tags, in.Version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) setRepeatRule(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Rule    string `json:"rule"`
+		Version int64  `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	t, err := s.tasks(r).SetRepeatRule(userID, id, in.Rule, in.Version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) clearRepeatRule(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	t, err := s.tasks(r).ClearRepeatRule(userID, id, version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) skipOccurrence(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	next, err := s.tasks(r).SkipOccurrence(userID, id, version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Next *models.Task `json:"next"`
+	}{next})
+}
+
+func (s *Server) seriesHistory(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	history, err := s.tasks(r).GetSeriesHistory(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	if history == nil {
+		history = []models.SeriesOccurrence{}
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) setCategory(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		CategoryID *int64 `json:"categoryId"`
+		Version    int64  `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	t, err := s.tasks(r).AssignCategory(userID, id, in.CategoryID, in.Version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) clearCompleted(w http.ResponseWriter, r *http.Request, userID int64) {
+	n, err := s.tasks(r).ClearCompleted(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, countResult{Count: n})
+}
+
+func (s *Server) unarchiveTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	t, err := s.tasks(r).Unarchive(userID, id, version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// moveTask takes {beforeId, afterId}, the tasks to end up between; either
+// may be 0 or left out at the ends of the list.
+func (s *Server) moveTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		BeforeID int64 `json:"beforeId"`
+		AfterID  int64 `json:"afterId"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	t, err := s.tasks(r).MoveTask(userID, id, in.BeforeID, in.AfterID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) bulkComplete(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in idsInput
+	if !decode(w, r, &in) {
+		return
+	}
+	n, err := s.tasks(r).BulkComplete(userID, in.IDs)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, countResult{Count: n})
+}
+
+func (s *Server) bulkDelete(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in idsInput
+	if !decode(w, r, &in) {
+		return
+	}
+	n, err := s.tasks(r).BulkDelete(userID, in.IDs)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, countResult{Count: n})
+}
+
+func (s *Server) listSubtasks(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.tasks(r).GetTask(userID, id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	ss, err := s.tasks(r).GetSubtasks(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, ss)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createSubtask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Title string `json:"title"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	st, err := s.tasks(r).AddSubtask(userID, id, in.Title)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, st)
+}
+
+func (s *Server) updateSubtask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var in struct {
+		Title     string `json:"title"`
+		Completed bool   `json:"completed"`
+		Version   int64  `json:"version"`
+	}
+	if !decode(w, r, &in) {
+		return
+	}
+	if !needVersion(w, in.Version) {
+		return
+	}
+	st, err := s.tasks(r).UpdateSubtask(userID, models.Subtask{ID: id, Title: in.Title, Completed: in.Completed, Version: in.Version})
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) toggleSubtask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	st, err := s.tasks(r).ToggleSubtask(userID, id, version)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) deleteSubtask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := queryVersion(w, r)
+	if !ok {
+		return
+	}
+	if err := s.tasks(r).DeleteSubtask(userID, id, version); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) undo(w http.ResponseWriter, r *http.Request, userID int64) {
+	label, err := s.tasks(r).Undo(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, historyResult{Label: label})
+}
+
+func (s *Server) redo(w http.ResponseWriter, r *http.Request, userID int64) {
+	label, err := s.tasks(r).Redo(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, historyResult{Label: label})
+}
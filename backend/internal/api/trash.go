@@ -0,0 +1,60 @@
+package api
+
+import "net/http"
+
+func (s *Server) listTrash(w http.ResponseWriter, r *http.Request, userID int64) {
+	tr, err := s.uc.Trash.List(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tr)
+}
+
+func (s *Server) restoreTask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	t, err := s.uc.Trash.RestoreTask(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) restoreSubtask(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	st, err := s.uc.Trash.RestoreSubtask(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) restoreCategory(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	c, err := s.uc.Trash.RestoreCategory(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (s *Server) purgeTrash(w http.ResponseWriter, r *http.Request, userID int64) {
+	n, err := s.uc.Trash.Purge(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, countResult{Count: n})
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"todo-app/backend/internal/models"
+)
+
+type viewInput struct {
+	Name   string            `json:"name"`
+	Filter models.TaskFilter `json:"filter"`
+}
+
+// viewID is pathID for views, whose built-in ids are negative.
+func viewID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		writeError(w, http.StatusBadRequest, "bad_request", "invalid id "+strconv.Quote(r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+func (s *Server) listViews(w http.ResponseWriter, r *http.Request, userID int64) {
+	vs, err := s.uc.Views.List(userID)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, vs)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) createView(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in viewInput
+	if !decode(w, r, &in) {
+		return
+	}
+	v, err := s.uc.Views.Create(userID, in.Name, in.Filter)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, v)
+}
+
+func (s *Server) updateView(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := viewID(w, r)
+	if !ok {
+		return
+	}
+	var in viewInput
+	if !decode(w, r, &in) {
+		return
+	}
+	v, err := s.uc.Views.Update(userID, id, in.Name, in.Filter)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) reorderViews(w http.ResponseWriter, r *http.Request, userID int64) {
+	var in idsInput
+	if !decode(w, r, &in) {
+		return
+	}
+	if err := s.uc.Views.Reorder(userID, in.IDs); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteView(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := viewID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.uc.Views.Delete(userID, id); err != nil {
+		writeErr(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) viewTasks(w http.ResponseWriter, r *http.Request, userID int64) {
+	id, ok := viewID(w, r)
+	if !ok {
+		return
+	}
+	ts, err := s.uc.Views.Tasks(userID, id)
+	if err != nil {
+		writeErr(w, err)
+		return
+	}
+	page, ok := paginate(w, r, ts)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,158 @@
+package bootstrap
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"todo-app/backend/internal/config"
+	"todo-app/backend/internal/migrations"
+	"todo-app/backend/internal/repository"
+	"todo-app/backend/internal/service"
+	"todo-app/backend/internal/usecase"
+
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// Usecases is the full set of application usecases over one database, as
+// used by the desktop app, the REST API and the CLI. Each of them builds its
+// own: Tasks keeps the undo history of the clients it serves.
+type Usecases struct {
+	Auth       usecase.AuthUsecase
+	Tasks      usecase.TaskUsecase
+	Categories usecase.CategoryUsecase
+	Priorities usecase.PriorityUsecase
+	Tags       usecase.TagUsecase
+	Stats      usecase.StatsUsecase
+	Views      usecase.ViewUsecase
+	Trash      usecase.TrashUsecase
+	Archive    usecase.ArchiveUsecase
+}
+
+// Dialect picks the storage backend from the connection string scheme:
+// sqlite:<path> or sqlite://<path> selects the embedded SQLite database,
+// anything else (postgres:// URLs or keyword DSNs) is handed to Postgres.
+func Dialect(dsn string) migrations.Dialect {
+	if _, ok := sqlitePath(dsn); ok {
+		return migrations.SQLite
+	}
+	return migrations.Postgres
+}
+
+func sqlitePath(dsn string) (string, bool) {
+	for _, prefix := range []string{"sqlite://", "sqlite:"} {
+		if strings.HasPrefix(dsn, prefix) {
+			return strings.TrimPrefix(dsn, prefix), true
+		}
+	}
+	return "", false
+}
+
+// OpenDB connects to the configured database and migrates it to the latest
+// schema version.
+func OpenDB(cfg config.Config) (*sql.DB, error) {
+	d := Dialect(cfg.DBConn)
+	var (
+		db  *sql.DB
+		err error
+	)
+	if d == migrations.SQLite {
+		db, err = openSQLite(cfg.DBConn)
+	} else {
+		db, err = sql.Open("postgres", cfg.DBConn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrations.Run(db, d); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// openSQLite opens the database file, creating its directory if needed.
+// Transactions start with BEGIN IMMEDIATE so the desktop app and the CLI can
+// share a file, and one connection keeps writers inside the process in line.
+func openSQLite(dsn string) (*sql.DB, error) {
+	path, _ := sqlitePath(dsn)
+	path, query, _ := strings.Cut(path, "?")
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	params := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	if query != "" {
+		params += "&" + query
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+func NewUsecases(db *sql.DB, cfg config.Config) Usecases {
+	if Dialect(cfg.DBConn) == migrations.SQLite {
+		taskRepo, subtaskRepo := repository.NewSQLiteTaskRepository(db), repository.NewSQLiteSubtaskRepository(db)
+		history := service.NewHistory(taskRepo, subtaskRepo, service.DefaultHistoryLimit)
+		categories := repository.NewSQLiteCategoryRepository(db)
+		priorities := repository.NewSQLitePriorityRepository(db)
+		tasks := service.NewTaskService(
+			taskRepo,
+			subtaskRepo,
+			repository.NewSQLiteSeriesRepository(db),
+			categories,
+			priorities,
+		)
+		return Usecases{
+			Auth: usecase.NewAuthUsecase(service.NewAuthService(
+				repository.NewSQLiteAuthRepository(db),
+				repository.NewSQLiteTokenRepository(db),
+				cfg,
+			)),
+			Tasks:      usecase.NewTaskUsecase(tasks, history),
+			Categories: usecase.NewCategoryUsecase(service.NewCategoryService(categories)),
+			Priorities: usecase.NewPriorityUsecase(service.NewPriorityService(priorities)),
+			Tags:       usecase.NewTagUsecase(service.NewTagService(repository.NewSQLiteTagRepository(db))),
+			Stats:      usecase.NewStatsUsecase(service.NewStatsService(repository.NewSQLiteStatsRepository(db))),
+			Views:      usecase.NewViewUsecase(service.NewViewService(repository.NewSQLiteViewRepository(db), tasks, priorities)),
+			Trash:      usecase.NewTrashUsecase(service.NewTrashService(repository.NewSQLiteTrashRepository(db), cfg.TrashRetention)),
+			Archive:    usecase.NewArchiveUsecase(service.NewArchiveService(repository.NewSQLiteTaskRepository(db), cfg.ArchiveAfter)),
+		}
+	}
+	taskRepo, subtaskRepo := repository.NewTaskRepository(db), repository.NewSubtaskRepository(db)
+	history := service.NewHistory(taskRepo, subtaskRepo, service.DefaultHistoryLimit)
+	categories := repository.NewCategoryRepository(db)
+	priorities := repository.NewPriorityRepository(db)
+	tasks := service.NewTaskService(
+		taskRepo,
+		subtaskRepo,
+		repository.NewSeriesRepository(db),
+		categories,
+		priorities,
+	)
+	return Usecases{
+		Auth: usecase.NewAuthUsecase(service.NewAuthService(
+			repository.NewAuthRepository(db),
+			repository.NewTokenRepository(db),
+			cfg,
+		)),
+		Tasks:      usecase.NewTaskUsecase(tasks, history),
+		Categories: usecase.NewCategoryUsecase(service.NewCategoryService(categories)),
+		Priorities: usecase.NewPriorityUsecase(service.NewPriorityService(priorities)),
+		Tags:       usecase.NewTagUsecase(service.NewTagService(repository.NewTagRepository(db))),
+		Stats:      usecase.NewStatsUsecase(service.NewStatsService(repository.NewStatsRepository(db))),
+		Views:      usecase.NewViewUsecase(service.NewViewService(repository.NewViewRepository(db), tasks, priorities)),
+		Trash:      usecase.NewTrashUsecase(service.NewTrashService(repository.NewTrashRepository(db), cfg.TrashRetention)),
+		Archive:    usecase.NewArchiveUsecase(service.NewArchiveService(repository.NewTaskRepository(db), cfg.ArchiveAfter)),
+	}
+}
@@ -1,22 +1,207 @@
 package config
 
-import "os"
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+const defaultJWTSecret = "devsecret"
+
+var ErrDefaultSecret = errors.New("TODOAPP_JWT is not set: refusing to sign sessions with the built-in development secret outside the embedded database (set TODOAPP_DEV=1 for local development)")
+
+// secretFile holds the signing key generated for the embedded database, next
+// to the database file.
+const secretFile = "jwt.key"
+
+// LegacyDBConn is the PostgreSQL database earlier versions used when no
+// connection string was configured.
+const LegacyDBConn = "host=127.0.0.1 port=5432 user=postgres password=postgres dbname=todoapp sslmode=disable"
+
+var ErrLegacyDB = errors.New("TODOAPP_DB is not set and the PostgreSQL database on 127.0.0.1:5432 where earlier versions kept their data by default holds tasks: set TODOAPP_DB=\"" + LegacyDBConn + "\" to keep using it, or TODOAPP_DB=sqlite:<file> to start with an SQLite database")
+
+// legacyDBHasTasks reports whether the database behind LegacyDBConn has a
+// tasks table, that is whether an earlier version kept its data there. A
+// server that does not answer, or another database, counts as no.
+var legacyDBHasTasks = func() bool {
+	db, err := sql.Open("postgres", LegacyDBConn+" connect_timeout=1")
+	if err != nil {
+		return false
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var found bool
+	err = db.QueryRowContext(ctx, `select to_regclass('public.tasks') is not null`).Scan(&found)
+	return err == nil && found
+}
 
 type Config struct {
-	DBConn    string
-	JWTSecret string
+	DBConn     string
+	JWTSecret  string
+	DevMode    bool
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	HTTPAddr   string
+	// TrashRetention is how long deleted items stay in the trash before
+	// they are purged; 0 keeps them until the trash is emptied.
+	TrashRetention time.Duration
+	// ArchiveAfter is how long completed tasks stay in the task lists before
+	// they are archived; 0 only archives them when cleared.
+	ArchiveAfter time.Duration
+
+	// legacyDB is set when no connection string was configured, no SQLite
+	// file exists yet, but the old default PostgreSQL database has tasks.
+	// Only then is it looked for, so once the file is created the probe no
+	// longer runs.
+	legacyDB bool
+	// secretErr is why the embedded database's signing key could not be
+	// read or created.
+	secretErr error
 }
 
 func Load() Config {
 	c := Config{
-		DBConn:    os.Getenv("TODOAPP_DB"),
-		JWTSecret: os.Getenv("TODOAPP_JWT"),
+		DBConn:         os.Getenv("TODOAPP_DB"),
+		JWTSecret:      os.Getenv("TODOAPP_JWT"),
+		DevMode:        envBool("TODOAPP_DEV"),
+		AccessTTL:      envDuration("TODOAPP_ACCESS_TTL", 15*time.Minute),
+		RefreshTTL:     envDuration("TODOAPP_REFRESH_TTL", 30*24*time.Hour),
+		HTTPAddr:       strings.TrimSpace(os.Getenv("TODOAPP_HTTP_ADDR")),
+		TrashRetention: envDuration("TODOAPP_TRASH_RETENTION", 30*24*time.Hour),
+		ArchiveAfter:   envDuration("TODOAPP_ARCHIVE_AFTER", 30*24*time.Hour),
 	}
 	if c.DBConn == "" {
-		c.DBConn = "user=postgres password=postgres dbname=todoapp host=127.0.0.1 port=5432 sslmode=disable"
+		c.DBConn = os.Getenv("DATABASE_URL")
+	}
+	embedded := c.DBConn == ""
+	if embedded {
+		c.DBConn = defaultDBConn()
+		c.legacyDB = !sqliteExists(c.DBConn) && legacyDBHasTasks()
+	}
+	if c.JWTSecret == "" && embedded && c.HTTPAddr == "" && !c.legacyDB {
+		// Nobody but this user's processes sees the tokens, so a key kept
+		// with the data is enough and spares the desktop app any setup.
+		c.JWTSecret, c.secretErr = localSecret(filepath.Dir(strings.TrimPrefix(c.DBConn, "sqlite:")))
 	}
 	if c.JWTSecret == "" {
-		c.JWTSecret = "devsecret"
+		c.JWTSecret = defaultJWTSecret
 	}
 	return c
 }
+
+// defaultDBConn keeps the desktop app usable without a database server: data
+// lives in an SQLite file next to the other per-user settings.
+func defaultDBConn() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "sqlite:todo.db"
+	}
+	return "sqlite:" + filepath.Join(dir, "todo-app", "todo.db")
+}
+
+func sqliteExists(conn string) bool {
+	_, err := os.Stat(strings.TrimPrefix(conn, "sqlite:"))
+	return err == nil
+}
+
+// localSecret returns the signing key stored in dir, creating it with a
+// random value on first use.
+func localSecret(dir string) (string, error) {
+	path := filepath.Join(dir, secretFile)
+	key, err := readSecret(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return key, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create signing key: %w", err)
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("create signing key: %w", err)
+	}
+	key = hex.EncodeToString(b)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, fs.ErrExist) {
+		return readSecret(path) // created by another process since
+	}
+	if err != nil {
+		return "", fmt.Errorf("create signing key: %w", err)
+	}
+	_, err = f.WriteString(key + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("create signing key: %w", err)
+	}
+	return key, nil
+}
+
+func readSecret(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("read signing key: %w", err)
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("signing key %s is empty", path)
+	}
+	return key, nil
+}
+
+func (c Config) Validate() error {
+	if c.legacyDB {
+		return ErrLegacyDB
+	}
+	if c.secretErr != nil {
+		return c.secretErr
+	}
+	if c.JWTSecret == defaultJWTSecret && !c.DevMode {
+		return ErrDefaultSecret
+	}
+	if c.AccessTTL <= 0 || c.RefreshTTL <= 0 {
+		return errors.New("token lifetimes must be positive")
+	}
+	if c.TrashRetention < 0 {
+		return errors.New("trash retention must not be negative")
+	}
+	if c.ArchiveAfter < 0 {
+		return errors.New("archive delay must not be negative")
+	}
+	return nil
+}
+
+func envBool(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLoadLegacyDB(t *testing.T) {
+	t.Setenv("TODOAPP_DB", "")
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("TODOAPP_DEV", "1")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	hasTasks := true
+	defer func(old func() bool) { legacyDBHasTasks = old }(legacyDBHasTasks)
+	legacyDBHasTasks = func() bool { return hasTasks }
+
+	if err := Load().Validate(); !errors.Is(err, ErrLegacyDB) {
+		t.Fatalf("fresh install next to the old PostgreSQL: got %v, want ErrLegacyDB", err)
+	}
+
+	hasTasks = false
+	if err := Load().Validate(); err != nil {
+		t.Fatalf("fresh install without old tasks: %v", err)
+	}
+
+	hasTasks = true
+	c := Load()
+	path := filepath.Clean(c.DBConn[len("sqlite:"):])
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load().Validate(); err != nil {
+		t.Fatalf("existing SQLite file: %v", err)
+	}
+
+	t.Setenv("TODOAPP_DB", LegacyDBConn)
+	if c := Load(); c.DBConn != LegacyDBConn || c.Validate() != nil {
+		t.Fatalf("explicit DSN: got %q, %v", c.DBConn, c.Validate())
+	}
+}
+
+func TestLoadLocalSecret(t *testing.T) {
+	t.Setenv("TODOAPP_DB", "")
+	t.Setenv("DATABASE_URL", "")
+	t.Setenv("TODOAPP_JWT", "")
+	t.Setenv("TODOAPP_DEV", "")
+	t.Setenv("TODOAPP_HTTP_ADDR", "")
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	defer func(old func() bool) { legacyDBHasTasks = old }(legacyDBHasTasks)
+	legacyDBHasTasks = func() bool { return false }
+
+	c := Load()
+	if err := c.Validate(); err != nil || c.JWTSecret == defaultJWTSecret {
+		t.Fatalf("embedded database: secret %q, %v", c.JWTSecret, err)
+	}
+	path := filepath.Join(filepath.Dir(c.DBConn[len("sqlite:"):]), secretFile)
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0o600 {
+		t.Fatalf("key file mode %v, want 0600", fi.Mode().Perm())
+	}
+	if again := Load(); again.JWTSecret != c.JWTSecret {
+		t.Fatal("the key changed between runs")
+	}
+
+	t.Setenv("TODOAPP_HTTP_ADDR", ":8080")
+	if err := Load().Validate(); !errors.Is(err, ErrDefaultSecret) {
+		t.Fatalf("HTTP server without TODOAPP_JWT: got %v, want ErrDefaultSecret", err)
+	}
+	t.Setenv("TODOAPP_HTTP_ADDR", "")
+	t.Setenv("TODOAPP_DB", "sqlite:"+filepath.Join(dir, "other.db"))
+	if err := Load().Validate(); !errors.Is(err, ErrDefaultSecret) {
+		t.Fatalf("configured database without TODOAPP_JWT: got %v, want ErrDefaultSecret", err)
+	}
+}
@@ -0,0 +1,305 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"todo-app/backend/internal/markdown"
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+// Task is the wire shape of a task shared by the Wails bindings and the CLI.
+type Task struct {
+	ID          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// DescriptionHTML is Description rendered from Markdown and sanitized.
+	DescriptionHTML string   `json:"descriptionHtml,omitempty"`
+	Priority        string   `json:"priority"`
+	Completed       bool     `json:"completed"`
+	CreatedAt       string   `json:"createdAt"`
+	CompletedAt     *string  `json:"completedAt,omitempty"`
+	DueDate         *string  `json:"dueDate,omitempty"`
+	RepeatRule      *string  `json:"repeatRule,omitempty"`
+	CategoryID      *int64   `json:"categoryId,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	SeriesID        *int64   `json:"seriesId,omitempty"`
+	ArchivedAt      *string  `json:"archivedAt,omitempty"`
+	DeletedAt       *string  `json:"deletedAt,omitempty"`
+	Position        string   `json:"position,omitempty"`
+	Version         int64    `json:"version"`
+}
+
+// Occurrence is one entry of a repeating task's history.
+type Occurrence struct {
+	OccurrenceAt string  `json:"occurrenceAt"`
+	Status       string  `json:"status"`
+	TaskID       *int64  `json:"taskId,omitempty"`
+	Title        string  `json:"title,omitempty"`
+	DueDate      *string `json:"dueDate,omitempty"`
+	CompletedAt  *string `json:"completedAt,omitempty"`
+}
+
+// TaskPage is one page of a task listing with opaque cursors for the pages
+// around it.
+type TaskPage struct {
+	Items []Task `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// SearchResult is a ranked search hit. Title and Snippet are HTML with the
+// matched words in <mark>.
+type SearchResult struct {
+	Task    Task    `json:"task"`
+	Rank    float64 `json:"rank"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+// TaskPatch is the wire shape of models.UpdateTaskInput. Absent or null
+// fields are left alone; "" clears DueDate and RepeatRule, 0 clears
+// CategoryID and [] removes every tag. Version is the task's version as the
+// client last saw it; 0 skips the conflict check.
+type TaskPatch struct {
+	Title       *string  `json:"title,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Priority    *string  `json:"priority,omitempty"`
+	DueDate     *string  `json:"dueDate,omitempty"`
+	RepeatRule  *string  `json:"repeatRule,omitempty"`
+	CategoryID  *int64   `json:"categoryId,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Version     int64    `json:"version,omitempty"`
+}
+
+func (p TaskPatch) ToModel(id int64) (models.UpdateTaskInput, error) {
+	in := models.UpdateTaskInput{
+		ID:          id,
+		Title:       p.Title,
+		Description: p.Description,
+		Priority:    p.Priority,
+		RepeatRule:  p.RepeatRule,
+		Tags:        p.Tags,
+		Version:     p.Version,
+	}
+	if p.DueDate != nil {
+		due, err := ParseDate(*p.DueDate)
+		if err != nil {
+			return in, service.FieldErrors{"dueDate": err.Error()}
+		}
+		in.DueDate, in.ClearDueDate = due, due == nil
+	}
+	if p.CategoryID != nil {
+		if *p.CategoryID == 0 {
+			in.ClearCategory = true
+		} else {
+			in.CategoryID = p.CategoryID
+		}
+	}
+	return in, nil
+}
+
+// TaskFilter is the wire shape of models.TaskFilter; From and To take any
+// format ParseDate accepts.
+type TaskFilter struct {
+	Status               string   `json:"status"`
+	Priority             string   `json:"priority"`
+	DateFilter           string   `json:"dateFilter"`
+	Search               string   `json:"search"`
+	CategoryID           *int64   `json:"categoryId,omitempty"`
+	CategoryName         string   `json:"categoryName,omitempty"`
+	IncludeSubcategories bool     `json:"includeSubcategories,omitempty"`
+	From                 string   `json:"from,omitempty"`
+	To                   string   `json:"to,omitempty"`
+	Sort                 string   `json:"sort"`
+	Terms                []string `json:"terms,omitempty"`
+	ExcludeTerms         []string `json:"excludeTerms,omitempty"`
+	Tags                 []string `json:"tags,omitempty"`
+	ExcludeTags          []string `json:"excludeTags,omitempty"`
+}
+
+func FromFilter(f models.TaskFilter) TaskFilter {
+	res := TaskFilter{
+		Status:               f.Status,
+		Priority:             f.Priority,
+		DateFilter:           f.DateFilter,
+		Search:               f.Search,
+		CategoryID:           f.CategoryID,
+		CategoryName:         f.CategoryName,
+		IncludeSubcategories: f.IncludeSubcategories,
+		Sort:                 f.Sort,
+		Terms:                f.Terms,
+		ExcludeTerms:         f.ExcludeTerms,
+		Tags:                 f.Tags,
+		ExcludeTags:          f.ExcludeTags,
+	}
+	if v := timePtr(f.From); v != nil {
+		res.From = *v
+	}
+	if v := timePtr(f.To); v != nil {
+		res.To = *v
+	}
+	return res
+}
+
+func (f TaskFilter) ToModel() (models.TaskFilter, error) {
+	from, err := ParseDate(f.From)
+	if err != nil {
+		return models.TaskFilter{}, err
+	}
+	to, err := ParseDate(f.To)
+	if err != nil {
+		return models.TaskFilter{}, err
+	}
+	return models.TaskFilter{
+		Status:               f.Status,
+		Priority:             f.Priority,
+		DateFilter:           f.DateFilter,
+		Search:               f.Search,
+		CategoryID:           f.CategoryID,
+		CategoryName:         f.CategoryName,
+		IncludeSubcategories: f.IncludeSubcategories,
+		From:                 from,
+		To:                   to,
+		Sort:                 f.Sort,
+		Terms:                f.Terms,
+		ExcludeTerms:         f.ExcludeTerms,
+		Tags:                 f.Tags,
+		ExcludeTags:          f.ExcludeTags,
+	}, nil
+}
+
+// View is a saved or built-in view; built-in views have negative ids.
+type View struct {
+	ID       int64      `json:"id"`
+	Name     string     `json:"name"`
+	Filter   TaskFilter `json:"filter"`
+	Position int        `json:"position"`
+	BuiltIn  bool       `json:"builtIn"`
+}
+
+func FromView(v *models.SavedView) View {
+	return View{ID: v.ID, Name: v.Name, Filter: FromFilter(v.Filter), Position: v.Position, BuiltIn: v.BuiltIn}
+}
+
+type Stats struct {
+	Total      int64      `json:"total"`
+	Active     int64      `json:"active"`
+	Completed  int64      `json:"completed"`
+	Overdue    int64      `json:"overdue"`
+	Priorities []Priority `json:"priorities"`
+}
+
+// Priority is one level of the user's priority scale; Count is the number
+// of tasks outside the trash at it.
+type Priority struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Color   string `json:"color"`
+	Weight  int    `json:"weight"`
+	Default bool   `json:"default"`
+	Count   int    `json:"count"`
+	Version int64  `json:"version"`
+}
+
+var ErrInvalidDate = errors.New("invalid date format")
+
+func FromTask(t *models.Task) Task {
+	return Task{
+		ID:              t.ID,
+		Title:           t.Title,
+		Description:     t.Description,
+		DescriptionHTML: markdown.Render(t.Description),
+		Priority:        t.Priority,
+		Completed:       t.Completed,
+		CreatedAt:       t.CreatedAt.UTC().Format(time.RFC3339),
+		CompletedAt:     timePtr(t.CompletedAt),
+		DueDate:         timePtr(t.DueDate),
+		RepeatRule:      t.RepeatRule,
+		CategoryID:      t.CategoryID,
+		Tags:            t.Tags,
+		SeriesID:        t.SeriesID,
+		ArchivedAt:      timePtr(t.ArchivedAt),
+		DeletedAt:       timePtr(t.DeletedAt),
+		Position:        t.Position,
+		Version:         t.Version,
+	}
+}
+
+func FromOccurrence(o *models.SeriesOccurrence) Occurrence {
+	return Occurrence{
+		OccurrenceAt: o.OccurrenceAt.UTC().Format(time.RFC3339),
+		Status:       o.Status,
+		TaskID:       o.TaskID,
+		Title:        o.Title,
+		DueDate:      timePtr(o.DueDate),
+		CompletedAt:  timePtr(o.CompletedAt),
+	}
+}
+
+func FromTaskPage(p *models.TaskPage) TaskPage {
+	res := TaskPage{Items: make([]Task, len(p.Items)), Next: p.Next, Prev: p.Prev}
+	for i := range p.Items {
+		res.Items[i] = FromTask(&p.Items[i])
+	}
+	return res
+}
+
+func FromSearchResult(r *models.SearchResult) SearchResult {
+	return SearchResult{Task: FromTask(&r.Task), Rank: r.Rank, Title: r.Title, Snippet: r.Snippet}
+}
+
+func FromStats(s service.Stats) Stats {
+	return Stats{
+		Total:      int64(s.Total),
+		Active:     int64(s.Active),
+		Completed:  int64(s.Completed),
+		Overdue:    int64(s.Overdue),
+		Priorities: FromPriorities(s.Priorities),
+	}
+}
+
+func FromPriority(p models.Priority) Priority {
+	return Priority{ID: p.ID, Name: p.Name, Label: p.Label, Color: p.Color, Weight: p.Weight, Default: p.Default, Count: p.Count, Version: p.Version}
+}
+
+func FromPriorities(ps []models.Priority) []Priority {
+	res := []Priority{}
+	for _, p := range ps {
+		res = append(res, FromPriority(p))
+	}
+	return res
+}
+
+func timePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC().Format(time.RFC3339)
+	return &v
+}
+
+// ParseDate accepts RFC 3339, a datetime-local value ("2006-01-02T15:04") or
+// a bare date, the latter two in local time. Blank input means no date.
+func ParseDate(s string) (*time.Time, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse("2006-01-02T15:04", s); err == nil {
+		loc := time.Now().Location()
+		u := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+		return &u, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		loc := time.Now().Location()
+		u := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return &u, nil
+	}
+	return nil, ErrInvalidDate
+}
@@ -0,0 +1,71 @@
+// Package markdown renders task descriptions for display and extracts the
+// checklists written in them.
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Without html.WithUnsafe goldmark leaves raw HTML out of the output and
+// drops javascript:, vbscript:, file: and non-image data: links, so the
+// result can be put into the page as is.
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// Render converts GitHub-flavoured Markdown to safe HTML.
+func Render(src string) string {
+	if strings.TrimSpace(src) == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// Item is one "- [ ] text" or "- [x] text" line of a checklist.
+type Item struct {
+	Text string
+	Done bool
+}
+
+var (
+	itemLine  = regexp.MustCompile(`^\s{0,3}[-*+]\s+\[([ xX])\]\s+(.*\S)\s*$`)
+	fenceLine = regexp.MustCompile("^\\s{0,3}(```|~~~)")
+)
+
+// Checklist returns the checklist items in src, outside code blocks, and src
+// without their lines. Blank lines left around a removed list are collapsed.
+func Checklist(src string) (items []Item, rest string) {
+	var (
+		kept    []string
+		inFence string
+		dropped bool
+	)
+	for _, line := range strings.Split(src, "\n") {
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			if inFence == "" {
+				inFence = m[1]
+			} else if m[1] == inFence {
+				inFence = ""
+			}
+		} else if m := itemLine.FindStringSubmatch(line); m != nil && inFence == "" {
+			items = append(items, Item{Text: m[2], Done: m[1] != " "})
+			dropped = true
+			continue
+		} else if strings.TrimSpace(line) == "" && inFence == "" {
+			if dropped && len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+				continue
+			}
+		} else {
+			dropped = false
+		}
+		kept = append(kept, line)
+	}
+	return items, strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,35 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderIsSafe(t *testing.T) {
+	out := Render("**Buy** [milk](https://shop.example) <script>alert(1)</script>\n\n[x](javascript:alert(1))\n\n- [x] done")
+	for _, want := range []string{"<strong>Buy</strong>", `<a href="https://shop.example">milk</a>`, `<input checked="" disabled="" type="checkbox"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s in %s", want, out)
+		}
+	}
+	for _, bad := range []string{"<script", "javascript:"} {
+		if strings.Contains(out, bad) {
+			t.Errorf("unsafe %s in %s", bad, out)
+		}
+	}
+	if Render("  \n") != "" {
+		t.Error("blank description must render empty")
+	}
+}
+
+func TestChecklist(t *testing.T) {
+	items, rest := Checklist("Shopping:\n- [ ] milk\n* [X] bread \n- plain item\n```\n- [ ] code\n```\n  - [ ]   eggs")
+	want := []Item{{"milk", false}, {"bread", true}, {"eggs", false}}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("items = %v, want %v", items, want)
+	}
+	if rest != "Shopping:\n- plain item\n```\n- [ ] code\n```" {
+		t.Errorf("rest = %q", rest)
+	}
+}
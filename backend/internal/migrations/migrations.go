@@ -0,0 +1,137 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dialect selects the SQL flavour and, with it, the migration history. Each
+// dialect keeps its own numbered history under a directory of the same name.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+func Load(d Dialect) ([]Migration, error) {
+	switch d {
+	case Postgres, SQLite:
+	default:
+		return nil, fmt.Errorf("unknown migration dialect %q", d)
+	}
+	sub, err := fs.Sub(files, string(d))
+	if err != nil {
+		return nil, err
+	}
+	return load(sub)
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, file := range names {
+		version, name, direction, err := parseFileName(file)
+		if err != nil {
+			return nil, err
+		}
+		body, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("migration %03d has conflicting names %q and %q", version, m.Name, name)
+		}
+		sqlText := normalize(body)
+		switch direction {
+		case "up":
+			if m.Up != "" {
+				return nil, fmt.Errorf("migration %03d has more than one up file", version)
+			}
+			m.Up = sqlText
+		case "down":
+			if m.Down != "" {
+				return nil, fmt.Errorf("migration %03d has more than one down file", version)
+			}
+			m.Down = sqlText
+		}
+	}
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no up file", m.Version, m.Name)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no down file", m.Version, m.Name)
+		}
+		m.Checksum = checksum(m.Up)
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Version < res[j].Version })
+	for i, m := range res {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous from 1, got %03d at position %d", m.Version, i+1)
+		}
+	}
+	return res, nil
+}
+
+// parseFileName splits "002_users.up.sql" into (2, "users", "up").
+func parseFileName(file string) (int, string, string, error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+	var direction string
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("migration %s must end in .up.sql or .down.sql", file)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+	num, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("migration %s must be named NNN_name.%s.sql", file, direction)
+	}
+	version, err := strconv.Atoi(num)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %s has invalid version %q", file, num)
+	}
+	return version, name, direction, nil
+}
+
+// normalize strips CRLF so checksums match between Windows and Unix checkouts.
+func normalize(b []byte) string {
+	return strings.TrimSpace(strings.ReplaceAll(string(b), "\r\n", "\n")) + "\n"
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
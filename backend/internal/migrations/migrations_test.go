@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	for _, d := range []Dialect{Postgres, SQLite} {
+		ms, err := Load(d)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", d, err)
+		}
+		if len(ms) == 0 {
+			t.Fatalf("no %s migrations embedded", d)
+		}
+		for i, m := range ms {
+			if m.Version != i+1 {
+				t.Errorf("%s migration %d has version %d", d, i, m.Version)
+			}
+			if m.Up == "" || m.Down == "" || m.Checksum == "" {
+				t.Errorf("%s migration %03d_%s is incomplete", d, m.Version, m.Name)
+			}
+		}
+	}
+	if _, err := Load("oracle"); err == nil {
+		t.Fatal("unknown dialect must fail")
+	}
+}
+
+func TestLoadRejectsBadTrees(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"001_a.up.sql": {Data: []byte("select 1;")},
+		},
+		"gap": {
+			"001_a.up.sql":   {Data: []byte("select 1;")},
+			"001_a.down.sql": {Data: []byte("select 1;")},
+			"003_c.up.sql":   {Data: []byte("select 1;")},
+			"003_c.down.sql": {Data: []byte("select 1;")},
+		},
+		"bad name": {
+			"first.up.sql": {Data: []byte("select 1;")},
+		},
+		"conflicting names": {
+			"001_a.up.sql":   {Data: []byte("select 1;")},
+			"001_b.down.sql": {Data: []byte("select 1;")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestChecksumIgnoresLineEndings(t *testing.T) {
+	unix, err := load(fstest.MapFS{
+		"001_a.up.sql":   {Data: []byte("create table a (id int);\n")},
+		"001_a.down.sql": {Data: []byte("drop table a;\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	windows, err := load(fstest.MapFS{
+		"001_a.up.sql":   {Data: []byte("create table a (id int);\r\n")},
+		"001_a.down.sql": {Data: []byte("drop table a;\r\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unix[0].Checksum != windows[0].Checksum {
+		t.Fatal("checksum depends on line endings")
+	}
+}
+
+func TestPending(t *testing.T) {
+	known := []Migration{
+		{Version: 1, Name: "a", Checksum: "x1"},
+		{Version: 2, Name: "b", Checksum: "x2"},
+		{Version: 3, Name: "c", Checksum: "x3"},
+	}
+
+	todo, err := pending(known, []Applied{{Version: 1, Checksum: "x1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todo) != 2 || todo[0].Version != 2 {
+		t.Fatalf("unexpected pending: %+v", todo)
+	}
+
+	_, err = pending(known, []Applied{{Version: 1, Checksum: "x1"}, {Version: 2, Checksum: "x2"}, {Version: 3, Checksum: "x3"}, {Version: 4, Checksum: "x4"}})
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+
+	_, err = pending(known, []Applied{{Version: 1, Checksum: "changed"}})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+
+	_, err = pending(known, []Applied{{Version: 2, Checksum: "x2"}})
+	if !errors.Is(err, ErrHistoryGap) {
+		t.Fatalf("expected ErrHistoryGap, got %v", err)
+	}
+}
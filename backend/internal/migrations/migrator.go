@@ -0,0 +1,222 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSchemaTooNew     = errors.New("database schema is newer than this build")
+	ErrChecksumMismatch = errors.New("applied migration does not match its source")
+	ErrHistoryGap       = errors.New("migration history has a gap")
+)
+
+// lockID serializes concurrent migrators (two app instances, app and CLI) on the same database.
+const lockID int64 = 7_420_001
+
+type Applied struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB, d Dialect) (*Migrator, error) {
+	ms, err := Load(d)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: d, migrations: ms}, nil
+}
+
+// Run brings the database up to the latest version, refusing to touch a schema
+// written by a newer build.
+func Run(db *sql.DB, d Dialect) error {
+	m, err := NewMigrator(db, d)
+	if err != nil {
+		return err
+	}
+	_, err = m.Up()
+	return err
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+func (m *Migrator) ensureTable() error {
+	appliedAt := "timestamptz not null default now()"
+	if m.dialect == SQLite {
+		appliedAt = "timestamp not null default current_timestamp"
+	}
+	_, err := m.db.Exec(`
+create table if not exists schema_migrations (
+  version integer primary key,
+  name text not null,
+  checksum text not null,
+  applied_at ` + appliedAt + `
+)`)
+	return err
+}
+
+// lock serializes migrators inside tx. SQLite needs nothing extra: the
+// connection opens transactions with BEGIN IMMEDIATE, which already takes the
+// database write lock.
+func (m *Migrator) lock(tx *sql.Tx) error {
+	if m.dialect != Postgres {
+		return nil
+	}
+	_, err := tx.Exec(`select pg_advisory_xact_lock($1)`, lockID)
+	return err
+}
+
+func (m *Migrator) Applied() ([]Applied, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	rows, err := m.db.Query(`select version, name, checksum, applied_at from schema_migrations order by version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []Applied
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+// Version returns the highest applied migration, 0 for an empty database.
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := pending(m.migrations, applied); err != nil {
+		return 0, err
+	}
+	return len(applied), nil
+}
+
+// Check validates the applied history without changing anything.
+func (m *Migrator) Check() error {
+	_, err := m.Version()
+	return err
+}
+
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return 0, err
+	}
+	todo, err := pending(m.migrations, applied)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, mig := range todo {
+		ok, err := m.apply(mig)
+		if err != nil {
+			return n, fmt.Errorf("migration %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if ok {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *Migrator) Down(steps int) (int, error) {
+	applied, err := m.Applied()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := pending(m.migrations, applied); err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := len(applied) - 1; i >= 0 && n < steps; i-- {
+		mig := m.migrations[applied[i].Version-1]
+		if err := m.revert(mig); err != nil {
+			return n, fmt.Errorf("revert %03d_%s: %w", mig.Version, mig.Name, err)
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (m *Migrator) apply(mig Migration) (bool, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	if err := m.lock(tx); err != nil {
+		return false, err
+	}
+	var done bool
+	if err := tx.QueryRow(`select exists(select 1 from schema_migrations where version=$1)`, mig.Version).Scan(&done); err != nil {
+		return false, err
+	}
+	if done {
+		return false, nil
+	}
+	if _, err := tx.Exec(mig.Up); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`insert into schema_migrations (version, name, checksum) values ($1,$2,$3)`, mig.Version, mig.Name, mig.Checksum); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (m *Migrator) revert(mig Migration) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := m.lock(tx); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(mig.Down); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`delete from schema_migrations where version=$1`, mig.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// pending validates the applied history against the known migrations and
+// returns the ones still to run, in order.
+func pending(known []Migration, applied []Applied) ([]Migration, error) {
+	for i, a := range applied {
+		if a.Version > len(known) {
+			return nil, fmt.Errorf("%w: database is at version %d, this build knows up to %d", ErrSchemaTooNew, applied[len(applied)-1].Version, len(known))
+		}
+		if a.Version != i+1 {
+			return nil, fmt.Errorf("%w: expected version %d, found %d", ErrHistoryGap, i+1, a.Version)
+		}
+		if k := known[a.Version-1]; k.Checksum != a.Checksum {
+			return nil, fmt.Errorf("%w: %03d_%s", ErrChecksumMismatch, k.Version, k.Name)
+		}
+	}
+	return known[len(applied):], nil
+}
@@ -1,9 +1,3 @@
-package main
-
-import "database/sql"
-
-func ensureSchema(db *sql.DB) error {
-	_, err := db.Exec(`
 create table if not exists tasks (
   id bigserial primary key,
   user_id bigint not null,
@@ -24,6 +18,7 @@ create table if not exists categories (
   user_id bigint not null,
   name text not null
 );
+alter table categories add column if not exists created_at timestamptz not null default now();
 
 create table if not exists subtasks (
   id bigserial primary key,
@@ -38,6 +33,3 @@ create index if not exists idx_tasks_user on tasks(user_id);
 create index if not exists idx_tasks_due on tasks(user_id, due_at);
 create index if not exists idx_tasks_tags on tasks using gin (tags);
 create index if not exists idx_subtasks_task on subtasks(user_id, task_id);
-`)
-	return err
-}
@@ -0,0 +1,4 @@
+alter table subtasks drop constraint if exists subtasks_user_id_fkey;
+alter table categories drop constraint if exists categories_user_id_fkey;
+alter table tasks drop constraint if exists tasks_user_id_fkey;
+drop table if exists users;
@@ -0,0 +1,23 @@
+create table if not exists users (
+  id bigserial primary key,
+  email text not null unique,
+  password_hash text not null,
+  created_at timestamptz not null default now()
+);
+
+insert into users (id, email, password_hash)
+select o.user_id, 'user' || o.user_id || '@localhost', ''
+from (
+  select user_id from tasks
+  union
+  select user_id from categories
+  union
+  select user_id from subtasks
+) o
+where not exists (select 1 from users u where u.id = o.user_id);
+
+select setval(pg_get_serial_sequence('users', 'id'), coalesce(max(id), 0) + 1, false) from users;
+
+alter table tasks add constraint tasks_user_id_fkey foreign key (user_id) references users(id) on delete cascade;
+alter table categories add constraint categories_user_id_fkey foreign key (user_id) references users(id) on delete cascade;
+alter table subtasks add constraint subtasks_user_id_fkey foreign key (user_id) references users(id) on delete cascade;
@@ -0,0 +1,5 @@
+drop index if exists idx_tasks_overdue;
+drop index if exists idx_tasks_completed;
+
+alter table tasks alter column tags drop not null;
+alter table tasks alter column description drop not null;
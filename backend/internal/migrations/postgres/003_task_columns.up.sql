@@ -0,0 +1,8 @@
+update tasks set description = '' where description is null;
+alter table tasks alter column description set not null;
+
+update tasks set tags = '{}' where tags is null;
+alter table tasks alter column tags set not null;
+
+create index if not exists idx_tasks_completed on tasks(user_id, completed);
+create index if not exists idx_tasks_overdue on tasks(user_id, completed, due_at);
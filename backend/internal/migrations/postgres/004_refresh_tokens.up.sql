@@ -0,0 +1,11 @@
+create table if not exists refresh_tokens (
+  id bigserial primary key,
+  user_id bigint not null references users(id) on delete cascade,
+  token_hash text not null unique,
+  created_at timestamptz not null default now(),
+  expires_at timestamptz not null,
+  revoked_at timestamptz null,
+  replaced_by bigint null references refresh_tokens(id) on delete set null
+);
+
+create index if not exists idx_refresh_tokens_user on refresh_tokens(user_id);
@@ -0,0 +1,5 @@
+drop table if exists series_skips;
+drop index if exists idx_tasks_series;
+alter table tasks drop column if exists occurrence_at;
+alter table tasks drop column if exists series_id;
+drop table if exists task_series;
@@ -0,0 +1,28 @@
+create table if not exists task_series (
+  id bigserial primary key,
+  user_id bigint not null references users(id) on delete cascade,
+  title text not null,
+  description text not null default '',
+  priority text not null default 'medium',
+  repeat_rule text not null,
+  category_id bigint null,
+  tags text[] not null default '{}',
+  subtasks text[] not null default '{}',
+  created_at timestamptz not null default now(),
+  ended_at timestamptz null
+);
+
+create index if not exists idx_task_series_user on task_series(user_id);
+
+alter table tasks add column if not exists series_id bigint null references task_series(id) on delete set null;
+alter table tasks add column if not exists occurrence_at timestamptz null;
+
+create index if not exists idx_tasks_series on tasks(series_id);
+
+create table if not exists series_skips (
+  id bigserial primary key,
+  series_id bigint not null references task_series(id) on delete cascade,
+  occurrence_at timestamptz not null,
+  skipped_at timestamptz not null default now(),
+  unique (series_id, occurrence_at)
+);
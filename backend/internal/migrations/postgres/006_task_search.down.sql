@@ -0,0 +1,7 @@
+drop index if exists idx_tasks_search;
+drop trigger if exists subtasks_search_refresh on subtasks;
+drop function if exists subtasks_search_refresh();
+drop trigger if exists tasks_search_refresh on tasks;
+drop function if exists tasks_search_refresh();
+alter table tasks drop column if exists search;
+drop function if exists task_search_vector(bigint, text, text, text[]);
@@ -0,0 +1,41 @@
+-- The 'russian' configuration stems Cyrillic words with the Russian and
+-- ASCII words with the English snowball stemmer.
+create or replace function task_search_vector(bigint, text, text, text[]) returns tsvector
+language sql stable as $$
+  select setweight(to_tsvector('russian', coalesce($2, '')), 'A')
+      || setweight(to_tsvector('russian', coalesce($3, '')), 'B')
+      || setweight(to_tsvector('russian', array_to_string(coalesce($4, '{}'), ' ')), 'C')
+      || setweight(to_tsvector('russian', coalesce((select string_agg(s.title, ' ') from subtasks s where s.task_id = $1), '')), 'D')
+$$;
+
+alter table tasks add column if not exists search tsvector;
+
+create or replace function tasks_search_refresh() returns trigger
+language plpgsql as $$
+begin
+  new.search := task_search_vector(new.id, new.title, new.description, new.tags);
+  return new;
+end
+$$;
+
+drop trigger if exists tasks_search_refresh on tasks;
+create trigger tasks_search_refresh before insert or update of title, description, tags on tasks
+  for each row execute function tasks_search_refresh();
+
+create or replace function subtasks_search_refresh() returns trigger
+language plpgsql as $$
+begin
+  -- new is null on delete and old on insert.
+  update tasks t set search = task_search_vector(t.id, t.title, t.description, t.tags)
+  where t.id in (new.task_id, old.task_id);
+  return null;
+end
+$$;
+
+drop trigger if exists subtasks_search_refresh on subtasks;
+create trigger subtasks_search_refresh after insert or update of title, task_id or delete on subtasks
+  for each row execute function subtasks_search_refresh();
+
+update tasks set search = task_search_vector(id, title, description, tags);
+
+create index if not exists idx_tasks_search on tasks using gin(search);
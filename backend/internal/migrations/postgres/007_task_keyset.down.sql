@@ -0,0 +1 @@
+drop index if exists idx_tasks_keyset;
@@ -0,0 +1,3 @@
+-- Serves the keyset-paginated listing: where user_id = $1 and
+-- (created_at, id) < ($2, $3) order by created_at desc, id desc.
+create index if not exists idx_tasks_keyset on tasks(user_id, created_at desc, id desc);
@@ -0,0 +1,11 @@
+-- filter holds a models.TaskFilter as JSON; the built-in views are not stored.
+create table if not exists saved_views (
+  id bigserial primary key,
+  user_id bigint not null references users(id) on delete cascade,
+  name text not null,
+  filter jsonb not null default '{}',
+  position integer not null default 0,
+  created_at timestamptz not null default now()
+);
+
+create index if not exists idx_saved_views_user on saved_views(user_id, position);
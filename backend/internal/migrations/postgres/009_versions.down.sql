@@ -0,0 +1,3 @@
+alter table categories drop column if exists version;
+alter table subtasks drop column if exists version;
+alter table tasks drop column if exists version;
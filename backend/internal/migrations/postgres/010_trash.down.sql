@@ -0,0 +1,21 @@
+create or replace function task_search_vector(bigint, text, text, text[]) returns tsvector
+language sql stable as $$
+  select setweight(to_tsvector('russian', coalesce($2, '')), 'A')
+      || setweight(to_tsvector('russian', coalesce($3, '')), 'B')
+      || setweight(to_tsvector('russian', array_to_string(coalesce($4, '{}'), ' ')), 'C')
+      || setweight(to_tsvector('russian', coalesce((select string_agg(s.title, ' ') from subtasks s where s.task_id = $1), '')), 'D')
+$$;
+
+drop trigger if exists subtasks_search_refresh on subtasks;
+create trigger subtasks_search_refresh after insert or update of title, task_id or delete on subtasks
+  for each row execute function subtasks_search_refresh();
+
+delete from categories where deleted_at is not null;
+delete from subtasks where deleted_at is not null;
+delete from tasks where deleted_at is not null;
+drop index if exists idx_categories_deleted;
+drop index if exists idx_subtasks_deleted;
+drop index if exists idx_tasks_deleted;
+alter table categories drop column if exists deleted_at;
+alter table subtasks drop column if exists deleted_at;
+alter table tasks drop column if exists deleted_at;
@@ -0,0 +1,22 @@
+-- Deleting moves a row to the trash by setting deleted_at; trashed rows are
+-- purged for good once they are older than the retention period.
+alter table tasks add column if not exists deleted_at timestamptz null;
+alter table subtasks add column if not exists deleted_at timestamptz null;
+alter table categories add column if not exists deleted_at timestamptz null;
+
+create index if not exists idx_tasks_deleted on tasks(deleted_at) where deleted_at is not null;
+create index if not exists idx_subtasks_deleted on subtasks(deleted_at) where deleted_at is not null;
+create index if not exists idx_categories_deleted on categories(deleted_at) where deleted_at is not null;
+
+-- Trashed subtasks no longer count towards their task's search vector.
+create or replace function task_search_vector(bigint, text, text, text[]) returns tsvector
+language sql stable as $$
+  select setweight(to_tsvector('russian', coalesce($2, '')), 'A')
+      || setweight(to_tsvector('russian', coalesce($3, '')), 'B')
+      || setweight(to_tsvector('russian', array_to_string(coalesce($4, '{}'), ' ')), 'C')
+      || setweight(to_tsvector('russian', coalesce((select string_agg(s.title, ' ') from subtasks s where s.task_id = $1 and s.deleted_at is null), '')), 'D')
+$$;
+
+drop trigger if exists subtasks_search_refresh on subtasks;
+create trigger subtasks_search_refresh after insert or update of title, task_id, deleted_at or delete on subtasks
+  for each row execute function subtasks_search_refresh();
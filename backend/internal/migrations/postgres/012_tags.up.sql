@@ -0,0 +1,38 @@
+-- Tags get a table of their own: names are unique per user ignoring case
+-- and carry a color. Tasks and series keep the names in their tags arrays,
+-- which are rewritten when a tag is renamed, merged or deleted.
+create table if not exists tags (
+  id bigserial primary key,
+  user_id bigint not null references users(id) on delete cascade,
+  name text not null,
+  color text not null default '',
+  created_at timestamptz not null default now(),
+  version bigint not null default 1
+);
+
+create unique index if not exists idx_tags_user_name on tags(user_id, lower(name));
+
+-- Register the tags already in use, keeping the first spelling in sort
+-- order, and respell the arrays to match.
+insert into tags (user_id, name)
+select distinct on (user_id, lower(name)) user_id, name
+from (
+  select user_id, unnest(tags) as name from tasks
+  union
+  select user_id, unnest(tags) from task_series
+) used
+where name <> ''
+order by user_id, lower(name), name
+on conflict do nothing;
+
+update tasks t set tags = array(
+  select g.name from unnest(t.tags) with ordinality u(name, ord)
+  join tags g on g.user_id = t.user_id and lower(g.name) = lower(u.name)
+  group by g.name order by min(u.ord))
+where t.tags <> '{}';
+
+update task_series s set tags = array(
+  select g.name from unnest(s.tags) with ordinality u(name, ord)
+  join tags g on g.user_id = s.user_id and lower(g.name) = lower(u.name)
+  group by g.name order by min(u.ord))
+where s.tags <> '{}';
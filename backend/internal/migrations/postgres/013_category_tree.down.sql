@@ -0,0 +1,5 @@
+drop index if exists idx_categories_parent;
+alter table categories drop column if exists icon;
+alter table categories drop column if exists color;
+alter table categories drop column if exists position;
+alter table categories drop column if exists parent_id;
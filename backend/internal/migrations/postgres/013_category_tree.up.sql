@@ -0,0 +1,13 @@
+-- Categories nest: parent_id points at the enclosing category and position
+-- orders the children of a parent. Existing categories become top-level,
+-- in their old alphabetical order.
+alter table categories add column if not exists parent_id bigint null references categories(id) on delete set null;
+alter table categories add column if not exists position integer not null default 0;
+alter table categories add column if not exists color text not null default '';
+alter table categories add column if not exists icon text not null default '';
+
+update categories c set position = o.n
+from (select id, row_number() over (partition by user_id order by name, id) - 1 as n from categories) o
+where o.id = c.id;
+
+create index if not exists idx_categories_parent on categories(user_id, parent_id, position);
@@ -0,0 +1,3 @@
+drop index if exists idx_tasks_category;
+alter table task_series drop constraint if exists task_series_category_id_fkey;
+alter table tasks drop constraint if exists tasks_category_id_fkey;
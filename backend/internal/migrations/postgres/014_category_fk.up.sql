@@ -0,0 +1,13 @@
+-- Tasks and series may only point at existing categories. Trashed
+-- categories still exist; purging one clears the reference.
+update tasks set category_id = null
+where category_id is not null and category_id not in (select id from categories);
+update task_series set category_id = null
+where category_id is not null and category_id not in (select id from categories);
+
+alter table tasks add constraint tasks_category_id_fkey
+  foreign key (category_id) references categories(id) on delete set null;
+alter table task_series add constraint task_series_category_id_fkey
+  foreign key (category_id) references categories(id) on delete set null;
+
+create index if not exists idx_tasks_category on tasks(category_id);
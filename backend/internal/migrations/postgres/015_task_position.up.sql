@@ -0,0 +1,6 @@
+-- position is the task's key in the manual order, compared byte by byte
+-- (see package position). Existing tasks keep the empty key, which sorts
+-- first and falls back to newest first until they are moved.
+alter table tasks add column if not exists position text collate "C" not null default '';
+
+create index if not exists idx_tasks_position on tasks(user_id, position);
@@ -0,0 +1,3 @@
+drop trigger if exists users_default_priorities on users;
+drop function if exists add_default_priorities();
+drop table if exists priorities;
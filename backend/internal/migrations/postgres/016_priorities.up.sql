@@ -0,0 +1,42 @@
+-- Priorities become a per-user scale. Tasks and series keep the level's
+-- name in their priority column; a higher weight sorts first, and the
+-- default level is given to tasks created without a priority. Every user
+-- starts with the former fixed levels.
+create table if not exists priorities (
+  id bigserial primary key,
+  user_id bigint not null references users(id) on delete cascade,
+  name text not null,
+  label text not null default '',
+  color text not null default '',
+  weight integer not null default 0,
+  is_default boolean not null default false,
+  version bigint not null default 1
+);
+
+create unique index if not exists idx_priorities_user_name on priorities(user_id, name);
+create unique index if not exists idx_priorities_user_default on priorities(user_id) where is_default;
+
+create or replace function add_default_priorities() returns trigger as $$
+begin
+  insert into priorities (user_id, name, label, color, weight, is_default) values
+    (new.id, 'high', 'High', '#e5484d', 300, false),
+    (new.id, 'medium', 'Medium', '#f5a524', 200, true),
+    (new.id, 'low', 'Low', '#30a46c', 100, false);
+  return new;
+end
+$$ language plpgsql;
+
+drop trigger if exists users_default_priorities on users;
+create trigger users_default_priorities after insert on users
+  for each row execute function add_default_priorities();
+
+insert into priorities (user_id, name, label, color, weight, is_default)
+select u.id, p.name, p.label, p.color, p.weight, p.is_default
+from users u cross join (values
+  ('high', 'High', '#e5484d', 300, false),
+  ('medium', 'Medium', '#f5a524', 200, true),
+  ('low', 'Low', '#30a46c', 100, false)) p(name, label, color, weight, is_default)
+on conflict do nothing;
+
+update tasks set priority = 'medium' where priority not in ('high', 'medium', 'low');
+update task_series set priority = 'medium' where priority not in ('high', 'medium', 'low');
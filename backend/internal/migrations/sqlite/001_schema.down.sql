@@ -0,0 +1,5 @@
+drop table if exists refresh_tokens;
+drop table if exists subtasks;
+drop table if exists tasks;
+drop table if exists categories;
+drop table if exists users;
@@ -0,0 +1,56 @@
+create table if not exists users (
+  id integer primary key autoincrement,
+  email text not null unique,
+  password_hash text not null,
+  created_at timestamp not null default current_timestamp
+);
+
+create table if not exists categories (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  name text not null,
+  created_at timestamp not null default current_timestamp
+);
+
+create table if not exists tasks (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  title text not null,
+  description text not null default '',
+  priority text not null default 'medium',
+  completed boolean not null default 0,
+  created_at timestamp not null default current_timestamp,
+  due_at timestamp null,
+  completed_at timestamp null,
+  repeat_rule text null,
+  category_id integer null,
+  tags text not null default '[]'
+);
+
+create index if not exists idx_tasks_user on tasks(user_id);
+create index if not exists idx_tasks_due on tasks(user_id, due_at);
+create index if not exists idx_tasks_completed on tasks(user_id, completed);
+create index if not exists idx_tasks_overdue on tasks(user_id, completed, due_at);
+
+create table if not exists subtasks (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  task_id integer not null references tasks(id) on delete cascade,
+  title text not null,
+  completed boolean not null default 0,
+  created_at timestamp not null default current_timestamp
+);
+
+create index if not exists idx_subtasks_task on subtasks(user_id, task_id);
+
+create table if not exists refresh_tokens (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  token_hash text not null unique,
+  created_at timestamp not null default current_timestamp,
+  expires_at timestamp not null,
+  revoked_at timestamp null,
+  replaced_by integer null references refresh_tokens(id) on delete set null
+);
+
+create index if not exists idx_refresh_tokens_user on refresh_tokens(user_id);
@@ -0,0 +1,30 @@
+create table if not exists task_series (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  title text not null,
+  description text not null default '',
+  priority text not null default 'medium',
+  repeat_rule text not null,
+  category_id integer null,
+  tags text not null default '[]',
+  subtasks text not null default '[]',
+  created_at timestamp not null default current_timestamp,
+  ended_at timestamp null
+);
+
+create index if not exists idx_task_series_user on task_series(user_id);
+
+-- No foreign key: SQLite cannot drop a referencing column on the way down.
+-- Series are only ended, never deleted, except together with their user.
+alter table tasks add column series_id integer null;
+alter table tasks add column occurrence_at timestamp null;
+
+create index if not exists idx_tasks_series on tasks(series_id);
+
+create table if not exists series_skips (
+  id integer primary key autoincrement,
+  series_id integer not null references task_series(id) on delete cascade,
+  occurrence_at timestamp not null,
+  skipped_at timestamp not null default current_timestamp,
+  unique (series_id, occurrence_at)
+);
@@ -0,0 +1,11 @@
+-- filter holds a models.TaskFilter as JSON; the built-in views are not stored.
+create table if not exists saved_views (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  name text not null,
+  filter text not null default '{}',
+  position integer not null default 0,
+  created_at timestamp not null default current_timestamp
+);
+
+create index if not exists idx_saved_views_user on saved_views(user_id, position);
@@ -0,0 +1,3 @@
+alter table categories drop column version;
+alter table subtasks drop column version;
+alter table tasks drop column version;
@@ -0,0 +1,5 @@
+-- Every write bumps version; an update that names an older one is rejected
+-- as a conflict instead of overwriting a concurrent edit.
+alter table tasks add column version integer not null default 1;
+alter table subtasks add column version integer not null default 1;
+alter table categories add column version integer not null default 1;
@@ -0,0 +1,9 @@
+delete from categories where deleted_at is not null;
+delete from subtasks where deleted_at is not null;
+delete from tasks where deleted_at is not null;
+drop index if exists idx_categories_deleted;
+drop index if exists idx_subtasks_deleted;
+drop index if exists idx_tasks_deleted;
+alter table categories drop column deleted_at;
+alter table subtasks drop column deleted_at;
+alter table tasks drop column deleted_at;
@@ -0,0 +1,9 @@
+-- Deleting moves a row to the trash by setting deleted_at; trashed rows are
+-- purged for good once they are older than the retention period.
+alter table tasks add column deleted_at timestamp null;
+alter table subtasks add column deleted_at timestamp null;
+alter table categories add column deleted_at timestamp null;
+
+create index if not exists idx_tasks_deleted on tasks(deleted_at) where deleted_at is not null;
+create index if not exists idx_subtasks_deleted on subtasks(deleted_at) where deleted_at is not null;
+create index if not exists idx_categories_deleted on categories(deleted_at) where deleted_at is not null;
@@ -0,0 +1,2 @@
+drop index if exists idx_tasks_archivable;
+alter table tasks drop column archived_at;
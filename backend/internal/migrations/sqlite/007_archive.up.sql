@@ -0,0 +1,6 @@
+-- Clearing completed tasks archives them: they leave the task lists but
+-- still count in the stats. Tasks completed long enough ago are archived
+-- automatically.
+alter table tasks add column archived_at timestamp null;
+
+create index if not exists idx_tasks_archivable on tasks(completed_at) where completed and archived_at is null;
@@ -0,0 +1,2 @@
+drop index if exists idx_tags_user_name;
+drop table if exists tags;
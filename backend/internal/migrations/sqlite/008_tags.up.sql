@@ -0,0 +1,40 @@
+-- Tags get a table of their own: names are unique per user ignoring case
+-- and carry a color. Tasks and series keep the names in their tags arrays,
+-- which are rewritten when a tag is renamed, merged or deleted. casefold is
+-- the Unicode-aware lower() that the repository package registers.
+create table if not exists tags (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  name text not null,
+  color text not null default '',
+  created_at timestamp not null default current_timestamp,
+  version integer not null default 1
+);
+
+create unique index if not exists idx_tags_user_name on tags(user_id, casefold(name));
+
+-- Register the tags already in use, keeping the first spelling in sort
+-- order, and respell the arrays to match.
+insert or ignore into tags (user_id, name)
+select user_id, min(name)
+from (
+  select tasks.user_id, t.value as name from tasks, json_each(tasks.tags) t
+  union
+  select task_series.user_id, t.value from task_series, json_each(task_series.tags) t
+)
+where name <> ''
+group by user_id, casefold(name);
+
+update tasks set tags = (
+  select json_group_array(name) from (
+    select g.name from json_each(tasks.tags) u
+    join tags g on g.user_id = tasks.user_id and casefold(g.name) = casefold(u.value)
+    group by g.id order by min(u.key)))
+where tags <> '[]';
+
+update task_series set tags = (
+  select json_group_array(name) from (
+    select g.name from json_each(task_series.tags) u
+    join tags g on g.user_id = task_series.user_id and casefold(g.name) = casefold(u.value)
+    group by g.id order by min(u.key)))
+where tags <> '[]';
@@ -0,0 +1,5 @@
+drop index if exists idx_categories_parent;
+alter table categories drop column icon;
+alter table categories drop column color;
+alter table categories drop column position;
+alter table categories drop column parent_id;
@@ -0,0 +1,17 @@
+-- Categories nest: parent_id points at the enclosing category and position
+-- orders the children of a parent. Existing categories become top-level,
+-- in their old alphabetical order. parent_id has no foreign key, which
+-- SQLite could not drop again; the repository keeps it pointing at a live
+-- category.
+alter table categories add column parent_id integer null;
+alter table categories add column position integer not null default 0;
+alter table categories add column color text not null default '';
+alter table categories add column icon text not null default '';
+
+update categories set position = (
+  select count(*) from categories o
+  where o.user_id = categories.user_id
+    and (o.name < categories.name or (o.name = categories.name and o.id < categories.id))
+);
+
+create index if not exists idx_categories_parent on categories(user_id, parent_id, position);
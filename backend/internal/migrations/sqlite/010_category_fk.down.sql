@@ -0,0 +1,6 @@
+drop index if exists idx_tasks_category;
+drop trigger if exists categories_purge;
+drop trigger if exists task_series_category_update;
+drop trigger if exists task_series_category_insert;
+drop trigger if exists tasks_category_update;
+drop trigger if exists tasks_category_insert;
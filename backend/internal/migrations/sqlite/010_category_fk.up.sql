@@ -0,0 +1,39 @@
+-- Tasks and series may only point at existing categories. Trashed
+-- categories still exist; purging one clears the reference. SQLite cannot
+-- add a foreign key to an existing table, so triggers stand in for one.
+update tasks set category_id = null
+where category_id is not null and category_id not in (select id from categories);
+update task_series set category_id = null
+where category_id is not null and category_id not in (select id from categories);
+
+create trigger if not exists tasks_category_insert before insert on tasks
+when new.category_id is not null and not exists (select 1 from categories where id = new.category_id)
+begin
+  select raise(abort, 'FOREIGN KEY constraint failed');
+end;
+
+create trigger if not exists tasks_category_update before update of category_id on tasks
+when new.category_id is not null and not exists (select 1 from categories where id = new.category_id)
+begin
+  select raise(abort, 'FOREIGN KEY constraint failed');
+end;
+
+create trigger if not exists task_series_category_insert before insert on task_series
+when new.category_id is not null and not exists (select 1 from categories where id = new.category_id)
+begin
+  select raise(abort, 'FOREIGN KEY constraint failed');
+end;
+
+create trigger if not exists task_series_category_update before update of category_id on task_series
+when new.category_id is not null and not exists (select 1 from categories where id = new.category_id)
+begin
+  select raise(abort, 'FOREIGN KEY constraint failed');
+end;
+
+create trigger if not exists categories_purge after delete on categories
+begin
+  update tasks set category_id = null where category_id = old.id;
+  update task_series set category_id = null where category_id = old.id;
+end;
+
+create index if not exists idx_tasks_category on tasks(category_id);
@@ -0,0 +1,2 @@
+drop index if exists idx_tasks_position;
+alter table tasks drop column position;
@@ -0,0 +1,2 @@
+drop trigger if exists users_default_priorities;
+drop table if exists priorities;
@@ -0,0 +1,35 @@
+-- Priorities become a per-user scale. Tasks and series keep the level's
+-- name in their priority column; a higher weight sorts first, and the
+-- default level is given to tasks created without a priority. Every user
+-- starts with the former fixed levels.
+create table if not exists priorities (
+  id integer primary key autoincrement,
+  user_id integer not null references users(id) on delete cascade,
+  name text not null,
+  label text not null default '',
+  color text not null default '',
+  weight integer not null default 0,
+  is_default boolean not null default 0,
+  version integer not null default 1
+);
+
+create unique index if not exists idx_priorities_user_name on priorities(user_id, name);
+create unique index if not exists idx_priorities_user_default on priorities(user_id) where is_default;
+
+create trigger if not exists users_default_priorities after insert on users
+begin
+  insert into priorities (user_id, name, label, color, weight, is_default) values
+    (new.id, 'high', 'High', '#e5484d', 300, 0),
+    (new.id, 'medium', 'Medium', '#f5a524', 200, 1),
+    (new.id, 'low', 'Low', '#30a46c', 100, 0);
+end;
+
+insert or ignore into priorities (user_id, name, label, color, weight, is_default)
+select u.id, p.name, p.label, p.color, p.weight, p.is_default
+from users u cross join (
+  select 'high' as name, 'High' as label, '#e5484d' as color, 300 as weight, 0 as is_default
+  union all select 'medium', 'Medium', '#f5a524', 200, 1
+  union all select 'low', 'Low', '#30a46c', 100, 0) p;
+
+update tasks set priority = 'medium' where priority not in ('high', 'medium', 'low');
+update task_series set priority = 'medium' where priority not in ('high', 'medium', 'low');
@@ -0,0 +1 @@
+alter table refresh_tokens drop column session_id;
@@ -0,0 +1,4 @@
+-- session_id is the id of the token issued at login; rotated tokens carry
+-- it forward so that one sign-in can be told apart from another. Tokens
+-- issued before this column leave it null and start their own session.
+alter table refresh_tokens add column session_id integer null;
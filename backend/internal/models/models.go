@@ -12,14 +12,101 @@ type User struct {
 type UserPublic struct {
 	ID    int64  `json:"id"`
 	Email string `json:"email"`
+	// SessionID is the sign-in a verified access token belongs to. It is
+	// only set by Verify.
+	SessionID int64 `json:"-"`
 }
 
+type Session struct {
+	User             UserPublic `json:"user"`
+	AccessToken      string     `json:"accessToken"`
+	AccessExpiresAt  time.Time  `json:"accessExpiresAt"`
+	RefreshToken     string     `json:"refreshToken"`
+	RefreshExpiresAt time.Time  `json:"refreshExpiresAt"`
+}
+
+type RefreshToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"-"`
+	TokenHash  string     `json:"-"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	ReplacedBy *int64     `json:"replacedBy,omitempty"`
+	// SessionID is the ID of the token the login issued, shared by every
+	// token rotated from it.
+	SessionID int64 `json:"sessionId"`
+}
+
+// Tag is one of a user's tags. Names are unique per user ignoring case;
+// tasks refer to their tags by name.
+type Tag struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	// Color is empty or a "#rrggbb" hex color.
+	Color string `json:"color"`
+	// Count is the number of tasks outside the trash that carry the tag.
+	Count   int   `json:"count"`
+	Version int64 `json:"version"`
+}
+
+// Priority is one level of a user's priority scale. Names are unique per
+// user; tasks refer to their level by name, and a higher Weight sorts first.
+// The Default level is given to tasks created without a priority.
+type Priority struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	Label  string `json:"label"`
+	// Color is empty or a "#rrggbb" hex color.
+	Color   string `json:"color"`
+	Weight  int    `json:"weight"`
+	Default bool   `json:"default"`
+	// Count is the number of tasks outside the trash at this level.
+	Count   int   `json:"count"`
+	Version int64 `json:"version"`
+}
+
+// DefaultPriorities is the scale every user starts with.
+var DefaultPriorities = []Priority{
+	{Name: "high", Label: "High", Color: "#e5484d", Weight: 300},
+	{Name: "medium", Label: "Medium", Color: "#f5a524", Weight: 200, Default: true},
+	{Name: "low", Label: "Low", Color: "#30a46c", Weight: 100},
+}
+
+// Category is a node of the user's category tree. Names are unique among
+// the children of a parent, ignoring case.
 type Category struct {
 	ID     int64  `json:"id"`
 	UserID int64  `json:"-"`
 	Name   string `json:"name"`
+	// ParentID is nil for a top-level category.
+	ParentID *int64 `json:"parentId,omitempty"`
+	// Position orders the children of a parent, from 0.
+	Position int `json:"position"`
+	// Color is empty or a "#rrggbb" hex color; Icon is free text, such as
+	// an emoji or an icon name.
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+	// TaskCount is the number of tasks in the category outside the trash
+	// and the archive. TotalCount adds those of its subcategories and is
+	// only set by CategoryService.List.
+	TaskCount  int   `json:"taskCount"`
+	TotalCount int   `json:"totalCount"`
+	Version    int64 `json:"version"`
+	// DeletedAt is set on categories listed in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
+// What DeleteCategory does with the tasks in the category: move them to
+// another category, leave them without one, or move them to the trash.
+const (
+	CategoryMoveTasks   = "move"
+	CategoryClearTasks  = "clear"
+	CategoryDeleteTasks = "delete"
+)
+
 type Task struct {
 	ID           int64      `json:"id"`
 	UserID       int64      `json:"-"`
@@ -34,47 +121,189 @@ type Task struct {
 	CategoryID   *int64     `json:"categoryId,omitempty"`
 	CategoryName *string    `json:"categoryName,omitempty"`
 	Tags         []string   `json:"tags,omitempty"`
+	SeriesID     *int64     `json:"seriesId,omitempty"`
+	OccurrenceAt *time.Time `json:"occurrenceAt,omitempty"`
+	// Subtasks are written together with the task on Create; reads leave
+	// them empty and subtasks are listed separately.
+	Subtasks []Subtask `json:"subtasks,omitempty"`
+	// Version grows with every write. Update only succeeds while it still
+	// matches the stored one; 0 skips the check.
+	Version int64 `json:"version"`
+	// ArchivedAt is set once the completed task was cleared or auto-archived;
+	// archived tasks only show up when asked for by the "archived" status.
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+	// DeletedAt is set on tasks listed in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Position is the task's key in the manual order, see package position.
+	// New tasks go first and the next occurrence of a repeating task takes
+	// the completed one's place; Update leaves it alone.
+	Position string `json:"position"`
 }
 
-type CreateTaskInput struct {
+// TaskSeries is the template of a repeating task. Each occurrence is an
+// ordinary task that references the series; completing or skipping the open
+// occurrence generates the next one from the template.
+type TaskSeries struct {
+	ID          int64      `json:"id"`
 	UserID      int64      `json:"-"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Priority    string     `json:"priority"`
-	DueDate     *time.Time `json:"dueDate,omitempty"`
-	RepeatRule  *string    `json:"repeatRule,omitempty"`
+	RepeatRule  string     `json:"repeatRule"`
 	CategoryID  *int64     `json:"categoryId,omitempty"`
-	Tags        []string   `json:"tags,omitempty"`
+	Tags        []string   `json:"tags"`
+	Subtasks    []string   `json:"subtasks"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	EndedAt     *time.Time `json:"endedAt,omitempty"`
 }
 
-type UpdateTaskInput struct {
+const (
+	OccurrenceOpen      = "open"
+	OccurrenceCompleted = "completed"
+	OccurrenceSkipped   = "skipped"
+)
+
+// SeriesOccurrence is one entry of a series' history. Skipped occurrences
+// have no task.
+type SeriesOccurrence struct {
+	OccurrenceAt time.Time  `json:"occurrenceAt"`
+	Status       string     `json:"status"`
+	TaskID       *int64     `json:"taskId,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	DueDate      *time.Time `json:"dueDate,omitempty"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+}
+
+type Subtask struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"-"`
+	TaskID    int64     `json:"taskId"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	CreatedAt time.Time `json:"createdAt"`
+	Version   int64     `json:"version"`
+	// DeletedAt is set on subtasks listed in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Trash holds what a user deleted and can still restore, most recently
+// deleted first. A trashed task's subtasks go and come back with it and are
+// not listed on their own.
+type Trash struct {
+	Tasks      []Task     `json:"tasks"`
+	Subtasks   []Subtask  `json:"subtasks"`
+	Categories []Category `json:"categories"`
+}
+
+type CreateTaskInput struct {
 	UserID      int64      `json:"-"`
-	ID          int64      `json:"id"`
-	Title       *string    `json:"title,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	Priority    *string    `json:"priority,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
 	DueDate     *time.Time `json:"dueDate,omitempty"`
 	RepeatRule  *string    `json:"repeatRule,omitempty"`
 	CategoryID  *int64     `json:"categoryId,omitempty"`
 	Tags        []string   `json:"tags,omitempty"`
 }
 
+// UpdateTaskInput changes only the fields that are set: a nil pointer or nil
+// Tags leaves the field as it is. ClearDueDate and ClearCategory remove the
+// value, a blank RepeatRule stops the task repeating and empty non-nil Tags
+// remove every tag.
+type UpdateTaskInput struct {
+	UserID        int64      `json:"-"`
+	ID            int64      `json:"id"`
+	Title         *string    `json:"title,omitempty"`
+	Description   *string    `json:"description,omitempty"`
+	Priority      *string    `json:"priority,omitempty"`
+	DueDate       *time.Time `json:"dueDate,omitempty"`
+	ClearDueDate  bool       `json:"clearDueDate,omitempty"`
+	RepeatRule    *string    `json:"repeatRule,omitempty"`
+	CategoryID    *int64     `json:"categoryId,omitempty"`
+	ClearCategory bool       `json:"clearCategory,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	// Version, when set, must match the stored task's.
+	Version int64 `json:"version,omitempty"`
+}
+
+// TaskFilter selects and orders a user's tasks. Every set field narrows the
+// result. Status is "active", "completed" or "archived"; archived tasks are
+// left out under any other status. DateFilter is "overdue",
+// "today", "week", "upcoming" (due after today) or "none" (no due date);
+// From and To bound the due date, From inclusive and To exclusive. Search
+// and every entry of Terms must occur in the title or description, ignoring
+// case; Tags must all be present. Sort is "created" (the default, newest
+// first), "manual" (by Position), "due", "priority" or "title".
 type TaskFilter struct {
-	UserID     int64      `json:"-"`
-	Status     string     `json:"status"`
-	Priority   string     `json:"priority"`
-	DateFilter string     `json:"dateFilter"`
-	Search     string     `json:"search"`
-	CategoryID *int64     `json:"categoryId,omitempty"`
-	From       *time.Time `json:"from,omitempty"`
-	To         *time.Time `json:"to,omitempty"`
-	Sort       string     `json:"sort"`
+	UserID       int64      `json:"-"`
+	Status       string     `json:"status"`
+	Priority     string     `json:"priority"`
+	DateFilter   string     `json:"dateFilter"`
+	Search       string     `json:"search"`
+	CategoryID   *int64     `json:"categoryId,omitempty"`
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+	Sort         string     `json:"sort"`
+	Terms        []string   `json:"terms,omitempty"`
+	ExcludeTerms []string   `json:"excludeTerms,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	ExcludeTags  []string   `json:"excludeTags,omitempty"`
+	// CategoryName matches the category by name, ignoring case, for queries
+	// that name it instead of giving its id.
+	CategoryName string `json:"categoryName,omitempty"`
+	// IncludeSubcategories widens CategoryID and CategoryName to the
+	// category's subcategories, at any depth.
+	IncludeSubcategories bool `json:"includeSubcategories,omitempty"`
+}
+
+// SavedView is a named TaskFilter, Sort included. Views are listed by
+// Position; the built-in ones come first, have negative ids and cannot be
+// changed.
+type SavedView struct {
+	ID       int64      `json:"id"`
+	UserID   int64      `json:"-"`
+	Name     string     `json:"name"`
+	Filter   TaskFilter `json:"filter"`
+	Position int        `json:"position"`
+	BuiltIn  bool       `json:"builtIn"`
+}
+
+// Keyset is a task's position in the listing order, newest first.
+type Keyset struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// PageQuery selects up to Limit tasks matching Filter, newest first: those
+// after After, or the Limit nearest before Before. Filter.Sort is ignored.
+type PageQuery struct {
+	Filter TaskFilter
+	After  *Keyset
+	Before *Keyset
+	Limit  int
+}
+
+// TaskPage is one page of a listing. Next and Prev are opaque cursors for the
+// neighbouring pages, empty at either end.
+type TaskPage struct {
+	Items []Task `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// SearchResult is a task found by full-text search. Title and Snippet are
+// HTML-escaped, with the matched words wrapped in <mark>; Snippet is drawn
+// from the description.
+type SearchResult struct {
+	Task    Task    `json:"task"`
+	Rank    float64 `json:"rank"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
 }
 
 type Stats struct {
-	Total        int64 `json:"total"`
-	Active       int64 `json:"active"`
-	Completed    int64 `json:"completed"`
-	Overdue      int64 `json:"overdue"`
-	HighPriority int64 `json:"highPriority"`
+	Total     int64 `json:"total"`
+	Active    int64 `json:"active"`
+	Completed int64 `json:"completed"`
+	Overdue   int64 `json:"overdue"`
 }
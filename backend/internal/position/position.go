@@ -0,0 +1,85 @@
+// Package position generates the keys that keep tasks in a manual order.
+// Keys are strings of base-62 digits compared byte by byte, so a task can be
+// put between two others by giving it a key between theirs without touching
+// any other row. Keys never end in "0", which leaves room below every key.
+package position
+
+import "strings"
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// MaxLen is the longest key Between returns. Keys grow by a digit every few
+// moves into the same gap; once that gap is used up the keys are spread out
+// again.
+const MaxLen = 32
+
+// Between returns a key greater than a and, unless b is empty, less than b.
+// It fails when a is not below b or no key of at most MaxLen digits fits.
+func Between(a, b string) (string, bool) {
+	if b != "" && a >= b {
+		return "", false
+	}
+	k := mid(a, b)
+	if k <= a || b != "" && k >= b || len(k) > MaxLen {
+		return "", false
+	}
+	return k, true
+}
+
+func mid(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + mid(a[min(n, len(a)):], b[n:])
+		}
+	}
+	da, db := 0, len(digits)
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(digits[da]) + mid(rest, "")
+}
+
+// digitAt is the nth digit of k, with k padded by zeros.
+func digitAt(k string, n int) byte {
+	if n < len(k) {
+		return k[n]
+	}
+	return digits[0]
+}
+
+// Spread returns n increasing keys, evenly spaced and as short as n allows.
+func Spread(n int) []string {
+	width, span := 1, len(digits)
+	for span <= n {
+		width, span = width+1, span*len(digits)
+	}
+	step := span / (n + 1)
+	keys := make([]string, n)
+	buf := make([]byte, width)
+	for i := range keys {
+		v := (i + 1) * step
+		for j := width - 1; j >= 0; j-- {
+			buf[j] = digits[v%len(digits)]
+			v /= len(digits)
+		}
+		keys[i] = strings.TrimRight(string(buf), digits[:1])
+	}
+	return keys
+}
@@ -0,0 +1,53 @@
+package position
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	for _, c := range []struct{ a, b string }{
+		{"", ""}, {"", "V"}, {"V", ""}, {"V", "W"}, {"V", "V1"}, {"", "01"}, {"z", ""}, {"zz", ""}, {"Vz", "W"}, {"A1", "A2"},
+	} {
+		k, ok := Between(c.a, c.b)
+		if !ok || k <= c.a || c.b != "" && k >= c.b || k[len(k)-1] == '0' {
+			t.Errorf("Between(%q, %q) = %q, %v", c.a, c.b, k, ok)
+		}
+	}
+	for _, c := range []struct{ a, b string }{{"V", "V"}, {"W", "V"}} {
+		if k, ok := Between(c.a, c.b); ok {
+			t.Errorf("Between(%q, %q) = %q, want failure", c.a, c.b, k)
+		}
+	}
+}
+
+func TestBetweenRunsOut(t *testing.T) {
+	lo, hi, n := "V", "W", 0
+	for {
+		k, ok := Between(lo, hi)
+		if !ok {
+			break
+		}
+		lo, n = k, n+1
+	}
+	if n < 100 || len(lo) > MaxLen {
+		t.Fatalf("gap used up after %d keys, last %q", n, lo)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 61, 62, 1000} {
+		keys := Spread(n)
+		if len(keys) != n || !sort.StringsAreSorted(keys) {
+			t.Fatalf("Spread(%d) = %v", n, keys)
+		}
+		for i, k := range keys {
+			if k == "" || k[len(k)-1] == '0' || i > 0 && k == keys[i-1] {
+				t.Fatalf("Spread(%d)[%d] = %q", n, i, k)
+			}
+			if _, ok := Between("", k); !ok {
+				t.Fatalf("no room below %q", k)
+			}
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Package query parses the task search syntax into a models.TaskFilter:
+//
+//	tag:work priority:high due<2026-11-01 -is:done "exact phrase"
+//
+// Bare words and quoted phrases must all occur in the title or description.
+// category: matches one category and in: also its subcategories.
+// A leading "-" negates words, phrases, tag: and is:.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"todo-app/backend/internal/models"
+)
+
+var ErrInvalid = errors.New("invalid query")
+
+// Error points at the token that could not be parsed. Pos counts characters
+// from 1.
+type Error struct {
+	Pos   int
+	Token string
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid query: %s (at %d: %s)", e.Msg, e.Pos, e.Token)
+}
+
+func (e *Error) Unwrap() error { return ErrInvalid }
+
+type token struct {
+	pos    int // byte offset in the query
+	text   string
+	neg    bool
+	key    string
+	op     string
+	value  string
+	quoted bool
+}
+
+// tokenize splits on whitespace outside double quotes, so that
+// tag:"home office" and "exact phrase" stay single tokens.
+func tokenize(s string) ([]token, error) {
+	var res []token
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		start, inQuote, quoteAt := i, false, 0
+		for i < len(s) {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == '"' {
+				inQuote = !inQuote
+				quoteAt = i
+			} else if unicode.IsSpace(r) && !inQuote {
+				break
+			}
+			i += size
+		}
+		if inQuote {
+			return nil, errorAt(s, quoteAt, s[quoteAt:i], "unterminated quote")
+		}
+		res = append(res, token{pos: start, text: s[start:i]})
+	}
+	return res, nil
+}
+
+func errorAt(s string, pos int, tok, msg string) *Error {
+	return &Error{Pos: utf8.RuneCountInString(s[:pos]) + 1, Token: tok, Msg: msg}
+}
+
+// split separates the optional "-", key and comparison operator from the
+// value. A token without a recognised operator is a plain term.
+func split(t *token) {
+	rest := t.text
+	if len(rest) > 1 && rest[0] == '-' {
+		t.neg, rest = true, rest[1:]
+	}
+	t.value = rest
+	if strings.HasPrefix(rest, `"`) {
+		t.value, t.quoted = strings.Trim(rest, `"`), true
+		return
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end <= 0 {
+		return
+	}
+	for _, op := range []string{"<=", ">=", ":", "<", ">"} {
+		if strings.HasPrefix(rest[end:], op) {
+			t.key, t.op = strings.ToLower(rest[:end]), op
+			t.value = unquote(rest[end+len(op):])
+			return
+		}
+	}
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && strings.HasPrefix(v, `"`) && strings.HasSuffix(v, `"`) {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+// Parse turns query into a filter. Dates are calendar days in now's location,
+// and words like "today" are relative to now. priority: takes one of the
+// names in priorities, ignoring case.
+func Parse(query string, now time.Time, priorities []string) (models.TaskFilter, error) {
+	var f models.TaskFilter
+	tokens, err := tokenize(query)
+	if err != nil {
+		return f, err
+	}
+	p := parser{query: query, now: now, priorities: priorities, f: &f}
+	for _, t := range tokens {
+		split(&t)
+		if err := p.apply(t); err != nil {
+			return models.TaskFilter{}, err
+		}
+	}
+	return f, nil
+}
+
+type parser struct {
+	query      string
+	now        time.Time
+	priorities []string
+	f          *models.TaskFilter
+}
+
+func (p *parser) fail(t token, format string, args ...any) error {
+	return errorAt(p.query, t.pos, t.text, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) apply(t token) error {
+	if t.key == "" {
+		if t.value == "" {
+			return p.fail(t, "empty phrase")
+		}
+		if t.neg {
+			p.f.ExcludeTerms = append(p.f.ExcludeTerms, t.value)
+		} else {
+			p.f.Terms = append(p.f.Terms, t.value)
+		}
+		return nil
+	}
+	if t.value == "" {
+		return p.fail(t, "%s needs a value", t.key)
+	}
+	if t.op != ":" && t.key != "due" {
+		return p.fail(t, "only due supports %s", t.op)
+	}
+	if t.neg && t.key != "tag" && t.key != "is" {
+		return p.fail(t, "%s cannot be negated", t.key)
+	}
+	switch t.key {
+	case "tag":
+		if t.neg {
+			p.f.ExcludeTags = append(p.f.ExcludeTags, t.value)
+		} else {
+			p.f.Tags = append(p.f.Tags, t.value)
+		}
+	case "priority":
+		v := strings.ToLower(t.value)
+		if !slices.Contains(p.priorities, v) {
+			return p.fail(t, "unknown priority %q; use %s", t.value, strings.Join(p.priorities, ", "))
+		}
+		if p.f.Priority != "" && p.f.Priority != v {
+			return p.fail(t, "conflicts with priority:%s", p.f.Priority)
+		}
+		p.f.Priority = v
+	case "is":
+		return p.status(t)
+	case "due":
+		return p.due(t)
+	case "category", "in":
+		if id, err := strconv.ParseInt(t.value, 10, 64); err == nil && id > 0 {
+			p.f.CategoryID = &id
+		} else {
+			p.f.CategoryName = t.value
+		}
+		p.f.IncludeSubcategories = t.key == "in"
+	case "sort":
+		v := strings.ToLower(t.value)
+		switch v {
+		case "created", "manual", "due", "priority", "title":
+			p.f.Sort = v
+		default:
+			return p.fail(t, "unknown sort %q; use created, manual, due, priority or title", t.value)
+		}
+	default:
+		return p.fail(t, "unknown field %q; use tag, priority, is, due, category, in or sort", t.key)
+	}
+	return nil
+}
+
+func (p *parser) status(t token) error {
+	var status string
+	switch strings.ToLower(t.value) {
+	case "done", "completed":
+		status = "completed"
+	case "open", "active", "todo":
+		status = "active"
+	case "archived":
+		if t.neg {
+			return p.fail(t, "is:archived cannot be negated")
+		}
+		status = "archived"
+	case "overdue":
+		if t.neg {
+			return p.fail(t, "is:overdue cannot be negated")
+		}
+		return p.dateFilter(t, "overdue")
+	default:
+		return p.fail(t, "unknown state %q; use done, open, archived or overdue", t.value)
+	}
+	if t.neg {
+		status = map[string]string{"completed": "active", "active": "completed"}[status]
+	}
+	if p.f.Status != "" && p.f.Status != status {
+		return p.fail(t, "conflicts with an earlier is:")
+	}
+	p.f.Status = status
+	return nil
+}
+
+func (p *parser) dateFilter(t token, v string) error {
+	if p.f.DateFilter != "" && p.f.DateFilter != v {
+		return p.fail(t, "conflicts with an earlier due:%s", p.f.DateFilter)
+	}
+	p.f.DateFilter = v
+	return nil
+}
+
+func (p *parser) due(t token) error {
+	v := strings.ToLower(t.value)
+	if t.op == ":" {
+		switch v {
+		case "today", "week", "upcoming", "overdue", "none":
+			return p.dateFilter(t, v)
+		}
+	}
+	day, ok := p.day(v)
+	if !ok {
+		return p.fail(t, "bad date %q; use YYYY-MM-DD, today, tomorrow or yesterday", t.value)
+	}
+	next := day.AddDate(0, 0, 1)
+	switch t.op {
+	case ":":
+		p.from(day)
+		p.to(next)
+	case "<":
+		p.to(day)
+	case "<=":
+		p.to(next)
+	case ">":
+		p.from(next)
+	case ">=":
+		p.from(day)
+	}
+	return nil
+}
+
+func (p *parser) day(v string) (time.Time, bool) {
+	y, m, d := p.now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, p.now.Location())
+	switch v {
+	case "today":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	}
+	day, err := time.ParseInLocation("2006-01-02", v, p.now.Location())
+	return day, err == nil
+}
+
+// from and to keep the tightest bound when a query gives several.
+func (p *parser) from(t time.Time) {
+	if p.f.From == nil || t.After(*p.f.From) {
+		p.f.From = &t
+	}
+}
+
+func (p *parser) to(t time.Time) {
+	if p.f.To == nil || t.Before(*p.f.To) {
+		p.f.To = &t
+	}
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+var (
+	now    = time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	levels = []string{"p0", "high", "medium", "low"}
+)
+
+func day(y int, m time.Month, d int) *time.Time {
+	t := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestParse(t *testing.T) {
+	cat := int64(4)
+	cases := map[string]models.TaskFilter{
+		"":                  {},
+		"milk":              {Terms: []string{"milk"}},
+		`"exact phrase" -x`: {Terms: []string{"exact phrase"}, ExcludeTerms: []string{"x"}},
+		`tag:work -tag:"home office" priority:HIGH`: {
+			Tags: []string{"work"}, ExcludeTags: []string{"home office"}, Priority: "high",
+		},
+		"priority:P0":                    {Priority: "p0"},
+		"-is:done":                       {Status: "active"},
+		"is:done":                        {Status: "completed"},
+		"is:archived":                    {Status: "archived"},
+		"is:overdue":                     {DateFilter: "overdue"},
+		"due:week sort:due":              {DateFilter: "week", Sort: "due"},
+		"sort:Manual":                    {Sort: "manual"},
+		"due:upcoming":                   {DateFilter: "upcoming"},
+		"due<2026-11-01":                 {To: day(2026, 11, 1)},
+		"due<=2026-11-01":                {To: day(2026, 11, 2)},
+		"due>=today due<tomorrow":        {From: day(2026, 10, 18), To: day(2026, 10, 19)},
+		"due:2026-11-01 due<=2026-12-31": {From: day(2026, 11, 1), To: day(2026, 11, 2)},
+		"category:4":                     {CategoryID: &cat},
+		"category:Дом":                   {CategoryName: "Дом"},
+		"in:Дом":                         {CategoryName: "Дом", IncludeSubcategories: true},
+	}
+	for in, want := range cases {
+		got, err := Parse(in, now, levels)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+}
+
+func TestParseErrorsPointAtToken(t *testing.T) {
+	cases := []struct {
+		in    string
+		pos   int
+		token string
+	}{
+		{"tag:work priority:urgent", 10, "priority:urgent"},
+		{"молоко due<someday", 8, "due<someday"},
+		{`milk "unterminated`, 6, `"unterminated`},
+		{"color:red", 1, "color:red"},
+		{"tag<work", 1, "tag<work"},
+		{"-priority:high", 1, "-priority:high"},
+		{"is:done is:open", 9, "is:open"},
+		{"tag:", 1, "tag:"},
+		{"sort:random", 1, "sort:random"},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.in, now, levels)
+		var qe *Error
+		if !errors.As(err, &qe) || !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = %v, want a query error", c.in, err)
+			continue
+		}
+		if qe.Pos != c.pos || qe.Token != c.token {
+			t.Errorf("Parse(%q) points at %d %q, want %d %q", c.in, qe.Pos, qe.Token, c.pos, c.token)
+		}
+	}
+}
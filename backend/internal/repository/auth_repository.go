@@ -11,6 +11,7 @@ type AuthRepository interface {
 	CreateUser(email, passwordHash string) (models.User, error)
 	GetUserByEmail(email string) (models.User, error)
 	GetUserByID(id int64) (models.User, error)
+	ClaimLegacyUser(email, passwordHash string) (models.User, error)
 }
 
 type authRepo struct{ db *sql.DB }
@@ -60,3 +61,23 @@ func (r *authRepo) GetUserByID(id int64) (models.User, error) {
 	}
 	return u, nil
 }
+
+// ClaimLegacyUser hands the placeholder account that migration 002 created for
+// pre-account data (empty password hash) to the first person who registers.
+// It returns a zero User once any real account exists.
+func (r *authRepo) ClaimLegacyUser(email, passwordHash string) (models.User, error) {
+	var u models.User
+	err := r.db.QueryRow(`
+		UPDATE users SET email = $1, password_hash = $2
+		WHERE id = (SELECT MIN(id) FROM users WHERE password_hash = '')
+		  AND NOT EXISTS (SELECT 1 FROM users WHERE password_hash <> '')
+		RETURNING id, email, password_hash, created_at
+	`, email, passwordHash).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.User{}, nil
+		}
+		return models.User{}, err
+	}
+	return u, nil
+}
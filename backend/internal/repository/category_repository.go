@@ -2,38 +2,280 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"todo-app/backend/internal/models"
 )
 
 type CategoryRepository interface {
-	Create(userID int64, name string) (models.Category, error)
+	// Create adds c as the last child of c.ParentID.
+	Create(c models.Category) (models.Category, error)
+	Get(userID, id int64) (models.Category, error)
+	// List returns the user's categories ordered by position; TotalCount is
+	// left for the caller to sum.
 	List(userID int64) ([]models.Category, error)
+	// Update renames and restyles c, with the same version check as
+	// TaskRepository.Update. It does not move c.
+	Update(c models.Category) (models.Category, error)
+	// Move makes id the child of parentID, nil for the top level, at
+	// position among its new siblings; a position past the end appends.
+	// Both the old and the new siblings are renumbered from 0. It has the
+	// same version check as Update.
+	Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error)
+	// Delete moves the category to the trash. Its subcategories take its
+	// place under its parent, after the parent's other children. The tasks
+	// in it are handled as tasks says, one of the models.Category*Tasks
+	// constants; moveTo is the category that "move" moves them to. It has
+	// the same version check as Update; with version 0 deleting a missing
+	// category is not an error.
+	Delete(userID, id int64, tasks string, moveTo *int64, version int64) error
 }
 
 type categoryRepo struct{ db *sql.DB }
 
 func NewCategoryRepository(db *sql.DB) CategoryRepository { return &categoryRepo{db: db} }
 
-func (r *categoryRepo) Create(userID int64, name string) (models.Category, error) {
-	var c models.Category
-	err := r.db.QueryRow(`INSERT INTO categories(user_id,name) VALUES($1,$2) RETURNING id,user_id,name`, userID, name).Scan(&c.ID, &c.UserID, &c.Name)
+// categoryColumns are the same in both dialects.
+const categoryColumns = `id, user_id, name, parent_id, position, color, icon, version,
+	(select count(*) from tasks t where t.category_id = categories.id and t.deleted_at is null and t.archived_at is null)`
+
+func scanCategory(s scanner) (models.Category, error) {
+	var (
+		c        models.Category
+		parentID sql.NullInt64
+	)
+	err := s.Scan(&c.ID, &c.UserID, &c.Name, &parentID, &c.Position, &c.Color, &c.Icon, &c.Version, &c.TaskCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, ErrNotFound
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.Int64
+	}
 	return c, err
 }
 
-func (r *categoryRepo) List(userID int64) ([]models.Category, error) {
-	rows, err := r.db.Query(`SELECT id,user_id,name FROM categories WHERE user_id=$1 ORDER BY name`, userID)
+func getCategory(q querier, userID, id int64) (models.Category, error) {
+	return scanCategory(q.QueryRow(`select `+categoryColumns+` from categories where id=$1 and user_id=$2 and deleted_at is null`, id, userID))
+}
+
+func listCategories(q querier, userID int64) ([]models.Category, error) {
+	rows, err := q.Query(`select `+categoryColumns+` from categories where user_id=$1 and deleted_at is null order by position, id`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var res []models.Category
 	for rows.Next() {
-		var c models.Category
-		if err := rows.Scan(&c.ID, &c.UserID, &c.Name); err != nil {
+		c, err := scanCategory(rows)
+		if err != nil {
 			return nil, err
 		}
 		res = append(res, c)
 	}
-	return res, nil
+	return res, rows.Err()
+}
+
+// nextPosition is the position after the last live child of parentID.
+const nextPosition = `(select coalesce(max(position)+1, 0) from categories
+	where user_id=$1 and coalesce(parent_id, 0) = coalesce($2, 0) and deleted_at is null)`
+
+// siblings lists the ids of the live children of parentID other than id, in
+// order.
+func siblings(q querier, userID int64, parentID *int64, id int64) ([]int64, error) {
+	rows, err := q.Query(`select id from categories
+		where user_id=$1 and coalesce(parent_id, 0) = coalesce($2, 0) and id <> $3 and deleted_at is null
+		order by position, id`, userID, parentID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func renumber(q querier, ids []int64) error {
+	for i, id := range ids {
+		if _, err := q.Exec(`update categories set position=$1 where id=$2`, i, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveCategory is Move inside a transaction.
+func moveCategory(q querier, userID, id int64, parentID *int64, position int, version int64) error {
+	var from *int64
+	err := q.QueryRow(`select parent_id from categories where id=$1 and user_id=$2 and deleted_at is null`, id, userID).Scan(&from)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	ids, err := siblings(q, userID, parentID, id)
+	if err != nil {
+		return err
+	}
+	ids = slices.Insert(ids, min(max(position, 0), len(ids)), id)
+	// version=$3 comes first so that Postgres types $3 as bigint.
+	res, err := q.Exec(`update categories set parent_id=$1, version=version+1 where id=$2 and (version=$3 or $3 = 0)`, parentID, id, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	if err := renumber(q, ids); err != nil {
+		return err
+	}
+	if (from == nil) == (parentID == nil) && (from == nil || *from == *parentID) {
+		return nil
+	}
+	if ids, err = siblings(q, userID, from, id); err != nil {
+		return err
+	}
+	return renumber(q, ids)
+}
+
+// orphanChildren hands the live children of the trashed category id to its
+// parent, after the parent's other children.
+func orphanChildren(q querier, userID, id int64) error {
+	var (
+		parentID *int64
+		next     int
+	)
+	if err := q.QueryRow(`select parent_id from categories where id=$1 and user_id=$2`, id, userID).Scan(&parentID); err != nil {
+		return err
+	}
+	if err := q.QueryRow(`select `+nextPosition, userID, parentID).Scan(&next); err != nil {
+		return err
+	}
+	_, err := q.Exec(`update categories set parent_id=$1, position=position+$2
+		where user_id=$3 and parent_id=$4 and deleted_at is null`, parentID, next, userID, id)
+	return err
+}
+
+// releaseTasks moves, clears or trashes the tasks of the category id, which
+// is being deleted at now, and points its series at moveTo or nothing.
+func releaseTasks(q querier, userID, id int64, tasks string, moveTo *int64, now any) error {
+	var query string
+	args := []any{userID, id}
+	switch tasks {
+	case models.CategoryMoveTasks:
+		query, args = `update tasks set category_id=$3, version=version+1`, append(args, moveTo)
+	case models.CategoryClearTasks:
+		query, moveTo = `update tasks set category_id=null, version=version+1`, nil
+	case models.CategoryDeleteTasks:
+		query, args, moveTo = `update tasks set deleted_at=$3, version=version+1`, append(args, now), nil
+	default:
+		return fmt.Errorf("unknown category deletion %q", tasks)
+	}
+	if _, err := q.Exec(query+` where user_id=$1 and category_id=$2 and deleted_at is null`, args...); err != nil {
+		return err
+	}
+	_, err := q.Exec(`update task_series set category_id=$3 where user_id=$1 and category_id=$2`, userID, id, moveTo)
+	return err
+}
+
+// reattachCategory makes a restored category top-level when its parent is
+// gone, and puts it after its siblings.
+func reattachCategory(q querier, userID, id int64) error {
+	if _, err := q.Exec(`update categories set parent_id=null
+		where id=$1 and user_id=$2 and parent_id not in (select id from categories where user_id=$2 and deleted_at is null)`, id, userID); err != nil {
+		return err
+	}
+	_, err := q.Exec(`update categories set position=(select coalesce(max(position)+1, 0) from categories s
+			where s.user_id=$2 and coalesce(s.parent_id, 0) = coalesce(categories.parent_id, 0) and s.id <> $1 and s.deleted_at is null)
+		where id=$1 and user_id=$2`, id, userID)
+	return err
+}
+
+func (r *categoryRepo) Create(c models.Category) (models.Category, error) {
+	var id int64
+	err := r.db.QueryRow(`insert into categories (user_id, name, parent_id, color, icon, position)
+		values ($1, $3, $2, $4, $5, `+nextPosition+`) returning id`, c.UserID, c.ParentID, c.Name, c.Color, c.Icon).Scan(&id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	return r.Get(c.UserID, id)
+}
+
+func (r *categoryRepo) Get(userID, id int64) (models.Category, error) {
+	return getCategory(r.db, userID, id)
+}
+
+func (r *categoryRepo) List(userID int64) ([]models.Category, error) {
+	return listCategories(r.db, userID)
+}
+
+func (r *categoryRepo) Update(c models.Category) (models.Category, error) {
+	res, err := r.db.Exec(`
+		UPDATE categories SET name=$1, color=$2, icon=$3, version=version+1
+		WHERE id=$4 AND user_id=$5 AND deleted_at IS NULL AND ($6::bigint = 0 OR version=$6)`, c.Name, c.Color, c.Icon, c.ID, c.UserID, c.Version)
+	if err != nil {
+		return models.Category{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Category{}, missing(r.db, "categories", c.UserID, c.ID)
+	}
+	return r.Get(c.UserID, c.ID)
+}
+
+func (r *categoryRepo) Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Category{}, err
+	}
+	defer tx.Rollback()
+	if err := moveCategory(tx, userID, id, parentID, position, version); err != nil {
+		return models.Category{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Category{}, err
+	}
+	return r.Get(userID, id)
+}
+
+func (r *categoryRepo) Delete(userID, id int64, tasks string, moveTo *int64, version int64) error {
+	return deleteCategory(r.db, userID, id, tasks, moveTo, version, time.Now())
+}
+
+// deleteCategory is Delete for both dialects; now is the deletion time as
+// the dialect stores it.
+func deleteCategory(db *sql.DB, userID, id int64, tasks string, moveTo *int64, version int64, now any) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`
+		update categories set deleted_at=$3
+		where id=$1 and user_id=$2 and deleted_at is null and (version=$4 or $4 = 0)
+	`, id, userID, now, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if version == 0 {
+			return nil
+		}
+		return missing(tx, "categories", userID, id)
+	}
+	if err := releaseTasks(tx, userID, id, tasks, moveTo, now); err != nil {
+		return err
+	}
+	if err := orphanChildren(tx, userID, id); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
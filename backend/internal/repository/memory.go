@@ -0,0 +1,1947 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/position"
+)
+
+var (
+	errDuplicateEmail    = errors.New("duplicate key value violates unique constraint on users.email")
+	errDuplicateTag      = errors.New("duplicate key value violates unique constraint on tags")
+	errDuplicatePriority = errors.New("duplicate key value violates unique constraint on priorities")
+	errNoCategory        = errors.New("insert or update violates foreign key constraint on category_id")
+)
+
+// MemoryStore holds the rows behind the in-memory repositories. Repositories
+// built on the same store see each other's data, like tables in one database,
+// and are safe for concurrent use.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	now        func() time.Time
+	lastID     int64
+	users      map[int64]models.User
+	tasks      map[int64]models.Task
+	subtasks   map[int64]models.Subtask
+	categories map[int64]models.Category
+	tokens     map[int64]models.RefreshToken
+	series     map[int64]models.TaskSeries
+	skips      map[int64][]time.Time
+	views      map[int64]models.SavedView
+	tags       map[int64]models.Tag
+	priorities map[int64]models.Priority
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:        time.Now,
+		users:      map[int64]models.User{},
+		tasks:      map[int64]models.Task{},
+		subtasks:   map[int64]models.Subtask{},
+		categories: map[int64]models.Category{},
+		tokens:     map[int64]models.RefreshToken{},
+		series:     map[int64]models.TaskSeries{},
+		skips:      map[int64][]time.Time{},
+		views:      map[int64]models.SavedView{},
+		tags:       map[int64]models.Tag{},
+		priorities: map[int64]models.Priority{},
+	}
+}
+
+// nextID hands out ids from one sequence across all tables; callers hold mu.
+func (s *MemoryStore) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+// task, subtask and category look up a row of the user's that is not in the
+// trash; a subtask is also trashed along with its task. Callers hold mu.
+func (s *MemoryStore) task(userID, id int64) (models.Task, bool) {
+	t, ok := s.tasks[id]
+	return t, ok && t.UserID == userID && t.DeletedAt == nil
+}
+
+func (s *MemoryStore) subtask(userID, id int64) (models.Subtask, bool) {
+	st, ok := s.subtasks[id]
+	if !ok || st.UserID != userID || st.DeletedAt != nil {
+		return st, false
+	}
+	_, ok = s.task(userID, st.TaskID)
+	return st, ok
+}
+
+func (s *MemoryStore) category(userID, id int64) (models.Category, bool) {
+	c, ok := s.categories[id]
+	return c, ok && c.UserID == userID && c.DeletedAt == nil
+}
+
+// checkCategory fails like a foreign key when id is set but no category,
+// trashed or not, has it. Callers hold mu.
+func (s *MemoryStore) checkCategory(id *int64) error {
+	if id == nil {
+		return nil
+	}
+	if _, ok := s.categories[*id]; !ok {
+		return errNoCategory
+	}
+	return nil
+}
+
+// clearCategory does what the foreign keys do when category id is purged.
+// Callers hold mu.
+func (s *MemoryStore) clearCategory(id int64) {
+	for tid, t := range s.tasks {
+		if t.CategoryID != nil && *t.CategoryID == id {
+			t.CategoryID = nil
+			s.tasks[tid] = t
+		}
+	}
+	for sid, ts := range s.series {
+		if ts.CategoryID != nil && *ts.CategoryID == id {
+			ts.CategoryID = nil
+			s.series[sid] = ts
+		}
+	}
+}
+
+// inCategory reports whether match holds for t's category or, with sub, a
+// category above it. Callers hold mu.
+func (s *MemoryStore) inCategory(t models.Task, sub bool, match func(models.Category) bool) bool {
+	for id := t.CategoryID; id != nil; {
+		c, ok := s.category(t.UserID, *id)
+		if !ok {
+			return false
+		}
+		if match(c) {
+			return true
+		}
+		if !sub {
+			return false
+		}
+		id = c.ParentID
+	}
+	return false
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneTask(t models.Task) models.Task {
+	t.DueDate = clonePtr(t.DueDate)
+	t.CompletedAt = clonePtr(t.CompletedAt)
+	t.RepeatRule = clonePtr(t.RepeatRule)
+	t.CategoryID = clonePtr(t.CategoryID)
+	t.CategoryName = nil
+	t.Tags = append([]string{}, t.Tags...)
+	t.SeriesID = clonePtr(t.SeriesID)
+	t.OccurrenceAt = clonePtr(t.OccurrenceAt)
+	t.Subtasks = nil
+	t.ArchivedAt = clonePtr(t.ArchivedAt)
+	t.DeletedAt = clonePtr(t.DeletedAt)
+	return t
+}
+
+func cloneSeries(ts models.TaskSeries) models.TaskSeries {
+	ts.CategoryID = clonePtr(ts.CategoryID)
+	ts.Tags = append([]string{}, nonNilTags(ts.Tags)...)
+	ts.Subtasks = append([]string{}, nonNilTags(ts.Subtasks)...)
+	ts.EndedAt = clonePtr(ts.EndedAt)
+	return ts
+}
+
+func cloneRefreshToken(t models.RefreshToken) models.RefreshToken {
+	t.RevokedAt = clonePtr(t.RevokedAt)
+	t.ReplacedBy = clonePtr(t.ReplacedBy)
+	return t
+}
+
+// sortTasks applies the SQL implementations' "order by created_at desc, id desc".
+func sortTasks(ts []models.Task) {
+	sort.Slice(ts, func(i, j int) bool {
+		if !ts[i].CreatedAt.Equal(ts[j].CreatedAt) {
+			return ts[i].CreatedAt.After(ts[j].CreatedAt)
+		}
+		return ts[i].ID > ts[j].ID
+	})
+}
+
+type memoryTaskRepository struct{ s *MemoryStore }
+
+func NewMemoryTaskRepository(s *MemoryStore) TaskRepository {
+	return &memoryTaskRepository{s: s}
+}
+
+func (r *memoryTaskRepository) list(userID int64, keep func(models.Task) bool) []models.Task {
+	var res []models.Task
+	for _, t := range r.s.tasks {
+		if t.UserID == userID && t.DeletedAt == nil && keep(t) {
+			res = append(res, cloneTask(t))
+		}
+	}
+	sortTasks(res)
+	return res
+}
+
+func dueWithin(t models.Task, from, to time.Time) bool {
+	return t.DueDate != nil && !t.DueDate.Before(from) && t.DueDate.Before(to)
+}
+
+func (r *memoryTaskRepository) GetAll(userID int64, filter, order string) ([]models.Task, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	now := r.s.now()
+	archived := filter == "archived"
+	keep := func(models.Task) bool { return true }
+	switch filter {
+	case "active":
+		keep = func(t models.Task) bool { return !t.Completed }
+	case "completed":
+		keep = func(t models.Task) bool { return t.Completed }
+	case "overdue":
+		keep = func(t models.Task) bool { return !t.Completed && t.DueDate != nil && t.DueDate.Before(now) }
+	case "today":
+		from, to := dayBounds(now)
+		keep = func(t models.Task) bool { return dueWithin(t, from, to) }
+	case "week":
+		from, to := weekBounds(now)
+		keep = func(t models.Task) bool { return dueWithin(t, from, to) }
+	}
+	res := r.list(userID, func(t models.Task) bool { return (t.ArchivedAt != nil) == archived && keep(t) })
+	r.s.orderTasks(res, order)
+	return res, nil
+}
+
+func containsFold(s, sub string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+}
+
+func hasTagFold(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter mirrors the where clause built by the SQL Query implementations.
+func (r *memoryTaskRepository) matchFilter(f models.TaskFilter, now time.Time) func(models.Task) bool {
+	terms := filterTerms(f)
+	return func(t models.Task) bool {
+		switch {
+		case (f.Status == "archived") != (t.ArchivedAt != nil),
+			f.Status == "active" && t.Completed,
+			f.Status == "completed" && !t.Completed,
+			f.Priority != "" && t.Priority != f.Priority,
+			f.From != nil && (t.DueDate == nil || t.DueDate.Before(*f.From)),
+			f.To != nil && (t.DueDate == nil || !t.DueDate.Before(*f.To)),
+			f.CategoryID != nil && !f.IncludeSubcategories && (t.CategoryID == nil || *t.CategoryID != *f.CategoryID):
+			return false
+		}
+		switch f.DateFilter {
+		case "overdue":
+			if t.Completed || t.DueDate == nil || !t.DueDate.Before(now) {
+				return false
+			}
+		case "today":
+			if from, to := dayBounds(now); !dueWithin(t, from, to) {
+				return false
+			}
+		case "week":
+			if from, to := weekBounds(now); !dueWithin(t, from, to) {
+				return false
+			}
+		case "upcoming":
+			if _, to := dayBounds(now); t.DueDate == nil || t.DueDate.Before(to) {
+				return false
+			}
+		case "none":
+			if t.DueDate != nil {
+				return false
+			}
+		}
+		if f.CategoryID != nil && f.IncludeSubcategories &&
+			!r.s.inCategory(t, true, func(c models.Category) bool { return c.ID == *f.CategoryID }) {
+			return false
+		}
+		if f.CategoryName != "" &&
+			!r.s.inCategory(t, f.IncludeSubcategories, func(c models.Category) bool { return strings.EqualFold(c.Name, f.CategoryName) }) {
+			return false
+		}
+		for _, term := range terms {
+			if !containsFold(t.Title, term) && !containsFold(t.Description, term) {
+				return false
+			}
+		}
+		for _, term := range f.ExcludeTerms {
+			if containsFold(t.Title, term) || containsFold(t.Description, term) {
+				return false
+			}
+		}
+		for _, tag := range f.Tags {
+			if !hasTagFold(t.Tags, tag) {
+				return false
+			}
+		}
+		for _, tag := range f.ExcludeTags {
+			if hasTagFold(t.Tags, tag) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func (r *memoryTaskRepository) Query(f models.TaskFilter) ([]models.Task, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.query(f), nil
+}
+
+func (r *memoryTaskRepository) query(f models.TaskFilter) []models.Task {
+	res := r.list(f.UserID, r.matchFilter(f, r.s.now()))
+	r.s.orderTasks(res, f.Sort)
+	return res
+}
+
+// orderTasks applies taskOrder to ts, which are already newest first, the
+// order every other one falls back to; callers hold mu.
+func (s *MemoryStore) orderTasks(ts []models.Task, order string) {
+	switch order {
+	case "manual":
+		sort.SliceStable(ts, func(i, j int) bool { return ts[i].Position < ts[j].Position })
+	case "due":
+		sort.SliceStable(ts, func(i, j int) bool {
+			a, b := ts[i].DueDate, ts[j].DueDate
+			return a != nil && (b == nil || a.Before(*b))
+		})
+	case "priority":
+		sort.SliceStable(ts, func(i, j int) bool { return s.priorityWeight(ts[i]) > s.priorityWeight(ts[j]) })
+	case "title":
+		sort.Slice(ts, func(i, j int) bool {
+			a, b := strings.ToLower(ts[i].Title), strings.ToLower(ts[j].Title)
+			if a != b {
+				return a < b
+			}
+			return ts[i].ID < ts[j].ID
+		})
+	}
+}
+
+func (r *memoryTaskRepository) Page(p models.PageQuery) ([]models.Task, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p.Filter.Sort = ""
+	all := r.query(p.Filter)
+	// all is newest first, so the tasks newer than a key are a prefix, and
+	// so are those no older than it.
+	prefix := func(k *models.Keyset, withKey bool) int {
+		return sort.Search(len(all), func(i int) bool {
+			t := all[i]
+			if t.CreatedAt.Equal(k.CreatedAt) {
+				return t.ID < k.ID || !withKey && t.ID == k.ID
+			}
+			return t.CreatedAt.Before(k.CreatedAt)
+		})
+	}
+	switch {
+	case p.After != nil:
+		all = all[prefix(p.After, true):]
+	case p.Before != nil:
+		i := prefix(p.Before, false)
+		return all[max(0, i-p.Limit):i], nil
+	}
+	return all[:min(p.Limit, len(all))], nil
+}
+
+func (r *memoryTaskRepository) Search(f models.TaskFilter) ([]models.SearchResult, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	terms, exclude := filterTerms(f), f.ExcludeTerms
+	f.Search, f.Terms, f.ExcludeTerms = "", nil, nil
+	var res []models.SearchResult
+	for _, t := range r.query(f) {
+		d := searchFields{title: t.Title, description: t.Description, tags: t.Tags}
+		for _, st := range r.s.subtasks {
+			if st.TaskID == t.ID && st.DeletedAt == nil {
+				d.subtasks = append(d.subtasks, st.Title)
+			}
+		}
+		if matchesTerms(d, terms, exclude) {
+			res = append(res, searchResult(t, d, terms))
+		}
+	}
+	sortResults(res, f.Sort)
+	return res, nil
+}
+
+func (r *memoryTaskRepository) get(userID, id int64) (*models.Task, error) {
+	t, ok := r.s.task(userID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	t = cloneTask(t)
+	return &t, nil
+}
+
+func (r *memoryTaskRepository) GetByID(userID, id int64) (*models.Task, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.get(userID, id)
+}
+
+// topPosition mirrors the SQL implementations' topPosition.
+func (s *MemoryStore) topPosition(userID int64) string {
+	first, found := "", false
+	for _, t := range s.tasks {
+		if t.UserID == userID && t.DeletedAt == nil && (!found || t.Position < first) {
+			first, found = t.Position, true
+		}
+	}
+	if found && first == "" {
+		return ""
+	}
+	k, _ := position.Between("", first)
+	return k
+}
+
+func (r *memoryTaskRepository) create(task *models.Task) *models.Task {
+	if task.Position == "" {
+		task.Position = r.s.topPosition(task.UserID)
+	}
+	task.ID = r.s.nextID()
+	task.CreatedAt = r.s.now()
+	task.Completed = false
+	task.CompletedAt = nil
+	task.Tags = r.s.registerTags(task.UserID, task.Tags)
+	task.Version = 1
+	r.s.tasks[task.ID] = cloneTask(*task)
+	for i := range task.Subtasks {
+		st := &task.Subtasks[i]
+		st.ID, st.UserID, st.TaskID, st.Completed, st.CreatedAt, st.Version = r.s.nextID(), task.UserID, task.ID, false, task.CreatedAt, 1
+		r.s.subtasks[st.ID] = *st
+	}
+	return task
+}
+
+func (r *memoryTaskRepository) Create(task *models.Task) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.s.checkCategory(task.CategoryID); err != nil {
+		return nil, err
+	}
+	return r.create(task), nil
+}
+
+func (r *memoryTaskRepository) Update(task *models.Task) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.update(task)
+}
+
+func (r *memoryTaskRepository) UpdateWithSubtasks(task *models.Task, subtasks []models.Subtask) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	res, err := r.update(task)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range subtasks {
+		st.ID, st.UserID, st.TaskID, st.CreatedAt, st.Version = r.s.nextID(), task.UserID, task.ID, r.s.now(), 1
+		r.s.subtasks[st.ID] = st
+	}
+	return res, nil
+}
+
+func (r *memoryTaskRepository) update(task *models.Task) (*models.Task, error) {
+	cur, ok := r.s.task(task.UserID, task.ID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if task.Version != 0 && task.Version != cur.Version {
+		return nil, ErrConflict
+	}
+	if err := r.s.checkCategory(task.CategoryID); err != nil {
+		return nil, err
+	}
+	cur.Version++
+	cur.Title = task.Title
+	cur.Description = task.Description
+	cur.Priority = task.Priority
+	cur.DueDate = clonePtr(task.DueDate)
+	cur.RepeatRule = clonePtr(task.RepeatRule)
+	cur.CategoryID = clonePtr(task.CategoryID)
+	cur.Tags = r.s.registerTags(task.UserID, task.Tags)
+	cur.SeriesID = clonePtr(task.SeriesID)
+	cur.OccurrenceAt = clonePtr(task.OccurrenceAt)
+	r.s.tasks[task.ID] = cur
+	return r.get(task.UserID, task.ID)
+}
+
+func (r *memoryTaskRepository) SetCompleted(userID, id int64, completed bool, version int64, next *models.Task) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.task(userID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if version != 0 && version != cur.Version {
+		return nil, ErrConflict
+	}
+	cur.Completed = completed
+	cur.CompletedAt = nil
+	cur.Version++
+	if !completed {
+		cur.ArchivedAt = nil
+	}
+	if completed {
+		now := r.s.now()
+		cur.CompletedAt = &now
+	}
+	r.s.tasks[id] = cur
+	if next != nil {
+		r.create(next)
+	}
+	return r.get(userID, id)
+}
+
+func (r *memoryTaskRepository) delete(userID int64, keep func(models.Task) bool) int64 {
+	var n int64
+	for id, t := range r.s.tasks {
+		if t.UserID != userID || keep(t) {
+			continue
+		}
+		delete(r.s.tasks, id)
+		for sid, st := range r.s.subtasks {
+			if st.TaskID == id {
+				delete(r.s.subtasks, sid)
+			}
+		}
+		n++
+	}
+	return n
+}
+
+// trash moves the user's tasks that keep rejects to the trash.
+func (r *memoryTaskRepository) trash(userID int64, keep func(models.Task) bool) int64 {
+	var n int64
+	now := r.s.now()
+	for id, t := range r.s.tasks {
+		if t.UserID != userID || t.DeletedAt != nil || keep(t) {
+			continue
+		}
+		t.DeletedAt = &now
+		r.s.tasks[id] = t
+		n++
+	}
+	return n
+}
+
+func (r *memoryTaskRepository) Delete(userID, id, version int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if version != 0 {
+		cur, ok := r.s.task(userID, id)
+		if !ok {
+			return ErrNotFound
+		}
+		if cur.Version != version {
+			return ErrConflict
+		}
+	}
+	r.trash(userID, func(t models.Task) bool { return t.ID != id })
+	return nil
+}
+
+func (r *memoryTaskRepository) DeleteMany(userID int64, ids []int64) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return r.trash(userID, func(t models.Task) bool { return !set[t.ID] }), nil
+}
+
+func (r *memoryTaskRepository) ClearCompleted(userID int64) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.archive(func(t models.Task) bool { return t.UserID == userID }), nil
+}
+
+// archive archives the live completed tasks that match.
+func (r *memoryTaskRepository) archive(match func(models.Task) bool) int64 {
+	var n int64
+	now := r.s.now()
+	for id, t := range r.s.tasks {
+		if !t.Completed || t.ArchivedAt != nil || t.DeletedAt != nil || !match(t) {
+			continue
+		}
+		t.ArchivedAt = &now
+		t.Version++
+		r.s.tasks[id] = t
+		n++
+	}
+	return n
+}
+
+func (r *memoryTaskRepository) Unarchive(userID, id, version int64) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.task(userID, id)
+	if !ok || cur.ArchivedAt == nil {
+		return nil, ErrNotFound
+	}
+	if version != 0 && version != cur.Version {
+		return nil, ErrConflict
+	}
+	cur.ArchivedAt = nil
+	cur.Version++
+	r.s.tasks[id] = cur
+	return r.get(userID, id)
+}
+
+func (r *memoryTaskRepository) ArchiveCompleted(before time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.archive(func(t models.Task) bool { return t.CompletedAt != nil && t.CompletedAt.Before(before) }), nil
+}
+
+func (r *memoryTaskRepository) Restore(t *models.Task) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.s.checkCategory(t.CategoryID); err != nil {
+		return err
+	}
+	version := t.Version + 1
+	if cur, ok := r.s.tasks[t.ID]; ok {
+		if cur.UserID != t.UserID {
+			return ErrNotFound
+		}
+		version = cur.Version + 1
+	}
+	row := cloneTask(*t)
+	if cur, ok := r.s.tasks[t.ID]; ok {
+		row.Position = cur.Position
+	}
+	row.Tags = r.s.registerTags(t.UserID, row.Tags)
+	row.Version = version
+	row.DeletedAt = nil
+	r.s.tasks[t.ID] = row
+	for id, st := range r.s.subtasks {
+		if st.TaskID == t.ID && st.DeletedAt == nil {
+			delete(r.s.subtasks, id)
+		}
+	}
+	for _, st := range t.Subtasks {
+		st.UserID, st.TaskID, st.DeletedAt = t.UserID, t.ID, nil
+		st.Version++
+		r.s.subtasks[st.ID] = st
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) Move(userID, id int64, pos string) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.task(userID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cur.Position = pos
+	r.s.tasks[id] = cur
+	return r.get(userID, id)
+}
+
+func (r *memoryTaskRepository) Rebalance(userID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var ts []models.Task
+	for _, t := range r.s.tasks {
+		if t.UserID == userID {
+			ts = append(ts, t)
+		}
+	}
+	sortTasks(ts)
+	r.s.orderTasks(ts, "manual")
+	for i, k := range position.Spread(len(ts)) {
+		ts[i].Position = k
+		r.s.tasks[ts[i].ID] = ts[i]
+	}
+	return nil
+}
+
+type memorySubtaskRepository struct{ s *MemoryStore }
+
+func NewMemorySubtaskRepository(s *MemoryStore) SubtaskRepository {
+	return &memorySubtaskRepository{s: s}
+}
+
+func (r *memorySubtaskRepository) List(userID, taskID int64) ([]models.Subtask, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var res []models.Subtask
+	for id, st := range r.s.subtasks {
+		if _, ok := r.s.subtask(userID, id); ok && st.TaskID == taskID {
+			res = append(res, st)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+func (r *memorySubtaskRepository) Create(st *models.Subtask) (*models.Subtask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.task(st.UserID, st.TaskID); !ok {
+		return nil, ErrNotFound
+	}
+	st.ID = r.s.nextID()
+	st.CreatedAt = r.s.now()
+	st.Completed = false
+	st.Version = 1
+	r.s.subtasks[st.ID] = *st
+	return st, nil
+}
+
+func (r *memorySubtaskRepository) Get(userID, id int64) (*models.Subtask, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	st, ok := r.s.subtask(userID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &st, nil
+}
+
+func (r *memorySubtaskRepository) Update(s *models.Subtask) (*models.Subtask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	st, ok := r.s.subtask(s.UserID, s.ID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if s.Version != 0 && s.Version != st.Version {
+		return nil, ErrConflict
+	}
+	st.Title, st.Completed = s.Title, s.Completed
+	st.Version++
+	r.s.subtasks[s.ID] = st
+	return &st, nil
+}
+
+func (r *memorySubtaskRepository) Toggle(userID, id, version int64) (*models.Subtask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	st, ok := r.s.subtask(userID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if version != 0 && version != st.Version {
+		return nil, ErrConflict
+	}
+	st.Completed = !st.Completed
+	st.Version++
+	r.s.subtasks[id] = st
+	return &st, nil
+}
+
+func (r *memorySubtaskRepository) Delete(userID, id, version int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	st, ok := r.s.subtask(userID, id)
+	if version != 0 {
+		if !ok {
+			return ErrNotFound
+		}
+		if st.Version != version {
+			return ErrConflict
+		}
+	}
+	if ok {
+		now := r.s.now()
+		st.DeletedAt = &now
+		r.s.subtasks[id] = st
+	}
+	return nil
+}
+
+type memoryCategoryRepo struct{ s *MemoryStore }
+
+func NewMemoryCategoryRepository(s *MemoryStore) CategoryRepository {
+	return &memoryCategoryRepo{s: s}
+}
+
+// withCount returns c with TaskCount set. Callers hold mu.
+func (s *MemoryStore) withCount(c models.Category) models.Category {
+	c.ParentID = clonePtr(c.ParentID)
+	c.TaskCount = 0
+	for _, t := range s.tasks {
+		if t.CategoryID != nil && *t.CategoryID == c.ID && t.DeletedAt == nil && t.ArchivedAt == nil {
+			c.TaskCount++
+		}
+	}
+	return c
+}
+
+// children returns the live children of the user's category parentID, nil
+// for the top level, in order. Callers hold mu.
+func (s *MemoryStore) children(userID int64, parentID *int64) []models.Category {
+	var res []models.Category
+	for _, c := range s.categories {
+		if c.UserID == userID && c.DeletedAt == nil && sameID(c.ParentID, parentID) {
+			res = append(res, c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Position != res[j].Position {
+			return res[i].Position < res[j].Position
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+func sameID(a, b *int64) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func (s *MemoryStore) nextPosition(userID int64, parentID *int64) int {
+	if cs := s.children(userID, parentID); len(cs) > 0 {
+		return cs[len(cs)-1].Position + 1
+	}
+	return 0
+}
+
+// renumber sets the positions of cs to their indexes.
+func (s *MemoryStore) renumber(cs []models.Category) {
+	for i, c := range cs {
+		c = s.categories[c.ID]
+		c.Position = i
+		s.categories[c.ID] = c
+	}
+}
+
+func (r *memoryCategoryRepo) Create(c models.Category) (models.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c.ID = r.s.nextID()
+	c.ParentID = clonePtr(c.ParentID)
+	c.Position = r.s.nextPosition(c.UserID, c.ParentID)
+	c.Version = 1
+	c.TaskCount, c.TotalCount, c.DeletedAt = 0, 0, nil
+	r.s.categories[c.ID] = c
+	return r.s.withCount(c), nil
+}
+
+func (r *memoryCategoryRepo) Get(userID, id int64) (models.Category, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	c, ok := r.s.category(userID, id)
+	if !ok {
+		return models.Category{}, ErrNotFound
+	}
+	return r.s.withCount(c), nil
+}
+
+func (r *memoryCategoryRepo) Update(c models.Category) (models.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.category(c.UserID, c.ID)
+	if !ok {
+		return models.Category{}, ErrNotFound
+	}
+	if c.Version != 0 && c.Version != cur.Version {
+		return models.Category{}, ErrConflict
+	}
+	cur.Name, cur.Color, cur.Icon = c.Name, c.Color, c.Icon
+	cur.Version++
+	r.s.categories[c.ID] = cur
+	return r.s.withCount(cur), nil
+}
+
+func (r *memoryCategoryRepo) Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c, ok := r.s.category(userID, id)
+	if !ok {
+		return models.Category{}, ErrNotFound
+	}
+	if version != 0 && version != c.Version {
+		return models.Category{}, ErrConflict
+	}
+	from := c.ParentID
+	c.ParentID = clonePtr(parentID)
+	c.Version++
+	r.s.categories[id] = c
+	cs := slices.DeleteFunc(r.s.children(userID, parentID), func(s models.Category) bool { return s.ID == id })
+	r.s.renumber(slices.Insert(cs, min(max(position, 0), len(cs)), c))
+	if !sameID(from, parentID) {
+		r.s.renumber(r.s.children(userID, from))
+	}
+	return r.s.withCount(r.s.categories[id]), nil
+}
+
+func (r *memoryCategoryRepo) List(userID int64) ([]models.Category, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var res []models.Category
+	for _, c := range r.s.categories {
+		if c.UserID == userID && c.DeletedAt == nil {
+			res = append(res, r.s.withCount(c))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Position != res[j].Position {
+			return res[i].Position < res[j].Position
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *memoryCategoryRepo) Delete(userID, id int64, tasks string, moveTo *int64, version int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c, ok := r.s.category(userID, id)
+	if !ok {
+		if version != 0 {
+			return ErrNotFound
+		}
+		return nil
+	}
+	if version != 0 && version != c.Version {
+		return ErrConflict
+	}
+	switch tasks {
+	case models.CategoryMoveTasks:
+		if err := r.s.checkCategory(moveTo); err != nil {
+			return err
+		}
+	case models.CategoryClearTasks, models.CategoryDeleteTasks:
+		moveTo = nil
+	default:
+		return fmt.Errorf("unknown category deletion %q", tasks)
+	}
+	now := r.s.now()
+	c.DeletedAt = &now
+	r.s.categories[id] = c
+	for tid, t := range r.s.tasks {
+		if t.UserID != userID || t.DeletedAt != nil || t.CategoryID == nil || *t.CategoryID != id {
+			continue
+		}
+		if tasks == models.CategoryDeleteTasks {
+			t.DeletedAt = &now
+		} else {
+			t.CategoryID = clonePtr(moveTo)
+		}
+		t.Version++
+		r.s.tasks[tid] = t
+	}
+	for sid, ts := range r.s.series {
+		if ts.UserID == userID && ts.CategoryID != nil && *ts.CategoryID == id {
+			ts.CategoryID = clonePtr(moveTo)
+			r.s.series[sid] = ts
+		}
+	}
+	next := r.s.nextPosition(userID, c.ParentID)
+	for _, child := range r.s.children(userID, &id) {
+		child.ParentID = clonePtr(c.ParentID)
+		child.Position += next
+		r.s.categories[child.ID] = child
+	}
+	return nil
+}
+
+type memoryViewRepo struct{ s *MemoryStore }
+
+func NewMemoryViewRepository(s *MemoryStore) ViewRepository {
+	return &memoryViewRepo{s: s}
+}
+
+// cloneView copies the filter through JSON, as the SQL repositories store it.
+func cloneView(v models.SavedView) (*models.SavedView, error) {
+	b, err := json.Marshal(v.Filter)
+	if err != nil {
+		return nil, err
+	}
+	v.Filter = models.TaskFilter{}
+	if err := json.Unmarshal(b, &v.Filter); err != nil {
+		return nil, err
+	}
+	v.Filter.UserID = v.UserID
+	return &v, nil
+}
+
+func (r *memoryViewRepo) List(userID int64) ([]models.SavedView, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var res []models.SavedView
+	for _, v := range r.s.views {
+		if v.UserID == userID {
+			c, err := cloneView(v)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, *c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Position != res[j].Position {
+			return res[i].Position < res[j].Position
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *memoryViewRepo) Get(userID, id int64) (*models.SavedView, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	v, ok := r.s.views[id]
+	if !ok || v.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return cloneView(v)
+}
+
+func (r *memoryViewRepo) Create(v *models.SavedView) (*models.SavedView, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, err := cloneView(*v)
+	if err != nil {
+		return nil, err
+	}
+	stored.ID, stored.Position, stored.BuiltIn = r.s.nextID(), 0, false
+	for _, other := range r.s.views {
+		if other.UserID == v.UserID && other.Position >= stored.Position {
+			stored.Position = other.Position + 1
+		}
+	}
+	r.s.views[stored.ID] = *stored
+	return cloneView(*stored)
+}
+
+func (r *memoryViewRepo) Update(v *models.SavedView) (*models.SavedView, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.views[v.ID]
+	if !ok || cur.UserID != v.UserID {
+		return nil, ErrNotFound
+	}
+	cur.Name, cur.Filter = v.Name, v.Filter
+	stored, err := cloneView(cur)
+	if err != nil {
+		return nil, err
+	}
+	r.s.views[cur.ID] = *stored
+	return cloneView(*stored)
+}
+
+func (r *memoryViewRepo) Reorder(userID int64, ids []int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, id := range ids {
+		if v, ok := r.s.views[id]; !ok || v.UserID != userID {
+			return ErrNotFound
+		}
+	}
+	for i, id := range ids {
+		v := r.s.views[id]
+		v.Position = i
+		r.s.views[id] = v
+	}
+	return nil
+}
+
+func (r *memoryViewRepo) Delete(userID, id int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if v, ok := r.s.views[id]; !ok || v.UserID != userID {
+		return ErrNotFound
+	}
+	delete(r.s.views, id)
+	return nil
+}
+
+type memoryStatsRepo struct{ s *MemoryStore }
+
+func NewMemoryStatsRepository(s *MemoryStore) StatsRepository {
+	return &memoryStatsRepo{s: s}
+}
+
+func (r *memoryStatsRepo) Snapshot(userID int64) (StatsSnapshot, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := r.s.now()
+	var st StatsSnapshot
+	for _, t := range r.s.tasks {
+		if t.UserID != userID || t.DeletedAt != nil {
+			continue
+		}
+		st.Total++
+		if t.Completed {
+			st.Completed++
+		} else {
+			st.Active++
+			if t.DueDate != nil && t.DueDate.Before(now) {
+				st.Overdue++
+			}
+		}
+	}
+	r.s.seedPriorities(userID)
+	st.Priorities = r.s.listPriorities(userID)
+	return st, nil
+}
+
+type memoryTagRepo struct{ s *MemoryStore }
+
+func NewMemoryTagRepository(s *MemoryStore) TagRepository {
+	return &memoryTagRepo{s: s}
+}
+
+func (r *memoryTagRepo) All(userID int64) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range r.s.tasks {
+		if t.UserID != userID || t.DeletedAt != nil {
+			continue
+		}
+		for _, tag := range t.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				out = append(out, tag)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// tag finds the user's tag named name ignoring case; callers hold mu.
+func (s *MemoryStore) tag(userID int64, name string) (models.Tag, bool) {
+	for _, t := range s.tags {
+		if t.UserID == userID && strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return models.Tag{}, false
+}
+
+// registerTags mirrors the SQL registerTags; callers hold mu.
+func (s *MemoryStore) registerTags(userID int64, names []string) []string {
+	res := []string{}
+	for _, n := range names {
+		t, ok := s.tag(userID, n)
+		if !ok {
+			t = models.Tag{ID: s.nextID(), UserID: userID, Name: n, Version: 1}
+			s.tags[t.ID] = t
+		}
+		if !hasTagFold(res, t.Name) {
+			res = append(res, t.Name)
+		}
+	}
+	return res
+}
+
+// retag mirrors the SQL retag; callers hold mu.
+func (s *MemoryStore) retag(userID int64, from, to string) {
+	replace := func(tags []string) ([]string, bool) {
+		if !slices.Contains(tags, from) {
+			return tags, false
+		}
+		res := []string{}
+		for _, t := range tags {
+			if t == from {
+				t = to
+			}
+			if t != "" && !slices.Contains(res, t) {
+				res = append(res, t)
+			}
+		}
+		return res, true
+	}
+	for id, t := range s.tasks {
+		if t.UserID != userID {
+			continue
+		}
+		if tags, ok := replace(t.Tags); ok {
+			t.Tags = tags
+			t.Version++
+			s.tasks[id] = t
+		}
+	}
+	for id, ts := range s.series {
+		if ts.UserID != userID {
+			continue
+		}
+		if tags, ok := replace(ts.Tags); ok {
+			ts.Tags = tags
+			s.series[id] = ts
+		}
+	}
+}
+
+// countTag sets t.Count; callers hold mu.
+func (s *MemoryStore) countTag(t models.Tag) models.Tag {
+	t.Count = 0
+	for _, task := range s.tasks {
+		if task.UserID == t.UserID && task.DeletedAt == nil && slices.Contains(task.Tags, t.Name) {
+			t.Count++
+		}
+	}
+	return t
+}
+
+func (r *memoryTagRepo) List(userID int64) ([]models.Tag, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var res []models.Tag
+	for _, t := range r.s.tags {
+		if t.UserID == userID {
+			res = append(res, r.s.countTag(t))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := strings.ToLower(res[i].Name), strings.ToLower(res[j].Name)
+		if a != b {
+			return a < b
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res, nil
+}
+
+func (r *memoryTagRepo) Get(userID, id int64) (models.Tag, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	t, ok := r.s.tags[id]
+	if !ok || t.UserID != userID {
+		return models.Tag{}, ErrNotFound
+	}
+	return r.s.countTag(t), nil
+}
+
+func (r *memoryTagRepo) GetByName(userID int64, name string) (models.Tag, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	t, ok := r.s.tag(userID, name)
+	if !ok {
+		return models.Tag{}, ErrNotFound
+	}
+	return r.s.countTag(t), nil
+}
+
+func (r *memoryTagRepo) Create(t models.Tag) (models.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.tag(t.UserID, t.Name); ok {
+		return models.Tag{}, errDuplicateTag
+	}
+	t.ID, t.Count, t.Version = r.s.nextID(), 0, 1
+	r.s.tags[t.ID] = t
+	return t, nil
+}
+
+func (r *memoryTagRepo) Update(t models.Tag) (models.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.tags[t.ID]
+	if !ok || cur.UserID != t.UserID {
+		return models.Tag{}, ErrNotFound
+	}
+	if t.Version != 0 && t.Version != cur.Version {
+		return models.Tag{}, ErrConflict
+	}
+	if other, ok := r.s.tag(t.UserID, t.Name); ok && other.ID != t.ID {
+		return models.Tag{}, errDuplicateTag
+	}
+	if cur.Name != t.Name {
+		r.s.retag(t.UserID, cur.Name, t.Name)
+	}
+	cur.Name, cur.Color = t.Name, t.Color
+	cur.Version++
+	r.s.tags[t.ID] = cur
+	return r.s.countTag(cur), nil
+}
+
+func (r *memoryTagRepo) Merge(userID, from, into, version int64) (models.Tag, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	src, ok := r.s.tags[from]
+	dst, ok2 := r.s.tags[into]
+	if !ok || !ok2 || src.UserID != userID || dst.UserID != userID {
+		return models.Tag{}, ErrNotFound
+	}
+	if version != 0 && version != src.Version {
+		return models.Tag{}, ErrConflict
+	}
+	r.s.retag(userID, src.Name, dst.Name)
+	delete(r.s.tags, from)
+	dst.Version++
+	r.s.tags[into] = dst
+	return r.s.countTag(dst), nil
+}
+
+func (r *memoryTagRepo) Delete(userID, id, version int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	t, ok := r.s.tags[id]
+	ok = ok && t.UserID == userID
+	if version != 0 {
+		if !ok {
+			return ErrNotFound
+		}
+		if t.Version != version {
+			return ErrConflict
+		}
+	}
+	if ok {
+		r.s.retag(userID, t.Name, "")
+		delete(r.s.tags, id)
+	}
+	return nil
+}
+
+type memoryAuthRepo struct{ s *MemoryStore }
+
+func NewMemoryAuthRepository(s *MemoryStore) AuthRepository {
+	return &memoryAuthRepo{s: s}
+}
+
+func (s *MemoryStore) createUser(email, passwordHash string) (models.User, error) {
+	for _, u := range s.users {
+		if u.Email == email {
+			return models.User{}, errDuplicateEmail
+		}
+	}
+	u := models.User{ID: s.nextID(), Email: email, PasswordHash: passwordHash, CreatedAt: s.now()}
+	s.users[u.ID] = u
+	s.seedPriorities(u.ID)
+	return u, nil
+}
+
+func (s *MemoryStore) userByEmail(email string) (models.User, bool) {
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, true
+		}
+	}
+	return models.User{}, false
+}
+
+func (r *memoryAuthRepo) CreateUser(email, passwordHash string) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.s.createUser(email, passwordHash)
+}
+
+func (r *memoryAuthRepo) GetUserByEmail(email string) (models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	u, _ := r.s.userByEmail(email)
+	return u, nil
+}
+
+func (r *memoryAuthRepo) GetUserByID(id int64) (models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.s.users[id], nil
+}
+
+func (r *memoryAuthRepo) ClaimLegacyUser(email, passwordHash string) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var legacy models.User
+	for _, u := range r.s.users {
+		if u.PasswordHash != "" {
+			return models.User{}, nil
+		}
+		if legacy.ID == 0 || u.ID < legacy.ID {
+			legacy = u
+		}
+	}
+	if legacy.ID == 0 {
+		return models.User{}, nil
+	}
+	legacy.Email = email
+	legacy.PasswordHash = passwordHash
+	r.s.users[legacy.ID] = legacy
+	return legacy, nil
+}
+
+type memoryTokenRepo struct{ s *MemoryStore }
+
+func NewMemoryTokenRepository(s *MemoryStore) TokenRepository {
+	return &memoryTokenRepo{s: s}
+}
+
+func (r *memoryTokenRepo) create(userID int64, tokenHash string, expiresAt time.Time, sessionID int64) (models.RefreshToken, error) {
+	for _, t := range r.s.tokens {
+		if t.TokenHash == tokenHash {
+			return models.RefreshToken{}, errors.New("duplicate refresh token hash")
+		}
+	}
+	t := models.RefreshToken{ID: r.s.nextID(), UserID: userID, TokenHash: tokenHash, CreatedAt: r.s.now(), ExpiresAt: expiresAt, SessionID: sessionID}
+	if t.SessionID == 0 {
+		t.SessionID = t.ID
+	}
+	r.s.tokens[t.ID] = t
+	return t, nil
+}
+
+func (r *memoryTokenRepo) Create(userID int64, tokenHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.create(userID, tokenHash, expiresAt, 0)
+}
+
+func (r *memoryTokenRepo) GetByHash(tokenHash string) (models.RefreshToken, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, t := range r.s.tokens {
+		if t.TokenHash == tokenHash {
+			return cloneRefreshToken(t), nil
+		}
+	}
+	return models.RefreshToken{}, nil
+}
+
+func (r *memoryTokenRepo) Rotate(old models.RefreshToken, newHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, ok := r.s.tokens[old.ID]
+	if !ok || cur.RevokedAt != nil {
+		return models.RefreshToken{}, ErrTokenRevoked
+	}
+	next, err := r.create(old.UserID, newHash, expiresAt, old.SessionID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	cur.RevokedAt = &next.CreatedAt
+	cur.ReplacedBy = &next.ID
+	r.s.tokens[cur.ID] = cur
+	return next, nil
+}
+
+func (r *memoryTokenRepo) revoke(match func(models.RefreshToken) bool) {
+	now := r.s.now()
+	for id, t := range r.s.tokens {
+		if t.RevokedAt == nil && match(t) {
+			t.RevokedAt = &now
+			r.s.tokens[id] = t
+		}
+	}
+}
+
+func (r *memoryTokenRepo) Revoke(tokenHash string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.revoke(func(t models.RefreshToken) bool { return t.TokenHash == tokenHash })
+	return nil
+}
+
+func (r *memoryTokenRepo) RevokeAll(userID int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.revoke(func(t models.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+type memoryUserRepository struct{ s *MemoryStore }
+
+func NewMemoryUserRepository(s *MemoryStore) UserRepository {
+	return &memoryUserRepository{s: s}
+}
+
+func (r *memoryUserRepository) GetByEmail(email string) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	u, ok := r.s.userByEmail(email)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepository) GetByID(id int64) (*models.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	u, ok := r.s.users[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepository) Create(user *models.User) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	u, err := r.s.createUser(user.Email, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = u.ID
+	user.CreatedAt = u.CreatedAt
+	return user, nil
+}
+
+type memorySeriesRepository struct{ s *MemoryStore }
+
+func NewMemorySeriesRepository(s *MemoryStore) SeriesRepository {
+	return &memorySeriesRepository{s: s}
+}
+
+func (r *memorySeriesRepository) Create(series *models.TaskSeries) (*models.TaskSeries, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.s.checkCategory(series.CategoryID); err != nil {
+		return nil, err
+	}
+	ts := cloneSeries(*series)
+	ts.ID = r.s.nextID()
+	ts.CreatedAt = r.s.now()
+	r.s.series[ts.ID] = ts
+	res := cloneSeries(ts)
+	return &res, nil
+}
+
+func (r *memorySeriesRepository) get(userID, id int64) (*models.TaskSeries, error) {
+	ts, ok := r.s.series[id]
+	if !ok || ts.UserID != userID {
+		return nil, ErrNotFound
+	}
+	res := cloneSeries(ts)
+	return &res, nil
+}
+
+func (r *memorySeriesRepository) Get(userID, id int64) (*models.TaskSeries, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	return r.get(userID, id)
+}
+
+func (r *memorySeriesRepository) Update(series *models.TaskSeries) (*models.TaskSeries, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, err := r.get(series.UserID, series.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.s.checkCategory(series.CategoryID); err != nil {
+		return nil, err
+	}
+	ts := cloneSeries(*series)
+	ts.CreatedAt = cur.CreatedAt
+	r.s.series[ts.ID] = ts
+	return r.get(ts.UserID, ts.ID)
+}
+
+func (r *memorySeriesRepository) Skip(userID, taskID, version int64, next *models.Task) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	t, ok := r.s.task(userID, taskID)
+	if !ok || t.SeriesID == nil || t.OccurrenceAt == nil {
+		return ErrNotFound
+	}
+	if version != 0 && version != t.Version {
+		return ErrConflict
+	}
+	for _, at := range r.s.skips[*t.SeriesID] {
+		if at.Equal(*t.OccurrenceAt) {
+			return errors.New("duplicate key value violates unique constraint on series_skips")
+		}
+	}
+	r.s.skips[*t.SeriesID] = append(r.s.skips[*t.SeriesID], *t.OccurrenceAt)
+	tasks := &memoryTaskRepository{s: r.s}
+	tasks.delete(userID, func(t models.Task) bool { return t.ID != taskID })
+	if next != nil {
+		tasks.create(next)
+	}
+	return nil
+}
+
+func (r *memorySeriesRepository) History(userID, seriesID int64) ([]models.SeriesOccurrence, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var res []models.SeriesOccurrence
+	for _, t := range r.s.tasks {
+		if t.UserID != userID || t.DeletedAt != nil || t.SeriesID == nil || *t.SeriesID != seriesID || t.OccurrenceAt == nil {
+			continue
+		}
+		o := models.SeriesOccurrence{
+			OccurrenceAt: *t.OccurrenceAt,
+			Status:       models.OccurrenceOpen,
+			TaskID:       clonePtr(&t.ID),
+			Title:        t.Title,
+			DueDate:      clonePtr(t.DueDate),
+			CompletedAt:  clonePtr(t.CompletedAt),
+		}
+		if t.Completed {
+			o.Status = models.OccurrenceCompleted
+		}
+		res = append(res, o)
+	}
+	if ts, ok := r.s.series[seriesID]; ok && ts.UserID == userID {
+		for _, at := range r.s.skips[seriesID] {
+			res = append(res, models.SeriesOccurrence{OccurrenceAt: at, Status: models.OccurrenceSkipped})
+		}
+	}
+	sortOccurrences(res)
+	return res, nil
+}
+
+type memoryTrashRepository struct{ s *MemoryStore }
+
+func NewMemoryTrashRepository(s *MemoryStore) TrashRepository {
+	return &memoryTrashRepository{s: s}
+}
+
+// trashedSubtask is the memory counterpart of the SQL trashedSubtask.
+func (s *MemoryStore) trashedSubtask(st models.Subtask) bool {
+	t, ok := s.tasks[st.TaskID]
+	return st.DeletedAt != nil && ok && t.DeletedAt == nil
+}
+
+// newestDeleted orders by deleted_at desc, id desc.
+func newestDeleted(a, b *time.Time, aID, bID int64) bool {
+	if !a.Equal(*b) {
+		return a.After(*b)
+	}
+	return aID > bID
+}
+
+func (r *memoryTrashRepository) List(userID int64) (models.Trash, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var res models.Trash
+	for _, t := range r.s.tasks {
+		if t.UserID == userID && t.DeletedAt != nil {
+			res.Tasks = append(res.Tasks, cloneTask(t))
+		}
+	}
+	sort.Slice(res.Tasks, func(i, j int) bool {
+		a, b := res.Tasks[i], res.Tasks[j]
+		return newestDeleted(a.DeletedAt, b.DeletedAt, a.ID, b.ID)
+	})
+	for _, st := range r.s.subtasks {
+		if st.UserID == userID && r.s.trashedSubtask(st) {
+			st.DeletedAt = clonePtr(st.DeletedAt)
+			res.Subtasks = append(res.Subtasks, st)
+		}
+	}
+	sort.Slice(res.Subtasks, func(i, j int) bool {
+		a, b := res.Subtasks[i], res.Subtasks[j]
+		return newestDeleted(a.DeletedAt, b.DeletedAt, a.ID, b.ID)
+	})
+	for _, c := range r.s.categories {
+		if c.UserID == userID && c.DeletedAt != nil {
+			c.DeletedAt = clonePtr(c.DeletedAt)
+			res.Categories = append(res.Categories, r.s.withCount(c))
+		}
+	}
+	sort.Slice(res.Categories, func(i, j int) bool {
+		a, b := res.Categories[i], res.Categories[j]
+		return newestDeleted(a.DeletedAt, b.DeletedAt, a.ID, b.ID)
+	})
+	return res, nil
+}
+
+func (r *memoryTrashRepository) RestoreTask(userID, id int64) (*models.Task, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	t, ok := r.s.tasks[id]
+	if !ok || t.UserID != userID || t.DeletedAt == nil {
+		return nil, ErrNotFound
+	}
+	t.DeletedAt = nil
+	t.Version++
+	r.s.tasks[id] = t
+	t = cloneTask(t)
+	return &t, nil
+}
+
+func (r *memoryTrashRepository) RestoreSubtask(userID, id int64) (*models.Subtask, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	st, ok := r.s.subtasks[id]
+	if !ok || st.UserID != userID || !r.s.trashedSubtask(st) {
+		return nil, ErrNotFound
+	}
+	st.DeletedAt = nil
+	st.Version++
+	r.s.subtasks[id] = st
+	return &st, nil
+}
+
+func (r *memoryTrashRepository) RestoreCategory(userID, id int64) (models.Category, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	c, ok := r.s.categories[id]
+	if !ok || c.UserID != userID || c.DeletedAt == nil {
+		return models.Category{}, ErrNotFound
+	}
+	c.DeletedAt = nil
+	c.Version++
+	if c.ParentID != nil {
+		if _, ok := r.s.category(userID, *c.ParentID); !ok {
+			c.ParentID = nil
+		}
+	}
+	c.Position = r.s.nextPosition(userID, c.ParentID)
+	r.s.categories[id] = c
+	return r.s.withCount(c), nil
+}
+
+// purge deletes the trashed rows for which match is true, with the subtasks
+// of purged tasks.
+func (r *memoryTrashRepository) purge(match func(userID int64, deletedAt time.Time) bool) int64 {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var n int64
+	for id, st := range r.s.subtasks {
+		if st.DeletedAt != nil && match(st.UserID, *st.DeletedAt) {
+			delete(r.s.subtasks, id)
+			n++
+		}
+	}
+	for id, t := range r.s.tasks {
+		if t.DeletedAt == nil || !match(t.UserID, *t.DeletedAt) {
+			continue
+		}
+		delete(r.s.tasks, id)
+		for sid, st := range r.s.subtasks {
+			if st.TaskID == id {
+				delete(r.s.subtasks, sid)
+			}
+		}
+		n++
+	}
+	for id, c := range r.s.categories {
+		if c.DeletedAt != nil && match(c.UserID, *c.DeletedAt) {
+			delete(r.s.categories, id)
+			r.s.clearCategory(id)
+			n++
+		}
+	}
+	return n
+}
+
+func (r *memoryTrashRepository) Purge(userID int64) (int64, error) {
+	return r.purge(func(uid int64, _ time.Time) bool { return uid == userID }), nil
+}
+
+func (r *memoryTrashRepository) PurgeBefore(t time.Time) (int64, error) {
+	return r.purge(func(_ int64, deletedAt time.Time) bool { return deletedAt.Before(t) }), nil
+}
+
+type memoryPriorityRepo struct{ s *MemoryStore }
+
+func NewMemoryPriorityRepository(s *MemoryStore) PriorityRepository {
+	return &memoryPriorityRepo{s: s}
+}
+
+// seedPriorities gives the user the default scale unless they have levels,
+// as the SQL trigger does for every new user. Users the store never created
+// are seeded on first use. Callers hold mu.
+func (s *MemoryStore) seedPriorities(userID int64) {
+	for _, p := range s.priorities {
+		if p.UserID == userID {
+			return
+		}
+	}
+	for _, p := range models.DefaultPriorities {
+		p.ID, p.UserID, p.Version = s.nextID(), userID, 1
+		s.priorities[p.ID] = p
+	}
+}
+
+// levels returns the user's levels, or the default scale for a user not yet
+// seeded; callers hold mu.
+func (s *MemoryStore) levels(userID int64) []models.Priority {
+	var res []models.Priority
+	for _, p := range s.priorities {
+		if p.UserID == userID {
+			res = append(res, p)
+		}
+	}
+	if res == nil {
+		return models.DefaultPriorities
+	}
+	return res
+}
+
+// priorityWeight mirrors the SQL priorityWeight, with -1 for a task at no
+// level; callers hold mu.
+func (s *MemoryStore) priorityWeight(t models.Task) int {
+	for _, p := range s.levels(t.UserID) {
+		if p.Name == t.Priority {
+			return p.Weight
+		}
+	}
+	return -1
+}
+
+// countPriority sets p.Count; callers hold mu.
+func (s *MemoryStore) countPriority(p models.Priority) models.Priority {
+	p.Count = 0
+	for _, t := range s.tasks {
+		if t.UserID == p.UserID && t.DeletedAt == nil && t.Priority == p.Name {
+			p.Count++
+		}
+	}
+	return p
+}
+
+// listPriorities mirrors the SQL listPriorities; callers hold mu.
+func (s *MemoryStore) listPriorities(userID int64) []models.Priority {
+	var res []models.Priority
+	for _, p := range s.priorities {
+		if p.UserID == userID {
+			res = append(res, s.countPriority(p))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Weight != res[j].Weight {
+			return res[i].Weight > res[j].Weight
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// takeDefault mirrors the SQL takeDefault; callers hold mu.
+func (s *MemoryStore) takeDefault(userID, id int64) {
+	for pid, p := range s.priorities {
+		if p.UserID == userID && pid != id && p.Default {
+			p.Default = false
+			p.Version++
+			s.priorities[pid] = p
+		}
+	}
+}
+
+// reprioritize mirrors the SQL reprioritize; callers hold mu.
+func (s *MemoryStore) reprioritize(userID int64, from, to string) {
+	for id, t := range s.tasks {
+		if t.UserID == userID && t.Priority == from {
+			t.Priority = to
+			t.Version++
+			s.tasks[id] = t
+		}
+	}
+	for id, ts := range s.series {
+		if ts.UserID == userID && ts.Priority == from {
+			ts.Priority = to
+			s.series[id] = ts
+		}
+	}
+}
+
+func (r *memoryPriorityRepo) priority(userID, id int64) (models.Priority, error) {
+	r.s.seedPriorities(userID)
+	p, ok := r.s.priorities[id]
+	if !ok || p.UserID != userID {
+		return models.Priority{}, ErrNotFound
+	}
+	return r.s.countPriority(p), nil
+}
+
+func (r *memoryPriorityRepo) List(userID int64) ([]models.Priority, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.seedPriorities(userID)
+	return r.s.listPriorities(userID), nil
+}
+
+func (r *memoryPriorityRepo) Get(userID, id int64) (models.Priority, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return r.priority(userID, id)
+}
+
+func (r *memoryPriorityRepo) Create(p models.Priority) (models.Priority, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.seedPriorities(p.UserID)
+	for _, o := range r.s.priorities {
+		if o.UserID == p.UserID && o.Name == p.Name {
+			return models.Priority{}, errDuplicatePriority
+		}
+	}
+	if p.Default {
+		r.s.takeDefault(p.UserID, 0)
+	}
+	p.ID, p.Count, p.Version = r.s.nextID(), 0, 1
+	r.s.priorities[p.ID] = p
+	return r.s.countPriority(p), nil
+}
+
+func (r *memoryPriorityRepo) Update(p models.Priority) (models.Priority, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, err := r.priority(p.UserID, p.ID)
+	if err != nil {
+		return models.Priority{}, err
+	}
+	if p.Version != 0 && p.Version != cur.Version {
+		return models.Priority{}, ErrConflict
+	}
+	for _, o := range r.s.priorities {
+		if o.UserID == p.UserID && o.ID != p.ID && o.Name == p.Name {
+			return models.Priority{}, errDuplicatePriority
+		}
+	}
+	if p.Default {
+		r.s.takeDefault(p.UserID, p.ID)
+	}
+	if cur.Name != p.Name {
+		r.s.reprioritize(p.UserID, cur.Name, p.Name)
+	}
+	p.Version = cur.Version + 1
+	r.s.priorities[p.ID] = p
+	return r.s.countPriority(p), nil
+}
+
+func (r *memoryPriorityRepo) Delete(userID, id, moveTo, version int64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cur, err := r.priority(userID, id)
+	if errors.Is(err, ErrNotFound) && version == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != cur.Version {
+		return ErrConflict
+	}
+	to, err := r.priority(userID, moveTo)
+	if err != nil {
+		return err
+	}
+	r.s.reprioritize(userID, cur.Name, to.Name)
+	delete(r.s.priorities, id)
+	if cur.Default {
+		to.Default = true
+		to.Version++
+		r.s.priorities[moveTo] = to
+	}
+	return nil
+}
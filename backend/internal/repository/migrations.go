@@ -1,43 +0,0 @@
-package repository
-
-import (
-	"database/sql"
-)
-
-func RunMigrations(db *sql.DB) error {
-	_, err := db.Exec(`
-CREATE TABLE IF NOT EXISTS users (
-  id BIGSERIAL PRIMARY KEY,
-  email TEXT UNIQUE NOT NULL,
-  password TEXT NOT NULL,
-  created_at TIMESTAMP NOT NULL DEFAULT NOW()
-);
-CREATE TABLE IF NOT EXISTS categories (
-  id BIGSERIAL PRIMARY KEY,
-  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-  name TEXT NOT NULL
-);
-CREATE TABLE IF NOT EXISTS tasks (
-  id BIGSERIAL PRIMARY KEY,
-  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-  title TEXT NOT NULL,
-  description TEXT NOT NULL DEFAULT '',
-  priority TEXT NOT NULL DEFAULT 'medium',
-  completed BOOLEAN NOT NULL DEFAULT FALSE,
-  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-  due_date TIMESTAMP NULL,
-  completed_at TIMESTAMP NULL,
-  repeat_rule TEXT NULL,
-  category_id BIGINT NULL REFERENCES categories(id) ON DELETE SET NULL
-);
-CREATE TABLE IF NOT EXISTS task_tags (
-  task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
-  tag TEXT NOT NULL,
-  PRIMARY KEY(task_id, tag)
-);
-CREATE INDEX IF NOT EXISTS idx_tasks_user ON tasks(user_id);
-CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(user_id, completed);
-CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks(user_id, due_date);
-`)
-	return err
-}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"todo-app/backend/internal/models"
+)
+
+// PriorityRepository manages the user's priority scale. Names are unique per
+// user and one level is the default. Tasks and series hold the name of their
+// level.
+type PriorityRepository interface {
+	// List returns the user's levels, highest weight first, with Count set.
+	List(userID int64) ([]models.Priority, error)
+	Get(userID, id int64) (models.Priority, error)
+	// Create adds p; when p is the default it takes that from the previous
+	// default.
+	Create(p models.Priority) (models.Priority, error)
+	// Update renames, relabels and reweighs p, with the same version check
+	// as TaskRepository.Update, and makes it the default like Create. A
+	// rename is applied to every task and series.
+	Update(p models.Priority) (models.Priority, error)
+	// Delete moves the tasks and series at level id to level moveTo, which
+	// also becomes the default when id was, and removes id. It has the same
+	// version check as Update; with version 0 deleting a missing level is
+	// not an error.
+	Delete(userID, id, moveTo, version int64) error
+}
+
+// The SQL is the same in both dialects.
+type priorityRepo struct{ db *sql.DB }
+
+func NewPriorityRepository(db *sql.DB) PriorityRepository { return &priorityRepo{db: db} }
+
+func NewSQLitePriorityRepository(db *sql.DB) PriorityRepository { return &priorityRepo{db: db} }
+
+// priorityWeight is the weight of the level of the task in the current row
+// of tasks, null when the user has no such level.
+const priorityWeight = `(select p.weight from priorities p where p.user_id = tasks.user_id and p.name = tasks.priority)`
+
+const priorityColumns = `id, user_id, name, label, color, weight, is_default, version,
+	(select count(*) from tasks t where t.user_id = priorities.user_id and t.priority = priorities.name and t.deleted_at is null)`
+
+func scanPriority(s scanner) (models.Priority, error) {
+	var p models.Priority
+	err := s.Scan(&p.ID, &p.UserID, &p.Name, &p.Label, &p.Color, &p.Weight, &p.Default, &p.Version, &p.Count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, ErrNotFound
+	}
+	return p, err
+}
+
+func listPriorities(q querier, userID int64) ([]models.Priority, error) {
+	rows, err := q.Query(`select `+priorityColumns+` from priorities where user_id=$1 order by weight desc, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Priority
+	for rows.Next() {
+		p, err := scanPriority(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
+
+func getPriority(q querier, userID, id int64) (models.Priority, error) {
+	return scanPriority(q.QueryRow(`select `+priorityColumns+` from priorities where id=$1 and user_id=$2`, id, userID))
+}
+
+func (r *priorityRepo) List(userID int64) ([]models.Priority, error) {
+	return listPriorities(r.db, userID)
+}
+
+func (r *priorityRepo) Get(userID, id int64) (models.Priority, error) {
+	return getPriority(r.db, userID, id)
+}
+
+// takeDefault clears the default of the user's levels other than id.
+func takeDefault(q querier, userID, id int64) error {
+	_, err := q.Exec(`update priorities set is_default=false, version=version+1 where user_id=$1 and id<>$2 and is_default`, userID, id)
+	return err
+}
+
+func (r *priorityRepo) Create(p models.Priority) (models.Priority, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Priority{}, err
+	}
+	defer tx.Rollback()
+	if p.Default {
+		if err := takeDefault(tx, p.UserID, 0); err != nil {
+			return models.Priority{}, err
+		}
+	}
+	var id int64
+	if err := tx.QueryRow(`insert into priorities (user_id, name, label, color, weight, is_default)
+		values ($1,$2,$3,$4,$5,$6) returning id`, p.UserID, p.Name, p.Label, p.Color, p.Weight, p.Default).Scan(&id); err != nil {
+		return models.Priority{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Priority{}, err
+	}
+	return r.Get(p.UserID, id)
+}
+
+// reprioritize moves the user's tasks and series from level name from to
+// level name to.
+func reprioritize(q querier, userID int64, from, to string) error {
+	if _, err := q.Exec(`update tasks set priority=$3, version=version+1 where user_id=$1 and priority=$2`, userID, from, to); err != nil {
+		return err
+	}
+	_, err := q.Exec(`update task_series set priority=$3 where user_id=$1 and priority=$2`, userID, from, to)
+	return err
+}
+
+func (r *priorityRepo) Update(p models.Priority) (models.Priority, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Priority{}, err
+	}
+	defer tx.Rollback()
+	cur, err := getPriority(tx, p.UserID, p.ID)
+	if err != nil {
+		return models.Priority{}, err
+	}
+	if p.Version != 0 && p.Version != cur.Version {
+		return models.Priority{}, ErrConflict
+	}
+	if p.Default {
+		if err := takeDefault(tx, p.UserID, p.ID); err != nil {
+			return models.Priority{}, err
+		}
+	}
+	res, err := tx.Exec(`update priorities set name=$1, label=$2, color=$3, weight=$4, is_default=$5, version=version+1
+		where id=$6 and version=$7`, p.Name, p.Label, p.Color, p.Weight, p.Default, p.ID, cur.Version)
+	if err != nil {
+		return models.Priority{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Priority{}, ErrConflict
+	}
+	if cur.Name != p.Name {
+		if err := reprioritize(tx, p.UserID, cur.Name, p.Name); err != nil {
+			return models.Priority{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Priority{}, err
+	}
+	return r.Get(p.UserID, p.ID)
+}
+
+func (r *priorityRepo) Delete(userID, id, moveTo, version int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	cur, err := getPriority(tx, userID, id)
+	if errors.Is(err, ErrNotFound) && version == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	to, err := getPriority(tx, userID, moveTo)
+	if err != nil {
+		return err
+	}
+	if err := reprioritize(tx, userID, cur.Name, to.Name); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`delete from priorities where id=$1 and (version=$2 or $2 = 0)`, id, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	if cur.Default {
+		if _, err := tx.Exec(`update priorities set is_default=true, version=version+1 where id=$1`, moveTo); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"todo-app/backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type SeriesRepository interface {
+	Create(series *models.TaskSeries) (*models.TaskSeries, error)
+	Get(userID, id int64) (*models.TaskSeries, error)
+	Update(series *models.TaskSeries) (*models.TaskSeries, error)
+	// Skip records the open occurrence taskID as skipped, deletes its task
+	// and, when next is given, inserts the following occurrence, all at once.
+	// version is the task's, with the same check as TaskRepository.Update.
+	Skip(userID, taskID, version int64, next *models.Task) error
+	// History lists the series' occurrences, open, completed and skipped,
+	// in occurrence order.
+	History(userID, seriesID int64) ([]models.SeriesOccurrence, error)
+}
+
+type seriesRepository struct{ db *sql.DB }
+
+func NewSeriesRepository(db *sql.DB) SeriesRepository { return &seriesRepository{db: db} }
+
+const seriesColumns = `id, user_id, title, description, priority, repeat_rule, category_id, tags, subtasks, created_at, ended_at`
+
+func scanSeries(s scanner) (*models.TaskSeries, error) {
+	var (
+		ts         models.TaskSeries
+		categoryID sql.NullInt64
+		tags       pq.StringArray
+		subtasks   pq.StringArray
+		endedAt    sql.NullTime
+	)
+	err := s.Scan(&ts.ID, &ts.UserID, &ts.Title, &ts.Description, &ts.Priority, &ts.RepeatRule,
+		&categoryID, &tags, &subtasks, &ts.CreatedAt, &endedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if categoryID.Valid {
+		v := categoryID.Int64
+		ts.CategoryID = &v
+	}
+	if endedAt.Valid {
+		v := endedAt.Time
+		ts.EndedAt = &v
+	}
+	ts.Tags = nonNilTags(tags)
+	ts.Subtasks = nonNilTags(subtasks)
+	return &ts, nil
+}
+
+func (r *seriesRepository) Create(series *models.TaskSeries) (*models.TaskSeries, error) {
+	return scanSeries(r.db.QueryRow(`
+		insert into task_series (user_id, title, description, priority, repeat_rule, category_id, tags, subtasks)
+		values ($1,$2,$3,$4,$5,$6,$7,$8)
+		returning `+seriesColumns,
+		series.UserID, series.Title, series.Description, series.Priority, series.RepeatRule,
+		series.CategoryID, pq.Array(nonNilTags(series.Tags)), pq.Array(nonNilTags(series.Subtasks)),
+	))
+}
+
+func (r *seriesRepository) Get(userID, id int64) (*models.TaskSeries, error) {
+	return scanSeries(r.db.QueryRow(`select `+seriesColumns+` from task_series where user_id=$1 and id=$2`, userID, id))
+}
+
+func (r *seriesRepository) Update(series *models.TaskSeries) (*models.TaskSeries, error) {
+	return scanSeries(r.db.QueryRow(`
+		update task_series set title=$1, description=$2, priority=$3, repeat_rule=$4, category_id=$5,
+		       tags=$6, subtasks=$7, ended_at=$8
+		where id=$9 and user_id=$10
+		returning `+seriesColumns,
+		series.Title, series.Description, series.Priority, series.RepeatRule, series.CategoryID,
+		pq.Array(nonNilTags(series.Tags)), pq.Array(nonNilTags(series.Subtasks)), series.EndedAt,
+		series.ID, series.UserID,
+	))
+}
+
+func (r *seriesRepository) Skip(userID, taskID, version int64, next *models.Task) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`
+		insert into series_skips (series_id, occurrence_at)
+		select series_id, occurrence_at from tasks
+		where id=$1 and user_id=$2 and series_id is not null and occurrence_at is not null and deleted_at is null
+		  and ($3::bigint = 0 or version=$3)
+	`, taskID, userID, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if version == 0 {
+			return ErrNotFound
+		}
+		return missing(tx, "tasks", userID, taskID)
+	}
+	if _, err := tx.Exec(`delete from tasks where id=$1 and user_id=$2`, taskID, userID); err != nil {
+		return err
+	}
+	if next != nil {
+		if _, err := createTask(tx, next); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *seriesRepository) History(userID, seriesID int64) ([]models.SeriesOccurrence, error) {
+	rows, err := r.db.Query(`
+		select occurrence_at, case when completed then 'completed' else 'open' end, id, title, due_at, completed_at
+		from tasks
+		where user_id=$1 and series_id=$2 and occurrence_at is not null and deleted_at is null
+		union all
+		select k.occurrence_at, 'skipped', null, '', null, null
+		from series_skips k join task_series s on s.id = k.series_id
+		where s.user_id=$1 and k.series_id=$2
+		order by 1, 3
+	`, userID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.SeriesOccurrence
+	for rows.Next() {
+		var (
+			o           models.SeriesOccurrence
+			taskID      sql.NullInt64
+			dueAt       sql.NullTime
+			completedAt sql.NullTime
+		)
+		if err := rows.Scan(&o.OccurrenceAt, &o.Status, &taskID, &o.Title, &dueAt, &completedAt); err != nil {
+			return nil, err
+		}
+		if taskID.Valid {
+			v := taskID.Int64
+			o.TaskID = &v
+		}
+		if dueAt.Valid {
+			v := dueAt.Time
+			o.DueDate = &v
+		}
+		if completedAt.Valid {
+			v := completedAt.Time
+			o.CompletedAt = &v
+		}
+		res = append(res, o)
+	}
+	return res, rows.Err()
+}
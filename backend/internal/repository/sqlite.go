@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// SQLite stores timestamps as text. Every bound time goes through sqliteTime so
+// that stored values share one fixed-width UTC layout and compare correctly as
+// strings; the driver parses the layout back when scanning timestamp columns.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000000-07:00"
+
+func init() {
+	// SQLite's lower() and LIKE only fold ASCII; casefold keeps search
+	// case-insensitive for Cyrillic and other scripts, like ilike on Postgres.
+	sqlite.MustRegisterDeterministicScalarFunction("casefold", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return strings.ToLower(v), nil
+		case []byte:
+			return strings.ToLower(string(v)), nil
+		}
+		return args[0], nil
+	})
+}
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func sqliteNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return sqliteTime(*t)
+}
+
+func localTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.Local()
+	return &v
+}
+
+func encodeTags(tags []string) string {
+	b, _ := json.Marshal(nonNilTags(tags))
+	return string(b)
+}
+
+func decodeTags(s string) ([]string, error) {
+	tags := []string{}
+	if s == "" {
+		return tags, nil
+	}
+	if err := json.Unmarshal([]byte(s), &tags); err != nil {
+		return nil, err
+	}
+	return nonNilTags(tags), nil
+}
+
+// dayBounds and weekBounds reproduce the Postgres current_date and
+// date_trunc('week', ...) filters in the local time zone.
+func dayBounds(now time.Time) (time.Time, time.Time) {
+	y, m, d := now.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+func weekBounds(now time.Time) (time.Time, time.Time) {
+	start, _ := dayBounds(now)
+	offset := (int(start.Weekday()) + 6) % 7
+	start = start.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 7)
+}
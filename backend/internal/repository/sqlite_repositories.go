@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+type sqliteCategoryRepo struct{ db *sql.DB }
+
+func NewSQLiteCategoryRepository(db *sql.DB) CategoryRepository { return &sqliteCategoryRepo{db: db} }
+
+func (r *sqliteCategoryRepo) Create(c models.Category) (models.Category, error) {
+	res, err := r.db.Exec(`INSERT INTO categories(user_id,name,parent_id,color,icon,position,created_at)
+		VALUES($1,$3,$2,$4,$5,`+nextPosition+`,$6)`, c.UserID, c.ParentID, c.Name, c.Color, c.Icon, sqliteTime(time.Now()))
+	if err != nil {
+		return models.Category{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Category{}, err
+	}
+	return r.Get(c.UserID, id)
+}
+
+func (r *sqliteCategoryRepo) Get(userID, id int64) (models.Category, error) {
+	return getCategory(r.db, userID, id)
+}
+
+func (r *sqliteCategoryRepo) List(userID int64) ([]models.Category, error) {
+	return listCategories(r.db, userID)
+}
+
+func (r *sqliteCategoryRepo) Update(c models.Category) (models.Category, error) {
+	res, err := r.db.Exec(`
+		UPDATE categories SET name=$1, color=$2, icon=$3, version=version+1
+		WHERE id=$4 AND user_id=$5 AND deleted_at IS NULL AND ($6 = 0 OR version=$6)`, c.Name, c.Color, c.Icon, c.ID, c.UserID, c.Version)
+	if err != nil {
+		return models.Category{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Category{}, missing(r.db, "categories", c.UserID, c.ID)
+	}
+	return r.Get(c.UserID, c.ID)
+}
+
+func (r *sqliteCategoryRepo) Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Category{}, err
+	}
+	defer tx.Rollback()
+	if err := moveCategory(tx, userID, id, parentID, position, version); err != nil {
+		return models.Category{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Category{}, err
+	}
+	return r.Get(userID, id)
+}
+
+func (r *sqliteCategoryRepo) Delete(userID, id int64, tasks string, moveTo *int64, version int64) error {
+	return deleteCategory(r.db, userID, id, tasks, moveTo, version, sqliteTime(time.Now()))
+}
+
+type sqliteStatsRepo struct{ db *sql.DB }
+
+func NewSQLiteStatsRepository(db *sql.DB) StatsRepository { return &sqliteStatsRepo{db: db} }
+
+func (r *sqliteStatsRepo) Snapshot(userID int64) (StatsSnapshot, error) {
+	var s StatsSnapshot
+	err := r.db.QueryRow(`
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE completed=0),
+		       COUNT(*) FILTER (WHERE completed=1),
+		       COUNT(*) FILTER (WHERE completed=0 AND due_at IS NOT NULL AND due_at < $2)
+		FROM tasks
+		WHERE user_id=$1 AND deleted_at IS NULL
+	`, userID, sqliteTime(time.Now())).Scan(&s.Total, &s.Active, &s.Completed, &s.Overdue)
+	if err != nil {
+		return s, err
+	}
+	s.Priorities, err = listPriorities(r.db, userID)
+	return s, err
+}
+
+type sqliteAuthRepo struct{ db *sql.DB }
+
+func NewSQLiteAuthRepository(db *sql.DB) AuthRepository { return &sqliteAuthRepo{db: db} }
+
+const userColumns = `id, email, password_hash, created_at`
+
+func scanSQLiteUser(s scanner) (models.User, error) {
+	var u models.User
+	err := s.Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, nil
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	u.CreatedAt = u.CreatedAt.Local()
+	return u, nil
+}
+
+func (r *sqliteAuthRepo) CreateUser(email, passwordHash string) (models.User, error) {
+	now := time.Now()
+	res, err := r.db.Exec(`INSERT INTO users (email, password_hash, created_at) VALUES ($1, $2, $3)`, email, passwordHash, sqliteTime(now))
+	if err != nil {
+		return models.User{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.User{}, err
+	}
+	return models.User{ID: id, Email: email, PasswordHash: passwordHash, CreatedAt: now}, nil
+}
+
+func (r *sqliteAuthRepo) GetUserByEmail(email string) (models.User, error) {
+	return scanSQLiteUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+}
+
+func (r *sqliteAuthRepo) GetUserByID(id int64) (models.User, error) {
+	return scanSQLiteUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+}
+
+// ClaimLegacyUser mirrors the Postgres implementation. A fresh SQLite database
+// never has placeholder users, but one copied from an exported Postgres
+// database may.
+func (r *sqliteAuthRepo) ClaimLegacyUser(email, passwordHash string) (models.User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.User{}, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`
+		UPDATE users SET email = $1, password_hash = $2
+		WHERE id = (SELECT MIN(id) FROM users WHERE password_hash = '')
+		  AND NOT EXISTS (SELECT 1 FROM users WHERE password_hash <> '')
+	`, email, passwordHash)
+	if err != nil {
+		return models.User{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.User{}, nil
+	}
+	u, err := scanSQLiteUser(tx.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = $1`, email))
+	if err != nil {
+		return models.User{}, err
+	}
+	return u, tx.Commit()
+}
+
+type sqliteTokenRepo struct{ db *sql.DB }
+
+func NewSQLiteTokenRepository(db *sql.DB) TokenRepository { return &sqliteTokenRepo{db: db} }
+
+func scanSQLiteRefreshToken(s scanner) (models.RefreshToken, error) {
+	var (
+		t          models.RefreshToken
+		revokedAt  sql.NullTime
+		replacedBy sql.NullInt64
+	)
+	if err := s.Scan(&t.ID, &t.UserID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &revokedAt, &replacedBy, &t.SessionID); err != nil {
+		return models.RefreshToken{}, err
+	}
+	t.CreatedAt = t.CreatedAt.Local()
+	t.ExpiresAt = t.ExpiresAt.Local()
+	t.RevokedAt = localTimePtr(revokedAt)
+	if replacedBy.Valid {
+		v := replacedBy.Int64
+		t.ReplacedBy = &v
+	}
+	return t, nil
+}
+
+func createSQLiteRefreshToken(q querier, userID int64, tokenHash string, expiresAt time.Time, sessionID int64) (models.RefreshToken, error) {
+	now := time.Now()
+	res, err := q.Exec(`
+		insert into refresh_tokens (user_id, token_hash, created_at, expires_at, session_id)
+		values ($1,$2,$3,$4,nullif($5, 0))
+	`, userID, tokenHash, sqliteTime(now), sqliteTime(expiresAt), sessionID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	if sessionID == 0 {
+		sessionID = id
+	}
+	return models.RefreshToken{ID: id, UserID: userID, TokenHash: tokenHash, CreatedAt: now, ExpiresAt: expiresAt, SessionID: sessionID}, nil
+}
+
+func (r *sqliteTokenRepo) Create(userID int64, tokenHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	return createSQLiteRefreshToken(r.db, userID, tokenHash, expiresAt, 0)
+}
+
+func (r *sqliteTokenRepo) GetByHash(tokenHash string) (models.RefreshToken, error) {
+	t, err := scanSQLiteRefreshToken(r.db.QueryRow(`select `+refreshTokenColumns+` from refresh_tokens where token_hash=$1`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.RefreshToken{}, nil
+	}
+	return t, err
+}
+
+func (r *sqliteTokenRepo) Rotate(old models.RefreshToken, newHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	defer tx.Rollback()
+	next, err := createSQLiteRefreshToken(tx, old.UserID, newHash, expiresAt, old.SessionID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	res, err := tx.Exec(`update refresh_tokens set revoked_at=$1, replaced_by=$2 where id=$3 and revoked_at is null`, sqliteTime(next.CreatedAt), next.ID, old.ID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.RefreshToken{}, ErrTokenRevoked
+	}
+	return next, tx.Commit()
+}
+
+func (r *sqliteTokenRepo) Revoke(tokenHash string) error {
+	_, err := r.db.Exec(`update refresh_tokens set revoked_at=$1 where token_hash=$2 and revoked_at is null`, sqliteTime(time.Now()), tokenHash)
+	return err
+}
+
+func (r *sqliteTokenRepo) RevokeAll(userID int64) error {
+	_, err := r.db.Exec(`update refresh_tokens set revoked_at=$1 where user_id=$2 and revoked_at is null`, sqliteTime(time.Now()), userID)
+	return err
+}
+
+type sqliteUserRepository struct{ db *sql.DB }
+
+func NewSQLiteUserRepository(db *sql.DB) UserRepository { return &sqliteUserRepository{db: db} }
+
+func (r *sqliteUserRepository) get(query string, arg any) (*models.User, error) {
+	var u models.User
+	if err := r.db.QueryRow(query, arg).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt); err != nil {
+		return nil, err
+	}
+	u.CreatedAt = u.CreatedAt.Local()
+	return &u, nil
+}
+
+func (r *sqliteUserRepository) GetByEmail(email string) (*models.User, error) {
+	return r.get(`select `+userColumns+` from users where email=$1`, email)
+}
+
+func (r *sqliteUserRepository) GetByID(id int64) (*models.User, error) {
+	return r.get(`select `+userColumns+` from users where id=$1`, id)
+}
+
+func (r *sqliteUserRepository) Create(user *models.User) (*models.User, error) {
+	u, err := NewSQLiteAuthRepository(r.db).CreateUser(user.Email, user.PasswordHash)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = u.ID
+	user.CreatedAt = u.CreatedAt
+	return user, nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+type sqliteSeriesRepository struct{ db *sql.DB }
+
+func NewSQLiteSeriesRepository(db *sql.DB) SeriesRepository { return &sqliteSeriesRepository{db: db} }
+
+func scanSQLiteSeries(s scanner) (*models.TaskSeries, error) {
+	var (
+		ts         models.TaskSeries
+		categoryID sql.NullInt64
+		tags       string
+		subtasks   string
+		createdAt  sql.NullTime
+		endedAt    sql.NullTime
+	)
+	err := s.Scan(&ts.ID, &ts.UserID, &ts.Title, &ts.Description, &ts.Priority, &ts.RepeatRule,
+		&categoryID, &tags, &subtasks, &createdAt, &endedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if categoryID.Valid {
+		v := categoryID.Int64
+		ts.CategoryID = &v
+	}
+	if ts.Tags, err = decodeTags(tags); err != nil {
+		return nil, err
+	}
+	if ts.Subtasks, err = decodeTags(subtasks); err != nil {
+		return nil, err
+	}
+	ts.CreatedAt = createdAt.Time.Local()
+	ts.EndedAt = localTimePtr(endedAt)
+	return &ts, nil
+}
+
+func (r *sqliteSeriesRepository) Create(series *models.TaskSeries) (*models.TaskSeries, error) {
+	res, err := r.db.Exec(`
+		insert into task_series (user_id, title, description, priority, repeat_rule, category_id, tags, subtasks, created_at)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	`,
+		series.UserID, series.Title, series.Description, series.Priority, series.RepeatRule,
+		series.CategoryID, encodeTags(series.Tags), encodeTags(series.Subtasks), sqliteTime(time.Now()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(series.UserID, id)
+}
+
+func (r *sqliteSeriesRepository) Get(userID, id int64) (*models.TaskSeries, error) {
+	return scanSQLiteSeries(r.db.QueryRow(`select `+seriesColumns+` from task_series where user_id=$1 and id=$2`, userID, id))
+}
+
+func (r *sqliteSeriesRepository) Update(series *models.TaskSeries) (*models.TaskSeries, error) {
+	res, err := r.db.Exec(`
+		update task_series set title=$1, description=$2, priority=$3, repeat_rule=$4, category_id=$5,
+		       tags=$6, subtasks=$7, ended_at=$8
+		where id=$9 and user_id=$10
+	`,
+		series.Title, series.Description, series.Priority, series.RepeatRule, series.CategoryID,
+		encodeTags(series.Tags), encodeTags(series.Subtasks), sqliteNullTime(series.EndedAt),
+		series.ID, series.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return r.Get(series.UserID, series.ID)
+}
+
+func (r *sqliteSeriesRepository) Skip(userID, taskID, version int64, next *models.Task) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	now := time.Now()
+	res, err := tx.Exec(`
+		insert into series_skips (series_id, occurrence_at, skipped_at)
+		select series_id, occurrence_at, $3 from tasks
+		where id=$1 and user_id=$2 and series_id is not null and occurrence_at is not null and deleted_at is null
+		  and ($4 = 0 or version=$4)
+	`, taskID, userID, sqliteTime(now), version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if version == 0 {
+			return ErrNotFound
+		}
+		return missing(tx, "tasks", userID, taskID)
+	}
+	if _, err := tx.Exec(`delete from tasks where id=$1 and user_id=$2`, taskID, userID); err != nil {
+		return err
+	}
+	if next != nil {
+		if _, err := createSQLiteTask(tx, next, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// History runs two queries rather than a union: SQLite only reports the
+// declared column type, which the driver needs to scan timestamps, for plain
+// column references.
+func (r *sqliteSeriesRepository) History(userID, seriesID int64) ([]models.SeriesOccurrence, error) {
+	var res []models.SeriesOccurrence
+	rows, err := r.db.Query(`
+		select occurrence_at, completed, id, title, due_at, completed_at
+		from tasks
+		where user_id=$1 and series_id=$2 and occurrence_at is not null and deleted_at is null
+	`, userID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			o           models.SeriesOccurrence
+			occurrence  sql.NullTime
+			completed   bool
+			taskID      int64
+			dueAt       sql.NullTime
+			completedAt sql.NullTime
+		)
+		if err := rows.Scan(&occurrence, &completed, &taskID, &o.Title, &dueAt, &completedAt); err != nil {
+			return nil, err
+		}
+		o.OccurrenceAt = occurrence.Time.Local()
+		o.Status = models.OccurrenceOpen
+		if completed {
+			o.Status = models.OccurrenceCompleted
+		}
+		o.TaskID = &taskID
+		o.DueDate = localTimePtr(dueAt)
+		o.CompletedAt = localTimePtr(completedAt)
+		res = append(res, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	skips, err := r.db.Query(`
+		select k.occurrence_at
+		from series_skips k join task_series s on s.id = k.series_id
+		where s.user_id=$1 and k.series_id=$2
+	`, userID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer skips.Close()
+	for skips.Next() {
+		var occurrence sql.NullTime
+		if err := skips.Scan(&occurrence); err != nil {
+			return nil, err
+		}
+		res = append(res, models.SeriesOccurrence{OccurrenceAt: occurrence.Time.Local(), Status: models.OccurrenceSkipped})
+	}
+	if err := skips.Err(); err != nil {
+		return nil, err
+	}
+	sortOccurrences(res)
+	return res, nil
+}
+
+// sortOccurrences orders history like the Postgres "order by occurrence_at,
+// task id", where skips, having no task id, sort last on a tie.
+func sortOccurrences(res []models.SeriesOccurrence) {
+	sort.SliceStable(res, func(i, j int) bool {
+		if !res[i].OccurrenceAt.Equal(res[j].OccurrenceAt) {
+			return res[i].OccurrenceAt.Before(res[j].OccurrenceAt)
+		}
+		return res[i].TaskID != nil && (res[j].TaskID == nil || *res[i].TaskID < *res[j].TaskID)
+	})
+}
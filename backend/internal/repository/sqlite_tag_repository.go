@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"todo-app/backend/internal/models"
+)
+
+type sqliteTagRepo struct{ db *sql.DB }
+
+func NewSQLiteTagRepository(db *sql.DB) TagRepository { return &sqliteTagRepo{db: db} }
+
+func (r *sqliteTagRepo) All(userID int64) ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT t.value AS name FROM tasks, json_each(tasks.tags) t WHERE tasks.user_id=$1 AND tasks.deleted_at IS NULL ORDER BY name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+const sqliteTagColumns = `id, user_id, name, color, version,
+	(select count(*) from tasks t where t.user_id = tags.user_id and t.deleted_at is null
+	   and exists (select 1 from json_each(t.tags) where value = tags.name))`
+
+func (r *sqliteTagRepo) List(userID int64) ([]models.Tag, error) {
+	return queryTags(r.db, `select `+sqliteTagColumns+` from tags where user_id=$1 order by casefold(name), id`, userID)
+}
+
+func (r *sqliteTagRepo) Get(userID, id int64) (models.Tag, error) {
+	return scanTag(r.db.QueryRow(`select `+sqliteTagColumns+` from tags where id=$1 and user_id=$2`, id, userID))
+}
+
+func (r *sqliteTagRepo) GetByName(userID int64, name string) (models.Tag, error) {
+	return scanTag(r.db.QueryRow(`select `+sqliteTagColumns+` from tags where user_id=$1 and casefold(name)=casefold($2)`, userID, name))
+}
+
+func (r *sqliteTagRepo) Create(t models.Tag) (models.Tag, error) {
+	res, err := r.db.Exec(`insert into tags (user_id, name, color) values ($1,$2,$3)`, t.UserID, t.Name, t.Color)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	return r.Get(t.UserID, id)
+}
+
+// registerSQLiteTags is registerTags for SQLite.
+func registerSQLiteTags(q querier, userID int64, names []string) ([]string, error) {
+	res := []string{}
+	seen := map[string]bool{}
+	for _, n := range names {
+		if err := q.QueryRow(`
+			insert into tags (user_id, name) values ($1,$2)
+			on conflict (user_id, casefold(name)) do update set name=tags.name
+			returning name
+		`, userID, n).Scan(&n); err != nil {
+			return nil, err
+		}
+		if key := strings.ToLower(n); !seen[key] {
+			seen[key] = true
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+// sqliteRetag is retag for SQLite.
+func sqliteRetag(q querier, userID int64, from, to string) error {
+	for _, table := range []string{"tasks", "task_series"} {
+		bump := ""
+		if table == "tasks" {
+			bump = ", version=version+1"
+		}
+		if _, err := q.Exec(`
+			update `+table+` set tags = (
+				select json_group_array(n) from (
+					select case when value = $2 then $3 else value end as n
+					from json_each(`+table+`.tags)
+					group by n having n <> '' order by min(key)))`+bump+`
+			where user_id=$1 and exists (select 1 from json_each(`+table+`.tags) where value = $2)
+		`, userID, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteTagRepo) Update(t models.Tag) (models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+	var old string
+	err = tx.QueryRow(`select name from tags where id=$1 and user_id=$2 and ($3 = 0 or version=$3)`, t.ID, t.UserID, t.Version).Scan(&old)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Tag{}, tagMissing(tx, t.UserID, t.ID)
+	}
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if _, err := tx.Exec(`update tags set name=$1, color=$2, version=version+1 where id=$3`, t.Name, t.Color, t.ID); err != nil {
+		return models.Tag{}, err
+	}
+	if old != t.Name {
+		if err := sqliteRetag(tx, t.UserID, old, t.Name); err != nil {
+			return models.Tag{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Tag{}, err
+	}
+	return r.Get(t.UserID, t.ID)
+}
+
+func (r *sqliteTagRepo) Merge(userID, from, into, version int64) (models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+	names, err := tagNames(tx, userID, from, into)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if err := sqliteRetag(tx, userID, names[0], names[1]); err != nil {
+		return models.Tag{}, err
+	}
+	res, err := tx.Exec(`delete from tags where id=$1 and (version=$2 or $2 = 0)`, from, version)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Tag{}, ErrConflict
+	}
+	if _, err := tx.Exec(`update tags set version=version+1 where id=$1`, into); err != nil {
+		return models.Tag{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Tag{}, err
+	}
+	return r.Get(userID, into)
+}
+
+func (r *sqliteTagRepo) Delete(userID, id, version int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	names, err := tagNames(tx, userID, id)
+	if errors.Is(err, ErrNotFound) && version == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := sqliteRetag(tx, userID, names[0], ""); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`delete from tags where id=$1 and (version=$2 or $2 = 0)`, id, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,660 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+type sqliteTaskRepository struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func NewSQLiteTaskRepository(db *sql.DB) TaskRepository {
+	return &sqliteTaskRepository{db: db, now: time.Now}
+}
+
+const sqliteTaskColumns = `id, user_id, title, coalesce(description,''), priority, completed,
+		       created_at, due_at, completed_at, repeat_rule, category_id, coalesce(tags, '[]'),
+		       series_id, occurrence_at, version, archived_at, position`
+
+func scanSQLiteTask(s scanner) (models.Task, error) {
+	var (
+		t           models.Task
+		dueAt       sql.NullTime
+		completedAt sql.NullTime
+		repeatRule  sql.NullString
+		categoryID  sql.NullInt64
+		tags        string
+		seriesID    sql.NullInt64
+		occurrence  sql.NullTime
+		archivedAt  sql.NullTime
+	)
+	if err := s.Scan(
+		&t.ID, &t.UserID, &t.Title, &t.Description, &t.Priority, &t.Completed,
+		&t.CreatedAt, &dueAt, &completedAt, &repeatRule, &categoryID, &tags,
+		&seriesID, &occurrence, &t.Version, &archivedAt, &t.Position,
+	); err != nil {
+		return models.Task{}, err
+	}
+	t.CreatedAt = t.CreatedAt.Local()
+	t.DueDate = localTimePtr(dueAt)
+	t.CompletedAt = localTimePtr(completedAt)
+	t.OccurrenceAt = localTimePtr(occurrence)
+	t.ArchivedAt = localTimePtr(archivedAt)
+	if seriesID.Valid {
+		v := seriesID.Int64
+		t.SeriesID = &v
+	}
+	if repeatRule.Valid {
+		v := repeatRule.String
+		t.RepeatRule = &v
+	}
+	if categoryID.Valid {
+		v := categoryID.Int64
+		t.CategoryID = &v
+	}
+	var err error
+	if t.Tags, err = decodeTags(tags); err != nil {
+		return models.Task{}, err
+	}
+	return t, nil
+}
+
+func querySQLiteTasks(q querier, query string, args ...any) ([]models.Task, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Task
+	for rows.Next() {
+		t, err := scanSQLiteTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+func getSQLiteTask(q querier, userID, id int64) (*models.Task, error) {
+	t, err := scanSQLiteTask(q.QueryRow(`select `+sqliteTaskColumns+` from tasks where user_id=$1 and id=$2 and deleted_at is null`, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *sqliteTaskRepository) GetAll(userID int64, filter, sort string) ([]models.Task, error) {
+	q := `select ` + sqliteTaskColumns + ` from tasks where user_id = $1 and deleted_at is null`
+	args := []any{userID}
+	if filter == "archived" {
+		q += " and archived_at is not null"
+	} else {
+		q += " and archived_at is null"
+	}
+	now := r.now()
+	switch filter {
+	case "active":
+		q += " and completed = 0"
+	case "completed":
+		q += " and completed = 1"
+	case "overdue":
+		q += " and completed = 0 and due_at is not null and due_at < $2"
+		args = append(args, sqliteTime(now))
+	case "today":
+		from, to := dayBounds(now)
+		q += " and due_at >= $2 and due_at < $3"
+		args = append(args, sqliteTime(from), sqliteTime(to))
+	case "week":
+		from, to := weekBounds(now)
+		q += " and due_at >= $2 and due_at < $3"
+		args = append(args, sqliteTime(from), sqliteTime(to))
+	}
+	q += " order by " + taskOrder(sort, "casefold(title)")
+	return querySQLiteTasks(r.db, q, args...)
+}
+
+// filter builds the where clause for everything in f except its terms.
+func (r *sqliteTaskRepository) filter(f models.TaskFilter) *whereClause {
+	w := &whereClause{}
+	w.add("user_id = ?", f.UserID)
+	w.add("deleted_at is null")
+	if f.Status == "archived" {
+		w.add("archived_at is not null")
+	} else {
+		w.add("archived_at is null")
+	}
+	switch f.Status {
+	case "active":
+		w.add("completed = 0")
+	case "completed":
+		w.add("completed = 1")
+	}
+	if f.Priority != "" {
+		w.add("priority = ?", f.Priority)
+	}
+	now := r.now()
+	switch f.DateFilter {
+	case "overdue":
+		w.add("completed = 0 and due_at is not null and due_at < ?", sqliteTime(now))
+	case "today":
+		from, to := dayBounds(now)
+		w.add("due_at >= ? and due_at < ?", sqliteTime(from), sqliteTime(to))
+	case "week":
+		from, to := weekBounds(now)
+		w.add("due_at >= ? and due_at < ?", sqliteTime(from), sqliteTime(to))
+	case "upcoming":
+		_, to := dayBounds(now)
+		w.add("due_at >= ?", sqliteTime(to))
+	case "none":
+		w.add("due_at is null")
+	}
+	if f.From != nil {
+		w.add("due_at >= ?", sqliteTime(*f.From))
+	}
+	if f.To != nil {
+		w.add("due_at < ?", sqliteTime(*f.To))
+	}
+	if f.CategoryID != nil && f.IncludeSubcategories {
+		w.add(inCategories("select id from categories where id = ? and deleted_at is null", true), *f.CategoryID)
+	} else if f.CategoryID != nil {
+		w.add("category_id = ?", *f.CategoryID)
+	}
+	if f.CategoryName != "" {
+		w.add(inCategories("select id from categories where user_id = ? and casefold(name) = casefold(?) and deleted_at is null", f.IncludeSubcategories), f.UserID, f.CategoryName)
+	}
+	for _, tag := range f.Tags {
+		w.add("exists (select 1 from json_each(tasks.tags) where casefold(value) = casefold(?))", tag)
+	}
+	for _, tag := range f.ExcludeTags {
+		w.add("not exists (select 1 from json_each(tasks.tags) where casefold(value) = casefold(?))", tag)
+	}
+	return w
+}
+
+// queryFilter is filter with f's terms matched as substrings.
+func (r *sqliteTaskRepository) queryFilter(f models.TaskFilter) *whereClause {
+	w := r.filter(f)
+	const matches = "(instr(casefold(title), casefold(?)) > 0 or instr(casefold(coalesce(description,'')), casefold(?)) > 0)"
+	for _, term := range filterTerms(f) {
+		w.add(matches, term, term)
+	}
+	for _, term := range f.ExcludeTerms {
+		w.add("not "+matches, term, term)
+	}
+	return w
+}
+
+func (r *sqliteTaskRepository) Query(f models.TaskFilter) ([]models.Task, error) {
+	w := r.queryFilter(f)
+	q := `select ` + sqliteTaskColumns + ` from tasks where ` + w.String() + ` order by ` + taskOrder(f.Sort, "casefold(title)")
+	return querySQLiteTasks(r.db, q, w.args...)
+}
+
+func (r *sqliteTaskRepository) Page(p models.PageQuery) ([]models.Task, error) {
+	w := r.queryFilter(p.Filter)
+	order := keyset(w, p, func(t time.Time) any { return sqliteTime(t) })
+	q := `select ` + sqliteTaskColumns + ` from tasks where ` + w.String() + ` order by ` + order + ` limit ` + w.arg(p.Limit)
+	ts, err := querySQLiteTasks(r.db, q, w.args...)
+	if p.Before != nil {
+		reverseTasks(ts)
+	}
+	return ts, err
+}
+
+func (r *sqliteTaskRepository) Search(f models.TaskFilter) ([]models.SearchResult, error) {
+	w := r.filter(f)
+	const matches = `(instr(casefold(title), casefold(?)) > 0 or instr(casefold(coalesce(description,'')), casefold(?)) > 0
+		or exists (select 1 from json_each(tasks.tags) where instr(casefold(value), casefold(?)) > 0)
+		or exists (select 1 from subtasks s where s.task_id = tasks.id and s.deleted_at is null and instr(casefold(s.title), casefold(?)) > 0))`
+	terms := filterTerms(f)
+	for _, term := range terms {
+		w.add(matches, term, term, term, term)
+	}
+	for _, term := range f.ExcludeTerms {
+		w.add("not "+matches, term, term, term, term)
+	}
+	q := `select ` + sqliteTaskColumns + `,
+		       (select json_group_array(title) from subtasks where task_id = tasks.id and deleted_at is null)
+		from tasks where ` + w.String() + ` order by ` + taskOrder(f.Sort, "casefold(title)")
+	rows, err := r.db.Query(q, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.SearchResult
+	for rows.Next() {
+		var subtasks string
+		t, err := scanSQLiteTask(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &subtasks)...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		d := searchFields{title: t.Title, description: t.Description, tags: t.Tags}
+		if d.subtasks, err = decodeTags(subtasks); err != nil {
+			return nil, err
+		}
+		res = append(res, searchResult(t, d, terms))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortResults(res, f.Sort)
+	return res, nil
+}
+
+func (r *sqliteTaskRepository) GetByID(userID, id int64) (*models.Task, error) {
+	return getSQLiteTask(r.db, userID, id)
+}
+
+func createSQLiteTask(q querier, task *models.Task, now time.Time) (*models.Task, error) {
+	tags, err := registerSQLiteTags(q, task.UserID, task.Tags)
+	if err != nil {
+		return nil, err
+	}
+	task.Tags = tags
+	if task.Position == "" {
+		if task.Position, err = topPosition(q, task.UserID); err != nil {
+			return nil, err
+		}
+	}
+	res, err := q.Exec(`
+		insert into tasks (user_id, title, description, priority, completed, created_at, due_at, repeat_rule, category_id, tags, series_id, occurrence_at, position)
+		values ($1,$2,$3,$4,0,$5,$6,$7,$8,$9,$10,$11,$12)
+	`,
+		task.UserID,
+		task.Title,
+		task.Description,
+		task.Priority,
+		sqliteTime(now),
+		sqliteNullTime(task.DueDate),
+		task.RepeatRule,
+		task.CategoryID,
+		encodeTags(task.Tags),
+		task.SeriesID,
+		sqliteNullTime(task.OccurrenceAt),
+		task.Position,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if task.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+	task.CreatedAt = now
+	task.Completed = false
+	task.CompletedAt = nil
+	task.Version = 1
+	for i := range task.Subtasks {
+		st := &task.Subtasks[i]
+		st.UserID, st.TaskID, st.Completed, st.CreatedAt, st.Version = task.UserID, task.ID, false, now, 1
+		res, err := q.Exec(`
+			insert into subtasks (user_id, task_id, title, completed, created_at)
+			values ($1,$2,$3,0,$4)
+		`, st.UserID, st.TaskID, st.Title, sqliteTime(now))
+		if err != nil {
+			return nil, err
+		}
+		if st.ID, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+	}
+	return task, nil
+}
+
+func (r *sqliteTaskRepository) Create(task *models.Task) (*models.Task, error) {
+	if len(task.Subtasks) == 0 {
+		return createSQLiteTask(r.db, task, r.now())
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := createSQLiteTask(tx, task, r.now()); err != nil {
+		return nil, err
+	}
+	return task, tx.Commit()
+}
+
+func (r *sqliteTaskRepository) Move(userID, id int64, pos string) (*models.Task, error) {
+	if err := moveTask(r.db, userID, id, pos); err != nil {
+		return nil, err
+	}
+	return r.GetByID(userID, id)
+}
+
+func (r *sqliteTaskRepository) Rebalance(userID int64) error {
+	return rebalanceTasks(r.db, userID)
+}
+
+func (r *sqliteTaskRepository) Update(task *models.Task) (*models.Task, error) {
+	return updateSQLiteTask(r.db, task)
+}
+
+func (r *sqliteTaskRepository) UpdateWithSubtasks(task *models.Task, subtasks []models.Subtask) (*models.Task, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	res, err := updateSQLiteTask(tx, task)
+	if err != nil {
+		return nil, err
+	}
+	now := sqliteTime(r.now())
+	for _, st := range subtasks {
+		if _, err := tx.Exec(`
+			insert into subtasks (user_id, task_id, title, completed, created_at)
+			values ($1,$2,$3,$4,$5)
+		`, task.UserID, task.ID, st.Title, st.Completed, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// updateSQLiteTask is Update, inside a transaction or not.
+func updateSQLiteTask(q querier, task *models.Task) (*models.Task, error) {
+	tags, err := registerSQLiteTags(q, task.UserID, task.Tags)
+	if err != nil {
+		return nil, err
+	}
+	task.Tags = tags
+	res, err := q.Exec(`
+		update tasks set title=$1, description=$2, priority=$3, due_at=$4, repeat_rule=$5, category_id=$6, tags=$7,
+		       series_id=$10, occurrence_at=$11, version=version+1
+		where id=$8 and user_id=$9 and deleted_at is null and ($12 = 0 or version=$12)
+	`,
+		task.Title,
+		task.Description,
+		task.Priority,
+		sqliteNullTime(task.DueDate),
+		task.RepeatRule,
+		task.CategoryID,
+		encodeTags(task.Tags),
+		task.ID,
+		task.UserID,
+		task.SeriesID,
+		sqliteNullTime(task.OccurrenceAt),
+		task.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missing(q, "tasks", task.UserID, task.ID)
+	}
+	return getSQLiteTask(q, task.UserID, task.ID)
+}
+
+func (r *sqliteTaskRepository) SetCompleted(userID, id int64, completed bool, version int64, next *models.Task) (*models.Task, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	now := r.now()
+	var completedAt any
+	if completed {
+		completedAt = sqliteTime(now)
+	}
+	res, err := tx.Exec(`
+		update tasks set completed=$1, completed_at=$2, archived_at=case when $1 then archived_at else null end, version=version+1
+		where id=$3 and user_id=$4 and deleted_at is null and ($5 = 0 or version=$5)
+	`, completed, completedAt, id, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missing(tx, "tasks", userID, id)
+	}
+	if next != nil {
+		if _, err := createSQLiteTask(tx, next, now); err != nil {
+			return nil, err
+		}
+	}
+	t, err := getSQLiteTask(tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *sqliteTaskRepository) Delete(userID, id, version int64) error {
+	res, err := r.db.Exec(`
+		update tasks set deleted_at=$3
+		where id=$1 and user_id=$2 and deleted_at is null and ($4 = 0 or version=$4)
+	`, id, userID, sqliteTime(r.now()), version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 && version != 0 {
+		return missing(r.db, "tasks", userID, id)
+	}
+	return nil
+}
+
+func (r *sqliteTaskRepository) DeleteMany(userID int64, ids []int64) (int64, error) {
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return 0, err
+	}
+	res, err := r.db.Exec(`
+		update tasks set deleted_at=$3
+		where user_id=$1 and id in (select value from json_each($2)) and deleted_at is null
+	`, userID, string(b), sqliteTime(r.now()))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *sqliteTaskRepository) ClearCompleted(userID int64) (int64, error) {
+	res, err := r.db.Exec(`
+		update tasks set archived_at=$2, version=version+1
+		where user_id=$1 and completed=1 and archived_at is null and deleted_at is null
+	`, userID, sqliteTime(r.now()))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *sqliteTaskRepository) Unarchive(userID, id, version int64) (*models.Task, error) {
+	res, err := r.db.Exec(`
+		update tasks set archived_at=null, version=version+1
+		where id=$1 and user_id=$2 and archived_at is not null and deleted_at is null and ($3 = 0 or version=$3)
+	`, id, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missingArchived(r.db, userID, id)
+	}
+	return getSQLiteTask(r.db, userID, id)
+}
+
+func (r *sqliteTaskRepository) ArchiveCompleted(t time.Time) (int64, error) {
+	res, err := r.db.Exec(`
+		update tasks set archived_at=$2, version=version+1
+		where completed=1 and completed_at < $1 and archived_at is null and deleted_at is null
+	`, sqliteTime(t), sqliteTime(r.now()))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *sqliteTaskRepository) Restore(t *models.Task) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	tags, err := registerSQLiteTags(tx, t.UserID, t.Tags)
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(`
+		insert into tasks (id, user_id, title, description, priority, completed, created_at, due_at, completed_at,
+		                   repeat_rule, category_id, tags, series_id, occurrence_at, version, archived_at, position)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15+1,$16,$17)
+		on conflict (id) do update set title=excluded.title, description=excluded.description,
+		       priority=excluded.priority, completed=excluded.completed, created_at=excluded.created_at,
+		       due_at=excluded.due_at, completed_at=excluded.completed_at, repeat_rule=excluded.repeat_rule,
+		       category_id=excluded.category_id, tags=excluded.tags, series_id=excluded.series_id,
+		       occurrence_at=excluded.occurrence_at, version=tasks.version+1, archived_at=excluded.archived_at,
+		       deleted_at=null
+		where tasks.user_id=excluded.user_id
+	`, t.ID, t.UserID, t.Title, t.Description, t.Priority, t.Completed, sqliteTime(t.CreatedAt),
+		sqliteNullTime(t.DueDate), sqliteNullTime(t.CompletedAt), t.RepeatRule, t.CategoryID, encodeTags(tags),
+		t.SeriesID, sqliteNullTime(t.OccurrenceAt), t.Version, sqliteNullTime(t.ArchivedAt), t.Position)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(`delete from subtasks where task_id=$1 and deleted_at is null`, t.ID); err != nil {
+		return err
+	}
+	for _, st := range t.Subtasks {
+		if _, err := tx.Exec(`
+			insert into subtasks (id, user_id, task_id, title, completed, created_at, version)
+			values ($1,$2,$3,$4,$5,$6,$7+1)
+			on conflict (id) do update set title=excluded.title, completed=excluded.completed,
+			       created_at=excluded.created_at, version=subtasks.version+1, deleted_at=null
+			where subtasks.task_id=excluded.task_id
+		`, st.ID, t.UserID, t.ID, st.Title, st.Completed, sqliteTime(st.CreatedAt), st.Version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type sqliteSubtaskRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteSubtaskRepository(db *sql.DB) SubtaskRepository {
+	return &sqliteSubtaskRepository{db: db}
+}
+
+const subtaskColumns = `id, user_id, task_id, title, completed, created_at, version`
+
+func scanSQLiteSubtask(s scanner) (models.Subtask, error) {
+	var st models.Subtask
+	if err := s.Scan(&st.ID, &st.UserID, &st.TaskID, &st.Title, &st.Completed, &st.CreatedAt, &st.Version); err != nil {
+		return models.Subtask{}, err
+	}
+	st.CreatedAt = st.CreatedAt.Local()
+	return st, nil
+}
+
+func (r *sqliteSubtaskRepository) List(userID, taskID int64) ([]models.Subtask, error) {
+	rows, err := r.db.Query(`select `+subtaskColumns+` from subtasks where user_id=$1 and task_id=$2 and `+liveSubtask+` order by id`, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Subtask
+	for rows.Next() {
+		s, err := scanSQLiteSubtask(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
+
+func (r *sqliteSubtaskRepository) Create(s *models.Subtask) (*models.Subtask, error) {
+	now := time.Now()
+	res, err := r.db.Exec(`
+		insert into subtasks (user_id, task_id, title, completed, created_at)
+		select $1, id, $3, 0, $4 from tasks where id=$2 and user_id=$1 and deleted_at is null
+	`, s.UserID, s.TaskID, s.Title, sqliteTime(now))
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	if s.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+	s.CreatedAt = now
+	s.Completed = false
+	s.Version = 1
+	return s, nil
+}
+
+func (r *sqliteSubtaskRepository) Get(userID, id int64) (*models.Subtask, error) {
+	s, err := scanSQLiteSubtask(r.db.QueryRow(`select `+subtaskColumns+` from subtasks where id=$1 and user_id=$2 and `+liveSubtask, id, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sqliteSubtaskRepository) Update(s *models.Subtask) (*models.Subtask, error) {
+	res, err := r.db.Exec(`
+		update subtasks set title=$1, completed=$2, version=version+1
+		where id=$3 and user_id=$4 and `+liveSubtask+` and ($5 = 0 or version=$5)
+	`, s.Title, s.Completed, s.ID, s.UserID, s.Version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missing(r.db, "subtasks", s.UserID, s.ID)
+	}
+	return r.Get(s.UserID, s.ID)
+}
+
+func (r *sqliteSubtaskRepository) Toggle(userID, id, version int64) (*models.Subtask, error) {
+	res, err := r.db.Exec(`
+		update subtasks set completed = not completed, version=version+1
+		where id=$1 and user_id=$2 and `+liveSubtask+` and ($3 = 0 or version=$3)
+	`, id, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missing(r.db, "subtasks", userID, id)
+	}
+	return r.Get(userID, id)
+}
+
+func (r *sqliteSubtaskRepository) Delete(userID, id, version int64) error {
+	res, err := r.db.Exec(`
+		update subtasks set deleted_at=$3
+		where id=$1 and user_id=$2 and `+liveSubtask+` and ($4 = 0 or version=$4)
+	`, id, userID, sqliteTime(time.Now()), version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 && version != 0 {
+		return missing(r.db, "subtasks", userID, id)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"todo-app/backend/internal/migrations"
+	"todo-app/backend/internal/models"
+)
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todo.db")
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_txlock=immediate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := migrations.Run(db, migrations.SQLite); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newSQLiteUser(t *testing.T, db *sql.DB, email string) int64 {
+	t.Helper()
+	u, err := NewSQLiteAuthRepository(db).CreateUser(email, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.ID
+}
+
+func TestSQLiteTaskFilters(t *testing.T) {
+	db := openTestSQLite(t)
+	uid := newSQLiteUser(t, db, "a@example.com")
+	repo := NewSQLiteTaskRepository(db).(*sqliteTaskRepository)
+	now := time.Date(2024, 3, 13, 12, 0, 0, 0, time.Local) // a Wednesday
+	repo.now = func() time.Time { return now }
+
+	at := func(d time.Duration) *time.Time { v := now.Add(d); return &v }
+	for _, task := range []models.Task{
+		{Title: "yesterday", DueDate: at(-24 * time.Hour)},
+		{Title: "this morning", DueDate: at(-3 * time.Hour)},
+		{Title: "tonight", DueDate: at(10 * time.Hour)},
+		{Title: "sunday", DueDate: at(4*24*time.Hour - 2*time.Hour)},
+		{Title: "next week", DueDate: at(7 * 24 * time.Hour)},
+		{Title: "someday"},
+	} {
+		task.UserID = uid
+		task.Priority = "medium"
+		if _, err := repo.Create(&task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := models.Task{UserID: newSQLiteUser(t, db, "b@example.com"), Title: "not mine", Priority: "low", DueDate: at(0)}
+	if _, err := repo.Create(&other); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]int{"all": 6, "active": 6, "overdue": 2, "today": 2, "week": 4, "completed": 0}
+	for filter, want := range cases {
+		got, err := repo.GetAll(uid, filter, "")
+		if err != nil {
+			t.Fatalf("%s: %v", filter, err)
+		}
+		if len(got) != want {
+			t.Errorf("%s: got %d tasks, want %d", filter, len(got), want)
+		}
+	}
+}
+
+func TestSQLiteTagsAndSearch(t *testing.T) {
+	db := openTestSQLite(t)
+	uid := newSQLiteUser(t, db, "a@example.com")
+	repo := NewSQLiteTaskRepository(db)
+
+	task := &models.Task{UserID: uid, Title: "Купить молоко", Priority: "low", Tags: []string{"дом", "shop"}}
+	if _, err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Create(&models.Task{UserID: uid, Title: "Call Bob", Priority: "high"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.GetByID(uid, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tags) != 2 || got.Tags[0] != "дом" {
+		t.Fatalf("tags not round-tripped: %v", got.Tags)
+	}
+	tags, err := NewSQLiteTagRepository(db).All(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+
+	found, err := repo.Search(models.TaskFilter{UserID: uid, Terms: []string{"МОЛОКО"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Task.ID != task.ID {
+		t.Fatalf("case-insensitive search failed: %+v", found)
+	}
+}
+
+func TestSQLiteSetCompletedSpawnsNext(t *testing.T) {
+	db := openTestSQLite(t)
+	uid := newSQLiteUser(t, db, "a@example.com")
+	repo := NewSQLiteTaskRepository(db)
+
+	rule := "daily"
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	task := &models.Task{UserID: uid, Title: "water plants", Priority: "medium", RepeatRule: &rule, DueDate: &due}
+	if _, err := repo.Create(task); err != nil {
+		t.Fatal(err)
+	}
+	nextDue := due.Add(24 * time.Hour)
+	next := &models.Task{UserID: uid, Title: task.Title, Priority: task.Priority, RepeatRule: &rule, DueDate: &nextDue}
+	done, err := repo.SetCompleted(uid, task.ID, true, 0, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done.Completed || done.CompletedAt == nil {
+		t.Fatalf("task not completed: %+v", done)
+	}
+	active, err := repo.GetAll(uid, "active", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 1 || active[0].ID != next.ID || !active[0].DueDate.Equal(nextDue) {
+		t.Fatalf("next occurrence not stored: %+v", active)
+	}
+	if _, err := repo.SetCompleted(uid, 999, true, 0, nil); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	n, err := repo.DeleteMany(uid, []int64{task.ID, next.ID, 999})
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteMany = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+type sqliteTrashRepository struct{ db *sql.DB }
+
+func NewSQLiteTrashRepository(db *sql.DB) TrashRepository { return &sqliteTrashRepository{db: db} }
+
+func (r *sqliteTrashRepository) List(userID int64) (models.Trash, error) {
+	var res models.Trash
+	rows, err := r.db.Query(`select `+sqliteTaskColumns+`, deleted_at from tasks
+		where user_id=$1 and deleted_at is not null order by deleted_at desc, id desc`, userID)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var deletedAt time.Time
+		t, err := scanSQLiteTask(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &deletedAt)...)
+		}))
+		if err != nil {
+			return res, err
+		}
+		deletedAt = deletedAt.Local()
+		t.DeletedAt = &deletedAt
+		res.Tasks = append(res.Tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+	if res.Subtasks, err = listTrashedSubtasks(r.db, userID); err != nil {
+		return res, err
+	}
+	for i := range res.Subtasks {
+		st := &res.Subtasks[i]
+		st.CreatedAt = st.CreatedAt.Local()
+		*st.DeletedAt = st.DeletedAt.Local()
+	}
+	if res.Categories, err = listTrashedCategories(r.db, userID); err != nil {
+		return res, err
+	}
+	for _, c := range res.Categories {
+		*c.DeletedAt = c.DeletedAt.Local()
+	}
+	return res, nil
+}
+
+func (r *sqliteTrashRepository) RestoreTask(userID, id int64) (*models.Task, error) {
+	if err := untrash(r.db, "tasks", "deleted_at is not null", userID, id); err != nil {
+		return nil, err
+	}
+	return getSQLiteTask(r.db, userID, id)
+}
+
+func (r *sqliteTrashRepository) RestoreSubtask(userID, id int64) (*models.Subtask, error) {
+	if err := untrash(r.db, "subtasks", trashedSubtask, userID, id); err != nil {
+		return nil, err
+	}
+	return NewSQLiteSubtaskRepository(r.db).Get(userID, id)
+}
+
+func (r *sqliteTrashRepository) RestoreCategory(userID, id int64) (models.Category, error) {
+	return restoreCategory(r.db, userID, id)
+}
+
+func (r *sqliteTrashRepository) Purge(userID int64) (int64, error) {
+	return purge(r.db, "user_id=$1", userID)
+}
+
+func (r *sqliteTrashRepository) PurgeBefore(t time.Time) (int64, error) {
+	return purge(r.db, "deleted_at < $1", sqliteTime(t))
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"database/sql"
+
+	"todo-app/backend/internal/models"
+)
+
+type sqliteViewRepository struct{ db *sql.DB }
+
+func NewSQLiteViewRepository(db *sql.DB) ViewRepository { return &sqliteViewRepository{db: db} }
+
+func (r *sqliteViewRepository) List(userID int64) ([]models.SavedView, error) {
+	return queryViews(r.db, `select `+viewColumns+` from saved_views where user_id=$1 order by position, id`, userID)
+}
+
+func (r *sqliteViewRepository) Get(userID, id int64) (*models.SavedView, error) {
+	return scanView(r.db.QueryRow(`select `+viewColumns+` from saved_views where user_id=$1 and id=$2`, userID, id))
+}
+
+func (r *sqliteViewRepository) Create(v *models.SavedView) (*models.SavedView, error) {
+	filter, err := encodeFilter(v.Filter)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.db.Exec(`
+		insert into saved_views (user_id, name, filter, position)
+		values ($1, $2, $3, (select coalesce(max(position) + 1, 0) from saved_views where user_id=$1))
+	`, v.UserID, v.Name, filter)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(v.UserID, id)
+}
+
+func (r *sqliteViewRepository) Update(v *models.SavedView) (*models.SavedView, error) {
+	filter, err := encodeFilter(v.Filter)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.db.Exec(`update saved_views set name=$1, filter=$2 where id=$3 and user_id=$4`, v.Name, filter, v.ID, v.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+	return r.Get(v.UserID, v.ID)
+}
+
+func (r *sqliteViewRepository) Reorder(userID int64, ids []int64) error {
+	return reorderViews(r.db, userID, ids)
+}
+
+func (r *sqliteViewRepository) Delete(userID, id int64) error {
+	return deleteView(r.db, userID, id)
+}
@@ -1,19 +1,23 @@
 package repository
 
-import "database/sql"
+import (
+	"database/sql"
+
+	"todo-app/backend/internal/models"
+)
 
 type StatsSnapshot struct {
-	Total          int
-	Active         int
-	Completed      int
-	Overdue        int
-	HighPriority   int
-	MediumPriority int
-	LowPriority    int
+	Total     int
+	Active    int
+	Completed int
+	Overdue   int
+	// Priorities are the user's levels as PriorityRepository.List returns
+	// them, each counting its tasks.
+	Priorities []models.Priority
 }
 
 type StatsRepository interface {
-	Snapshot() (StatsSnapshot, error)
+	Snapshot(userID int64) (StatsSnapshot, error)
 }
 
 type statsRepo struct {
@@ -24,17 +28,19 @@ func NewStatsRepository(db *sql.DB) StatsRepository {
 	return &statsRepo{db: db}
 }
 
-func (r *statsRepo) Snapshot() (StatsSnapshot, error) {
+func (r *statsRepo) Snapshot(userID int64) (StatsSnapshot, error) {
 	var s StatsSnapshot
-	err := r.db.QueryRow(`SELECT COUNT(*) FROM tasks`).Scan(&s.Total)
+	err := r.db.QueryRow(`
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE completed=false),
+		       COUNT(*) FILTER (WHERE completed=true),
+		       COUNT(*) FILTER (WHERE completed=false AND due_at IS NOT NULL AND due_at < now())
+		FROM tasks
+		WHERE user_id=$1 AND deleted_at IS NULL
+	`, userID).Scan(&s.Total, &s.Active, &s.Completed, &s.Overdue)
 	if err != nil {
 		return s, err
 	}
-	_ = r.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE completed=false`).Scan(&s.Active)
-	_ = r.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE completed=true`).Scan(&s.Completed)
-	_ = r.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE completed=false AND due_date < now()`).Scan(&s.Overdue)
-	_ = r.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE priority='high'`).Scan(&s.HighPriority)
-	_ = r.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE priority='medium'`).Scan(&s.MediumPriority)
-	_ = r.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE priority='low'`).Scan(&s.LowPriority)
-	return s, nil
+	s.Priorities, err = listPriorities(r.db, userID)
+	return s, err
 }
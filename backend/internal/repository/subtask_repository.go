@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"todo-app/backend/internal/models"
+)
+
+type SubtaskRepository interface {
+	List(userID, taskID int64) ([]models.Subtask, error)
+	Get(userID, id int64) (*models.Subtask, error)
+	Create(s *models.Subtask) (*models.Subtask, error)
+	// Update saves the title and completion state, with the same version
+	// check as TaskRepository.Update.
+	Update(s *models.Subtask) (*models.Subtask, error)
+	// Toggle has the same version check as Update.
+	Toggle(userID, id, version int64) (*models.Subtask, error)
+	// Delete has the same version check as Update; with version 0 deleting
+	// a missing subtask is not an error.
+	Delete(userID, id, version int64) error
+}
+
+// liveSubtask matches subtasks that are not in the trash, either themselves
+// or with their task.
+const liveSubtask = `deleted_at is null and task_id in (select id from tasks where deleted_at is null)`
+
+type subtaskRepository struct {
+	db *sql.DB
+}
+
+func NewSubtaskRepository(db *sql.DB) SubtaskRepository {
+	return &subtaskRepository{db: db}
+}
+
+func scanSubtask(s scanner) (*models.Subtask, error) {
+	var st models.Subtask
+	err := s.Scan(&st.ID, &st.UserID, &st.TaskID, &st.Title, &st.Completed, &st.CreatedAt, &st.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (r *subtaskRepository) List(userID, taskID int64) ([]models.Subtask, error) {
+	rows, err := r.db.Query(`select `+subtaskColumns+` from subtasks where user_id=$1 and task_id=$2 and `+liveSubtask+` order by id`, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Subtask
+	for rows.Next() {
+		s, err := scanSubtask(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
+
+func (r *subtaskRepository) Get(userID, id int64) (*models.Subtask, error) {
+	return scanSubtask(r.db.QueryRow(`select `+subtaskColumns+` from subtasks where id=$1 and user_id=$2 and `+liveSubtask, id, userID))
+}
+
+func (r *subtaskRepository) Create(s *models.Subtask) (*models.Subtask, error) {
+	err := r.db.QueryRow(`
+		insert into subtasks (user_id, task_id, title, completed, created_at)
+		select $1, id, $3, false, now() from tasks where id=$2 and user_id=$1 and deleted_at is null
+		returning id, created_at, version
+	`, s.UserID, s.TaskID, s.Title).Scan(&s.ID, &s.CreatedAt, &s.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Completed = false
+	return s, nil
+}
+
+func (r *subtaskRepository) Update(s *models.Subtask) (*models.Subtask, error) {
+	res, err := scanSubtask(r.db.QueryRow(`
+		update subtasks set title=$1, completed=$2, version=version+1
+		where id=$3 and user_id=$4 and `+liveSubtask+` and ($5::bigint = 0 or version=$5)
+		returning `+subtaskColumns, s.Title, s.Completed, s.ID, s.UserID, s.Version))
+	if errors.Is(err, ErrNotFound) {
+		return nil, missing(r.db, "subtasks", s.UserID, s.ID)
+	}
+	return res, err
+}
+
+func (r *subtaskRepository) Toggle(userID, id, version int64) (*models.Subtask, error) {
+	res, err := scanSubtask(r.db.QueryRow(`
+		update subtasks set completed = not completed, version=version+1
+		where id=$1 and user_id=$2 and `+liveSubtask+` and ($3::bigint = 0 or version=$3)
+		returning `+subtaskColumns, id, userID, version))
+	if errors.Is(err, ErrNotFound) {
+		return nil, missing(r.db, "subtasks", userID, id)
+	}
+	return res, err
+}
+
+func (r *subtaskRepository) Delete(userID, id, version int64) error {
+	res, err := r.db.Exec(`
+		update subtasks set deleted_at=now()
+		where id=$1 and user_id=$2 and `+liveSubtask+` and ($3::bigint = 0 or version=$3)
+	`, id, userID, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 && version != 0 {
+		return missing(r.db, "subtasks", userID, id)
+	}
+	return nil
+}
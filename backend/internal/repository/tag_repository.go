@@ -1,9 +1,35 @@
 package repository
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+	"strings"
 
+	"todo-app/backend/internal/models"
+)
+
+// TagRepository manages the user's tags. Names are unique per user ignoring
+// case. Tasks and series hold the names of their tags; writing a task
+// registers its tags, spelled like the stored tag when one matches.
 type TagRepository interface {
-	All() ([]string, error)
+	// All lists the names of the tags on the user's tasks outside the trash.
+	All(userID int64) ([]string, error)
+	// List returns all of the user's tags by name, with Count set.
+	List(userID int64) ([]models.Tag, error)
+	Get(userID, id int64) (models.Tag, error)
+	// GetByName finds the tag whose name matches ignoring case.
+	GetByName(userID int64, name string) (models.Tag, error)
+	Create(t models.Tag) (models.Tag, error)
+	// Update renames and recolors t, with the same version check as
+	// TaskRepository.Update. A rename is applied to every task and series.
+	Update(t models.Tag) (models.Tag, error)
+	// Merge replaces tag from with into on every task and series, then
+	// deletes from. version is from's, with the same check as Update.
+	Merge(userID, from, into, version int64) (models.Tag, error)
+	// Delete removes the tag from every task and series. It has the same
+	// version check as Update; with version 0 deleting a missing tag is not
+	// an error.
+	Delete(userID, id, version int64) error
 }
 
 type tagRepo struct {
@@ -14,8 +40,8 @@ func NewTagRepository(db *sql.DB) TagRepository {
 	return &tagRepo{db: db}
 }
 
-func (r *tagRepo) All() ([]string, error) {
-	rows, err := r.db.Query(`SELECT name FROM tags ORDER BY name`)
+func (r *tagRepo) All(userID int64) ([]string, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT unnest(tags) AS name FROM tasks WHERE user_id=$1 AND deleted_at IS NULL ORDER BY name`, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -30,3 +56,205 @@ func (r *tagRepo) All() ([]string, error) {
 	}
 	return out, nil
 }
+
+const tagColumns = `id, user_id, name, color, version,
+	(select count(*) from tasks t where t.user_id = tags.user_id and t.deleted_at is null and tags.name = any(t.tags))`
+
+func scanTag(s scanner) (models.Tag, error) {
+	var t models.Tag
+	err := s.Scan(&t.ID, &t.UserID, &t.Name, &t.Color, &t.Version, &t.Count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return t, ErrNotFound
+	}
+	return t, err
+}
+
+func queryTags(q querier, query string, args ...any) ([]models.Tag, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Tag
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+func (r *tagRepo) List(userID int64) ([]models.Tag, error) {
+	return queryTags(r.db, `select `+tagColumns+` from tags where user_id=$1 order by lower(name), id`, userID)
+}
+
+func (r *tagRepo) Get(userID, id int64) (models.Tag, error) {
+	return scanTag(r.db.QueryRow(`select `+tagColumns+` from tags where id=$1 and user_id=$2`, id, userID))
+}
+
+func (r *tagRepo) GetByName(userID int64, name string) (models.Tag, error) {
+	return scanTag(r.db.QueryRow(`select `+tagColumns+` from tags where user_id=$1 and lower(name)=lower($2)`, userID, name))
+}
+
+func (r *tagRepo) Create(t models.Tag) (models.Tag, error) {
+	var id int64
+	if err := r.db.QueryRow(`insert into tags (user_id, name, color) values ($1,$2,$3) returning id`, t.UserID, t.Name, t.Color).Scan(&id); err != nil {
+		return models.Tag{}, err
+	}
+	return r.Get(t.UserID, id)
+}
+
+// registerTags adds the names missing from the user's tags and returns names
+// spelled like the stored tags, without duplicates.
+func registerTags(q querier, userID int64, names []string) ([]string, error) {
+	res := []string{}
+	seen := map[string]bool{}
+	for _, n := range names {
+		if err := q.QueryRow(`
+			insert into tags (user_id, name) values ($1,$2)
+			on conflict (user_id, lower(name)) do update set name=tags.name
+			returning name
+		`, userID, n).Scan(&n); err != nil {
+			return nil, err
+		}
+		if key := strings.ToLower(n); !seen[key] {
+			seen[key] = true
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+// retag replaces tag name from with name to on every task and series of the
+// user, dropping it when to is empty and keeping each name once.
+func retag(q querier, userID int64, from, to string) error {
+	for _, table := range []string{"tasks", "task_series"} {
+		bump := ""
+		if table == "tasks" {
+			bump = ", version=version+1"
+		}
+		if _, err := q.Exec(`
+			update `+table+` x set tags = array(
+				select n from (
+					select case when u.name = $2 then $3 else u.name end as n, u.ord
+					from unnest(x.tags) with ordinality u(name, ord)) r
+				where n <> '' group by n order by min(ord))`+bump+`
+			where user_id=$1 and $2 = any(tags)
+		`, userID, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *tagRepo) Update(t models.Tag) (models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+	var old string
+	err = tx.QueryRow(`select name from tags where id=$1 and user_id=$2 and ($3::bigint = 0 or version=$3) for update`, t.ID, t.UserID, t.Version).Scan(&old)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Tag{}, tagMissing(tx, t.UserID, t.ID)
+	}
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if _, err := tx.Exec(`update tags set name=$1, color=$2, version=version+1 where id=$3`, t.Name, t.Color, t.ID); err != nil {
+		return models.Tag{}, err
+	}
+	if old != t.Name {
+		if err := retag(tx, t.UserID, old, t.Name); err != nil {
+			return models.Tag{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Tag{}, err
+	}
+	return r.Get(t.UserID, t.ID)
+}
+
+// tagMissing is missing for tags, which have no trash.
+func tagMissing(q querier, userID, id int64) error {
+	var n int
+	if err := q.QueryRow(`select count(*) from tags where id=$1 and user_id=$2`, id, userID).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return ErrConflict
+}
+
+func (r *tagRepo) Merge(userID, from, into, version int64) (models.Tag, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.Tag{}, err
+	}
+	defer tx.Rollback()
+	names, err := tagNames(tx, userID, from, into)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if err := retag(tx, userID, names[0], names[1]); err != nil {
+		return models.Tag{}, err
+	}
+	res, err := tx.Exec(`delete from tags where id=$1 and (version=$2 or $2 = 0)`, from, version)
+	if err != nil {
+		return models.Tag{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Tag{}, ErrConflict
+	}
+	if _, err := tx.Exec(`update tags set version=version+1 where id=$1`, into); err != nil {
+		return models.Tag{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Tag{}, err
+	}
+	return r.Get(userID, into)
+}
+
+// tagNames looks up the names of the user's tags ids, in order.
+func tagNames(q querier, userID int64, ids ...int64) ([]string, error) {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		err := q.QueryRow(`select name from tags where id=$1 and user_id=$2`, id, userID).Scan(&names[i])
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return names, nil
+}
+
+func (r *tagRepo) Delete(userID, id, version int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	names, err := tagNames(tx, userID, id)
+	if errors.Is(err, ErrNotFound) && version == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := retag(tx, userID, names[0], ""); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`delete from tags where id=$1 and (version=$2 or $2 = 0)`, id, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrConflict
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+// whereClause collects conditions joined with "and". Each ? in a condition
+// takes the next argument and becomes a numbered placeholder.
+type whereClause struct {
+	conds []string
+	args  []any
+}
+
+func (w *whereClause) add(cond string, args ...any) {
+	for _, a := range args {
+		cond = strings.Replace(cond, "?", w.arg(a), 1)
+	}
+	w.conds = append(w.conds, cond)
+}
+
+// arg appends an argument used outside the conditions and returns its
+// placeholder.
+func (w *whereClause) arg(a any) string {
+	w.args = append(w.args, a)
+	return "$" + strconv.Itoa(len(w.args))
+}
+
+func (w *whereClause) String() string {
+	return strings.Join(w.conds, " and ")
+}
+
+// inCategories selects the tasks whose category is one of ids, a query
+// selecting category ids, or with sub one of their live subcategories.
+func inCategories(ids string, sub bool) string {
+	if sub {
+		ids = `with recursive sub(id) as (` + ids + `
+			union select c.id from categories c join sub on c.parent_id = sub.id where c.deleted_at is null)
+			select id from sub`
+	}
+	return "category_id in (" + ids + ")"
+}
+
+// filterTerms merges the legacy single Search string into Terms.
+func filterTerms(f models.TaskFilter) []string {
+	terms := f.Terms
+	if s := strings.TrimSpace(f.Search); s != "" {
+		terms = append(append([]string{}, terms...), s)
+	}
+	return terms
+}
+
+// likePattern escapes LIKE wildcards so that a term matches literally.
+func likePattern(term string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(term) + "%"
+}
+
+// taskOrder returns the order by clause for a TaskFilter.Sort value; title is
+// the dialect's case-folded title expression.
+func taskOrder(sort, title string) string {
+	switch sort {
+	case "manual":
+		return "position, created_at desc, id desc"
+	case "due":
+		return "due_at is null, due_at, created_at desc, id desc"
+	case "priority":
+		return "coalesce(" + priorityWeight + ", -1) desc, created_at desc, id desc"
+	case "title":
+		return title + ", id"
+	default:
+		return "created_at desc, id desc"
+	}
+}
+
+// keyset narrows w to the page p selects and returns the order to read it
+// in; rows read for Before come out oldest first and are put back by
+// reverseTasks. bind converts a time to the dialect's parameter.
+func keyset(w *whereClause, p models.PageQuery, bind func(time.Time) any) string {
+	switch {
+	case p.After != nil:
+		w.add("(created_at, id) < (?, ?)", bind(p.After.CreatedAt), p.After.ID)
+	case p.Before != nil:
+		w.add("(created_at, id) > (?, ?)", bind(p.Before.CreatedAt), p.Before.ID)
+		return "created_at, id"
+	}
+	return "created_at desc, id desc"
+}
+
+func reverseTasks(ts []models.Task) {
+	for i, j := 0, len(ts)-1; i < j; i, j = i+1, j-1 {
+		ts[i], ts[j] = ts[j], ts[i]
+	}
+}
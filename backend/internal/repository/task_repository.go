@@ -2,20 +2,67 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/position"
 
 	"github.com/lib/pq"
 )
 
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the row was changed since the version the caller
+	// read; it is returned instead of overwriting that change.
+	ErrConflict = errors.New("version conflict")
+)
+
 type TaskRepository interface {
-	GetAll(userID int64, filter string) ([]models.Task, error)
+	// GetAll lists the tasks a named filter selects, in the order of a
+	// TaskFilter.Sort value.
+	GetAll(userID int64, filter, sort string) ([]models.Task, error)
+	Query(f models.TaskFilter) ([]models.Task, error)
+	Page(p models.PageQuery) ([]models.Task, error)
+	// Search ranks the tasks matching f's terms by relevance, or orders them
+	// by f.Sort when it is set. f must have at least one term.
+	Search(f models.TaskFilter) ([]models.SearchResult, error)
 	GetByID(userID, id int64) (*models.Task, error)
 	Create(task *models.Task) (*models.Task, error)
+	// Update fails with ErrConflict unless task.Version is 0 or the stored
+	// version. Every write to a task increments its version.
 	Update(task *models.Task) (*models.Task, error)
-	Delete(userID, id int64) error
+	// UpdateWithSubtasks is Update that also adds subtasks, with their
+	// titles and completion state, to the task in the same transaction.
+	UpdateWithSubtasks(task *models.Task, subtasks []models.Subtask) (*models.Task, error)
+	// SetCompleted has the same version check as Update.
+	SetCompleted(userID, id int64, completed bool, version int64, next *models.Task) (*models.Task, error)
+	// Delete has the same version check as Update; with version 0 deleting
+	// a missing task is not an error.
+	Delete(userID, id, version int64) error
+	DeleteMany(userID int64, ids []int64) (int64, error)
+	// ClearCompleted archives the user's completed tasks. Archived tasks are
+	// left out of every listing unless the filter asks for them, and marking
+	// one as not completed unarchives it.
 	ClearCompleted(userID int64) (int64, error)
+	// Unarchive fails with ErrNotFound unless the task is archived, and has
+	// the same version check as Update.
+	Unarchive(userID, id, version int64) (*models.Task, error)
+	// ArchiveCompleted archives every user's tasks completed before t.
+	ArchiveCompleted(t time.Time) (int64, error)
+	// Restore writes task back exactly as given, with its id and
+	// task.Subtasks replacing the stored live ones, recreating it if it was
+	// deleted; trashed subtasks not in task.Subtasks stay in the trash. The
+	// version still moves forward. A task that still exists keeps its
+	// position, which Rebalance may have rewritten since.
+	Restore(task *models.Task) error
+	// Move sets the task's position. Positions are not versioned, so a move
+	// neither conflicts with an edit nor stales an undo step.
+	Move(userID, id int64, position string) (*models.Task, error)
+	// Rebalance gives the user's tasks evenly spaced positions in their
+	// current manual order. The order does not change, so neither do the
+	// versions.
+	Rebalance(userID int64) error
 }
 
 type taskRepository struct {
@@ -26,113 +73,65 @@ func NewTaskRepository(db *sql.DB) TaskRepository {
 	return &taskRepository{db: db}
 }
 
-func (r *taskRepository) GetAll(userID int64, filter string) ([]models.Task, error) {
-	q := `
-		select id, user_id, title, coalesce(description,''), priority, completed,
-		       created_at, due_at, completed_at, repeat_rule, category_id, coalesce(tags, '{}')
-		from tasks
-		where user_id = $1
-	`
-	switch filter {
-	case "active":
-		q += " and completed=false"
-	case "completed":
-		q += " and completed=true"
-	}
-	q += " order by created_at desc"
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
 
-	rows, err := r.db.Query(q, userID)
-	if err != nil {
-		return nil, err
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// missing tells why a versioned update matched no row: ErrNotFound when the
+// row is gone or belongs to another user, ErrConflict when it has moved on.
+func missing(q querier, table string, userID, id int64) error {
+	var n int
+	if err := q.QueryRow(`select count(*) from `+table+` where id=$1 and user_id=$2 and deleted_at is null`, id, userID).Scan(&n); err != nil {
+		return err
 	}
-	defer rows.Close()
+	if n == 0 {
+		return ErrNotFound
+	}
+	return ErrConflict
+}
 
-	var res []models.Task
-	for rows.Next() {
-		var (
-			id          int64
-			uID         int64
-			title       string
-			desc        string
-			priority    string
-			completed   bool
-			createdAt   time.Time
-			dueAt       sql.NullTime
-			completedAt sql.NullTime
-			repeatRule  sql.NullString
-			categoryID  sql.NullInt64
-			tags        pq.StringArray
-		)
-		if err := rows.Scan(
-			&id, &uID, &title, &desc, &priority, &completed,
-			&createdAt, &dueAt, &completedAt, &repeatRule, &categoryID, &tags,
-		); err != nil {
-			return nil, err
-		}
-		var t models.Task
-		t.ID = id
-		t.UserID = uID
-		t.Title = title
-		t.Description = desc
-		t.Priority = priority
-		t.Completed = completed
-		t.CreatedAt = createdAt
-		if dueAt.Valid {
-			v := dueAt.Time
-			t.DueDate = &v
-		}
-		if completedAt.Valid {
-			v := completedAt.Time
-			t.CompletedAt = &v
-		}
-		if repeatRule.Valid {
-			v := repeatRule.String
-			t.RepeatRule = &v
-		}
-		if categoryID.Valid {
-			v := categoryID.Int64
-			t.CategoryID = &v
-		}
-		t.Tags = []string(tags)
-		res = append(res, t)
+// missingArchived explains why a versioned write to an archived task matched
+// no row, like missing.
+func missingArchived(q querier, userID, id int64) error {
+	var n int
+	if err := q.QueryRow(`select count(*) from tasks where id=$1 and user_id=$2 and archived_at is not null and deleted_at is null`, id, userID).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
 	}
-	return res, nil
+	return ErrConflict
 }
 
-func (r *taskRepository) GetByID(userID, id int64) (*models.Task, error) {
-	q := `
-		select id, user_id, title, coalesce(description,''), priority, completed,
-		       created_at, due_at, completed_at, repeat_rule, category_id, coalesce(tags, '{}')
-		from tasks where user_id=$1 and id=$2
-	`
+const taskColumns = `id, user_id, title, coalesce(description,''), priority, completed,
+		       created_at, due_at, completed_at, repeat_rule, category_id, coalesce(tags, '{}'),
+		       series_id, occurrence_at, version, archived_at, position`
+
+func scanTask(s scanner) (models.Task, error) {
 	var (
-		tid         int64
-		uID         int64
-		title       string
-		desc        string
-		priority    string
-		completed   bool
-		createdAt   time.Time
+		t           models.Task
 		dueAt       sql.NullTime
 		completedAt sql.NullTime
 		repeatRule  sql.NullString
 		categoryID  sql.NullInt64
 		tags        pq.StringArray
+		seriesID    sql.NullInt64
+		occurrence  sql.NullTime
+		archivedAt  sql.NullTime
 	)
-	if err := r.db.QueryRow(q, userID, id).Scan(
-		&tid, &uID, &title, &desc, &priority, &completed,
-		&createdAt, &dueAt, &completedAt, &repeatRule, &categoryID, &tags,
+	if err := s.Scan(
+		&t.ID, &t.UserID, &t.Title, &t.Description, &t.Priority, &t.Completed,
+		&t.CreatedAt, &dueAt, &completedAt, &repeatRule, &categoryID, &tags,
+		&seriesID, &occurrence, &t.Version, &archivedAt, &t.Position,
 	); err != nil {
-		return nil, err
+		return models.Task{}, err
 	}
-	var t models.Task
-	t.ID = tid
-	t.UserID = uID
-	t.Title = title
-	t.Description = desc
-	t.Priority = priority
-	t.Completed = completed
-	t.CreatedAt = createdAt
 	if dueAt.Valid {
 		v := dueAt.Time
 		t.DueDate = &v
@@ -150,16 +149,184 @@ func (r *taskRepository) GetByID(userID, id int64) (*models.Task, error) {
 		t.CategoryID = &v
 	}
 	t.Tags = []string(tags)
+	if seriesID.Valid {
+		v := seriesID.Int64
+		t.SeriesID = &v
+	}
+	if occurrence.Valid {
+		v := occurrence.Time
+		t.OccurrenceAt = &v
+	}
+	if archivedAt.Valid {
+		v := archivedAt.Time
+		t.ArchivedAt = &v
+	}
+	return t, nil
+}
+
+func queryTasks(q querier, query string, args ...any) ([]models.Task, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+func getTask(q querier, userID, id int64) (*models.Task, error) {
+	t, err := scanTask(q.QueryRow(`select `+taskColumns+` from tasks where user_id=$1 and id=$2 and deleted_at is null`, userID, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
 	return &t, nil
 }
 
-func (r *taskRepository) Create(task *models.Task) (*models.Task, error) {
-	q := `
-		insert into tasks (user_id, title, description, priority, completed, created_at, due_at, repeat_rule, category_id, tags)
-		values ($1,$2,$3,$4,false,now(),$5,$6,$7,$8)
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func (r *taskRepository) GetAll(userID int64, filter, sort string) ([]models.Task, error) {
+	q := `select ` + taskColumns + ` from tasks where user_id = $1 and deleted_at is null`
+	if filter == "archived" {
+		q += " and archived_at is not null"
+	} else {
+		q += " and archived_at is null"
+	}
+	switch filter {
+	case "active":
+		q += " and completed = false"
+	case "completed":
+		q += " and completed = true"
+	case "overdue":
+		q += " and completed = false and due_at is not null and due_at < now()"
+	case "today":
+		q += " and due_at::date = current_date"
+	case "week":
+		q += " and due_at >= date_trunc('week', now()) and due_at < date_trunc('week', now()) + interval '1 week'"
+	}
+	q += " order by " + taskOrder(sort, "lower(title)")
+	return queryTasks(r.db, q, userID)
+}
+
+// taskFilter builds the where clause for everything in f except its terms.
+func taskFilter(f models.TaskFilter) *whereClause {
+	w := &whereClause{}
+	w.add("user_id = ?", f.UserID)
+	w.add("deleted_at is null")
+	if f.Status == "archived" {
+		w.add("archived_at is not null")
+	} else {
+		w.add("archived_at is null")
+	}
+	switch f.Status {
+	case "active":
+		w.add("completed = false")
+	case "completed":
+		w.add("completed = true")
+	}
+	if f.Priority != "" {
+		w.add("priority = ?", f.Priority)
+	}
+	switch f.DateFilter {
+	case "overdue":
+		w.add("completed = false and due_at is not null and due_at < now()")
+	case "today":
+		w.add("due_at::date = current_date")
+	case "week":
+		w.add("due_at >= date_trunc('week', now()) and due_at < date_trunc('week', now()) + interval '1 week'")
+	case "upcoming":
+		w.add("due_at >= current_date + 1")
+	case "none":
+		w.add("due_at is null")
+	}
+	if f.From != nil {
+		w.add("due_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		w.add("due_at < ?", *f.To)
+	}
+	if f.CategoryID != nil && f.IncludeSubcategories {
+		w.add(inCategories("select id from categories where id = ? and deleted_at is null", true), *f.CategoryID)
+	} else if f.CategoryID != nil {
+		w.add("category_id = ?", *f.CategoryID)
+	}
+	if f.CategoryName != "" {
+		w.add(inCategories("select id from categories where user_id = ? and lower(name) = lower(?) and deleted_at is null", f.IncludeSubcategories), f.UserID, f.CategoryName)
+	}
+	for _, tag := range f.Tags {
+		w.add("exists (select 1 from unnest(tags) t where lower(t) = lower(?))", tag)
+	}
+	for _, tag := range f.ExcludeTags {
+		w.add("not exists (select 1 from unnest(tags) t where lower(t) = lower(?))", tag)
+	}
+	return w
+}
+
+// queryFilter is taskFilter with f's terms matched as substrings.
+func queryFilter(f models.TaskFilter) *whereClause {
+	w := taskFilter(f)
+	for _, term := range filterTerms(f) {
+		p := likePattern(term)
+		w.add("(title ilike ? or coalesce(description,'') ilike ?)", p, p)
+	}
+	for _, term := range f.ExcludeTerms {
+		p := likePattern(term)
+		w.add("not (title ilike ? or coalesce(description,'') ilike ?)", p, p)
+	}
+	return w
+}
+
+func (r *taskRepository) Query(f models.TaskFilter) ([]models.Task, error) {
+	w := queryFilter(f)
+	q := `select ` + taskColumns + ` from tasks where ` + w.String() + ` order by ` + taskOrder(f.Sort, "lower(title)")
+	return queryTasks(r.db, q, w.args...)
+}
+
+func (r *taskRepository) Page(p models.PageQuery) ([]models.Task, error) {
+	w := queryFilter(p.Filter)
+	order := keyset(w, p, func(t time.Time) any { return t })
+	q := `select ` + taskColumns + ` from tasks where ` + w.String() + ` order by ` + order + ` limit ` + w.arg(p.Limit)
+	ts, err := queryTasks(r.db, q, w.args...)
+	if p.Before != nil {
+		reverseTasks(ts)
+	}
+	return ts, err
+}
+
+func (r *taskRepository) GetByID(userID, id int64) (*models.Task, error) {
+	return getTask(r.db, userID, id)
+}
+
+func createTask(q querier, task *models.Task) (*models.Task, error) {
+	tags, err := registerTags(q, task.UserID, task.Tags)
+	if err != nil {
+		return nil, err
+	}
+	task.Tags = tags
+	if task.Position == "" {
+		if task.Position, err = topPosition(q, task.UserID); err != nil {
+			return nil, err
+		}
+	}
+	err = q.QueryRow(`
+		insert into tasks (user_id, title, description, priority, completed, created_at, due_at, repeat_rule, category_id, tags, series_id, occurrence_at, position)
+		values ($1,$2,$3,$4,false,now(),$5,$6,$7,$8,$9,$10,$11)
 		returning id, created_at
-	`
-	if err := r.db.QueryRow(q,
+	`,
 		task.UserID,
 		task.Title,
 		task.Description,
@@ -168,20 +335,158 @@ func (r *taskRepository) Create(task *models.Task) (*models.Task, error) {
 		task.RepeatRule,
 		task.CategoryID,
 		pq.Array(task.Tags),
-	).Scan(&task.ID, &task.CreatedAt); err != nil {
+		task.SeriesID,
+		task.OccurrenceAt,
+		task.Position,
+	).Scan(&task.ID, &task.CreatedAt)
+	if err != nil {
 		return nil, err
 	}
+	task.Completed = false
+	task.CompletedAt = nil
+	task.Version = 1
+	for i := range task.Subtasks {
+		st := &task.Subtasks[i]
+		st.UserID, st.TaskID, st.Completed, st.Version = task.UserID, task.ID, false, 1
+		if err := q.QueryRow(`
+			insert into subtasks (user_id, task_id, title, completed, created_at)
+			values ($1,$2,$3,false,now())
+			returning id, created_at
+		`, st.UserID, st.TaskID, st.Title).Scan(&st.ID, &st.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
 	return task, nil
 }
 
+func (r *taskRepository) Create(task *models.Task) (*models.Task, error) {
+	if len(task.Subtasks) == 0 {
+		return createTask(r.db, task)
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := createTask(tx, task); err != nil {
+		return nil, err
+	}
+	return task, tx.Commit()
+}
+
+// topPosition is the position that puts a new task first: just below the
+// smallest one, or empty, which sorts before every key, when there is no
+// room or the user has unpositioned tasks.
+func topPosition(q querier, userID int64) (string, error) {
+	var first sql.NullString
+	if err := q.QueryRow(`select min(position) from tasks where user_id=$1 and deleted_at is null`, userID).Scan(&first); err != nil {
+		return "", err
+	}
+	if first.Valid && first.String == "" {
+		return "", nil
+	}
+	k, _ := position.Between("", first.String)
+	return k, nil
+}
+
+// moveTask is Move for both dialects.
+func moveTask(q querier, userID, id int64, pos string) error {
+	res, err := q.Exec(`update tasks set position=$3 where id=$1 and user_id=$2 and deleted_at is null`, id, userID, pos)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// rebalanceTasks is Rebalance for both dialects. Trashed tasks are included
+// so that they come back to their place.
+func rebalanceTasks(db *sql.DB, userID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	rows, err := tx.Query(`select id from tasks where user_id=$1 order by `+taskOrder("manual", ""), userID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i, k := range position.Spread(len(ids)) {
+		if _, err := tx.Exec(`update tasks set position=$1 where id=$2`, k, ids[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *taskRepository) Move(userID, id int64, pos string) (*models.Task, error) {
+	if err := moveTask(r.db, userID, id, pos); err != nil {
+		return nil, err
+	}
+	return r.GetByID(userID, id)
+}
+
+func (r *taskRepository) Rebalance(userID int64) error {
+	return rebalanceTasks(r.db, userID)
+}
+
 func (r *taskRepository) Update(task *models.Task) (*models.Task, error) {
-	q := `
-		update tasks set title=$1, description=$2, priority=$3, due_at=$4, repeat_rule=$5, category_id=$6, tags=$7
-		where id=$8 and user_id=$9
-		returning created_at, completed, completed_at
+	return updateTask(r.db, task)
+}
+
+func (r *taskRepository) UpdateWithSubtasks(task *models.Task, subtasks []models.Subtask) (*models.Task, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if _, err := updateTask(tx, task); err != nil {
+		return nil, err
+	}
+	for _, st := range subtasks {
+		if _, err := tx.Exec(`
+			insert into subtasks (user_id, task_id, title, completed, created_at)
+			values ($1,$2,$3,$4,now())
+		`, task.UserID, task.ID, st.Title, st.Completed); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// updateTask is Update, inside a transaction or not.
+func updateTask(q querier, task *models.Task) (*models.Task, error) {
+	tags, err := registerTags(q, task.UserID, task.Tags)
+	if err != nil {
+		return nil, err
+	}
+	task.Tags = tags
+	query := `
+		update tasks set title=$1, description=$2, priority=$3, due_at=$4, repeat_rule=$5, category_id=$6, tags=$7,
+		       series_id=$10, occurrence_at=$11, version=version+1
+		where id=$8 and user_id=$9 and deleted_at is null and ($12::bigint = 0 or version=$12)
+		returning created_at, completed, completed_at, version, archived_at
 	`
-	var completedAt sql.NullTime
-	if err := r.db.QueryRow(q,
+	var completedAt, archivedAt sql.NullTime
+	err = q.QueryRow(query,
 		task.Title,
 		task.Description,
 		task.Priority,
@@ -191,7 +496,14 @@ func (r *taskRepository) Update(task *models.Task) (*models.Task, error) {
 		pq.Array(task.Tags),
 		task.ID,
 		task.UserID,
-	).Scan(&task.CreatedAt, &task.Completed, &completedAt); err != nil {
+		task.SeriesID,
+		task.OccurrenceAt,
+		task.Version,
+	).Scan(&task.CreatedAt, &task.Completed, &completedAt, &task.Version, &archivedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, missing(q, "tasks", task.UserID, task.ID)
+	}
+	if err != nil {
 		return nil, err
 	}
 	if completedAt.Valid {
@@ -200,18 +512,151 @@ func (r *taskRepository) Update(task *models.Task) (*models.Task, error) {
 	} else {
 		task.CompletedAt = nil
 	}
+	if archivedAt.Valid {
+		v := archivedAt.Time
+		task.ArchivedAt = &v
+	} else {
+		task.ArchivedAt = nil
+	}
 	return task, nil
 }
 
-func (r *taskRepository) Delete(userID, id int64) error {
-	_, err := r.db.Exec(`delete from tasks where id=$1 and user_id=$2`, id, userID)
-	return err
+// SetCompleted flips the completion state and, when next is given, inserts the
+// follow-up occurrence in the same transaction.
+func (r *taskRepository) SetCompleted(userID, id int64, completed bool, version int64, next *models.Task) (*models.Task, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`
+		update tasks set completed=$1, completed_at=case when $1 then now() else null end,
+		       archived_at=case when $1 then archived_at else null end, version=version+1
+		where id=$2 and user_id=$3 and deleted_at is null and ($4::bigint = 0 or version=$4)
+	`, completed, id, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missing(tx, "tasks", userID, id)
+	}
+	if next != nil {
+		if _, err := createTask(tx, next); err != nil {
+			return nil, err
+		}
+	}
+	t, err := getTask(tx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Delete and DeleteMany move tasks to the trash; see TrashRepository.
+func (r *taskRepository) Delete(userID, id, version int64) error {
+	res, err := r.db.Exec(`
+		update tasks set deleted_at=now()
+		where id=$1 and user_id=$2 and deleted_at is null and ($3::bigint = 0 or version=$3)
+	`, id, userID, version)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 && version != 0 {
+		return missing(r.db, "tasks", userID, id)
+	}
+	return nil
+}
+
+func (r *taskRepository) DeleteMany(userID int64, ids []int64) (int64, error) {
+	res, err := r.db.Exec(`update tasks set deleted_at=now() where user_id=$1 and id = any($2) and deleted_at is null`, userID, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
 func (r *taskRepository) ClearCompleted(userID int64) (int64, error) {
-	res, err := r.db.Exec(`delete from tasks where user_id=$1 and completed=true`, userID)
+	res, err := r.db.Exec(`
+		update tasks set archived_at=now(), version=version+1
+		where user_id=$1 and completed and archived_at is null and deleted_at is null
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *taskRepository) Unarchive(userID, id, version int64) (*models.Task, error) {
+	res, err := r.db.Exec(`
+		update tasks set archived_at=null, version=version+1
+		where id=$1 and user_id=$2 and archived_at is not null and deleted_at is null and ($3::bigint = 0 or version=$3)
+	`, id, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, missingArchived(r.db, userID, id)
+	}
+	return getTask(r.db, userID, id)
+}
+
+func (r *taskRepository) ArchiveCompleted(t time.Time) (int64, error) {
+	res, err := r.db.Exec(`
+		update tasks set archived_at=now(), version=version+1
+		where completed and completed_at < $1 and archived_at is null and deleted_at is null
+	`, t)
 	if err != nil {
 		return 0, err
 	}
 	return res.RowsAffected()
 }
+
+func (r *taskRepository) Restore(t *models.Task) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	tags, err := registerTags(tx, t.UserID, t.Tags)
+	if err != nil {
+		return err
+	}
+	res, err := tx.Exec(`
+		insert into tasks (id, user_id, title, description, priority, completed, created_at, due_at, completed_at,
+		                   repeat_rule, category_id, tags, series_id, occurrence_at, version, archived_at, position)
+		values ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15+1,$16,$17)
+		on conflict (id) do update set title=excluded.title, description=excluded.description,
+		       priority=excluded.priority, completed=excluded.completed, created_at=excluded.created_at,
+		       due_at=excluded.due_at, completed_at=excluded.completed_at, repeat_rule=excluded.repeat_rule,
+		       category_id=excluded.category_id, tags=excluded.tags, series_id=excluded.series_id,
+		       occurrence_at=excluded.occurrence_at, version=tasks.version+1, archived_at=excluded.archived_at,
+		       deleted_at=null
+		where tasks.user_id=excluded.user_id
+	`, t.ID, t.UserID, t.Title, t.Description, t.Priority, t.Completed, t.CreatedAt, t.DueDate, t.CompletedAt,
+		t.RepeatRule, t.CategoryID, pq.Array(tags), t.SeriesID, t.OccurrenceAt, t.Version, t.ArchivedAt, t.Position)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	// Trashed subtasks stay in the trash unless t brings them back.
+	if _, err := tx.Exec(`delete from subtasks where task_id=$1 and deleted_at is null`, t.ID); err != nil {
+		return err
+	}
+	for _, st := range t.Subtasks {
+		if _, err := tx.Exec(`
+			insert into subtasks (id, user_id, task_id, title, completed, created_at, version)
+			values ($1,$2,$3,$4,$5,$6,$7+1)
+			on conflict (id) do update set title=excluded.title, completed=excluded.completed,
+			       created_at=excluded.created_at, version=subtasks.version+1, deleted_at=null
+			where subtasks.task_id=excluded.task_id
+		`, st.ID, t.UserID, t.ID, st.Title, st.Completed, st.CreatedAt, st.Version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+
+	"todo-app/backend/internal/models"
+)
+
+// Highlights are produced with control characters as markers, which cannot
+// occur in user text, and only turned into <mark> after HTML escaping.
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+func markHTML(s string) string {
+	return strings.NewReplacer(markStart, "<mark>", markStop, "</mark>").Replace(html.EscapeString(s))
+}
+
+// searchWords splits a term into the words full-text search matches on.
+func searchWords(term string) []string {
+	return strings.FieldsFunc(term, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// tsQuery builds a to_tsquery expression in which every term must match, a
+// phrase as adjacent words, and every word also matches as a prefix so that
+// results appear while the user is still typing.
+func tsQuery(terms, exclude []string) string {
+	phrase := func(term string) string {
+		words := searchWords(term)
+		for i, w := range words {
+			words[i] = "'" + w + "':*"
+		}
+		return strings.Join(words, " <-> ")
+	}
+	var parts []string
+	for _, t := range terms {
+		if p := phrase(t); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	for _, t := range exclude {
+		if p := phrase(t); p != "" {
+			parts = append(parts, "!("+p+")")
+		}
+	}
+	return strings.Join(parts, " & ")
+}
+
+func (r *taskRepository) Search(f models.TaskFilter) ([]models.SearchResult, error) {
+	w := taskFilter(f)
+	tsq := "to_tsquery('russian', " + w.arg(tsQuery(filterTerms(f), f.ExcludeTerms)) + ")"
+	w.add("search @@ " + tsq)
+	titleOpts := w.arg("HighlightAll=true, StartSel=" + markStart + ", StopSel=" + markStop)
+	snippetOpts := w.arg("MaxFragments=2, MaxWords=20, MinWords=8, FragmentDelimiter=\" … \", StartSel=" + markStart + ", StopSel=" + markStop)
+	order := "rank desc, created_at desc, id desc"
+	if f.Sort != "" {
+		order = taskOrder(f.Sort, "lower(title)")
+	}
+	q := `select ` + taskColumns + `,
+		       ts_rank(search, ` + tsq + `) as rank,
+		       ts_headline('russian', title, ` + tsq + `, ` + titleOpts + `),
+		       ts_headline('russian', coalesce(description,''), ` + tsq + `, ` + snippetOpts + `)
+		from tasks where ` + w.String() + ` order by ` + order
+	rows, err := r.db.Query(q, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.SearchResult
+	for rows.Next() {
+		var (
+			sr             models.SearchResult
+			title, snippet string
+		)
+		t, err := scanTask(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &sr.Rank, &title, &snippet)...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		sr.Task, sr.Title, sr.Snippet = t, markHTML(title), markHTML(snippet)
+		res = append(res, sr)
+	}
+	return res, rows.Err()
+}
+
+// scanFunc adapts a function to scanner, so that scanTask can read a row
+// that carries extra columns after the task's.
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
+
+// The SQLite and in-memory repositories have no text search engine. They
+// match terms as substrings of the same fields Postgres indexes and rank
+// with its default weights: title 1, description 0.4, tags 0.2, subtasks 0.1.
+
+type searchFields struct {
+	title, description string
+	tags, subtasks     []string
+}
+
+func (d searchFields) matches(term string) bool {
+	return containsFold(d.title, term) || containsFold(d.description, term) ||
+		anyContainsFold(d.tags, term) || anyContainsFold(d.subtasks, term)
+}
+
+func anyContainsFold(ss []string, sub string) bool {
+	for _, s := range ss {
+		if containsFold(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d searchFields) rank(terms []string) float64 {
+	var rank float64
+	for _, term := range terms {
+		for _, w := range searchWords(term) {
+			if containsFold(d.title, w) {
+				rank += 1
+			}
+			if containsFold(d.description, w) {
+				rank += 0.4
+			}
+			if anyContainsFold(d.tags, w) {
+				rank += 0.2
+			}
+			if anyContainsFold(d.subtasks, w) {
+				rank += 0.1
+			}
+		}
+	}
+	return rank
+}
+
+// highlight wraps every case-insensitive occurrence of the terms' words in s
+// with the markers.
+func highlight(s string, terms []string) string {
+	text := []rune(s)
+	lower := []rune(strings.ToLower(s))
+	if len(lower) != len(text) {
+		lower = text
+	}
+	marked := make([]bool, len(text))
+	for _, term := range terms {
+		for _, w := range searchWords(term) {
+			word := []rune(strings.ToLower(w))
+			for i := 0; i+len(word) <= len(lower); i++ {
+				if string(lower[i:i+len(word)]) == string(word) {
+					for j := i; j < i+len(word); j++ {
+						marked[j] = true
+					}
+				}
+			}
+		}
+	}
+	var b strings.Builder
+	for i, r := range text {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(markStart)
+		}
+		b.WriteRune(r)
+		if marked[i] && (i == len(text)-1 || !marked[i+1]) {
+			b.WriteString(markStop)
+		}
+	}
+	return b.String()
+}
+
+// snippet cuts the description down to about snippetWords words around the
+// first match.
+const snippetWords = 20
+
+func snippet(description string, terms []string) string {
+	if description == "" {
+		return ""
+	}
+	marked := highlight(description, terms)
+	words := strings.Fields(marked)
+	first := 0
+	for i, w := range words {
+		if strings.Contains(w, markStart) {
+			first = i
+			break
+		}
+	}
+	start := max(0, first-snippetWords/4)
+	end := min(len(words), start+snippetWords)
+	out := strings.Join(words[start:end], " ")
+	if start > 0 {
+		out = "… " + out
+	}
+	if end < len(words) {
+		out += " …"
+	}
+	return markHTML(out)
+}
+
+func searchResult(t models.Task, d searchFields, terms []string) models.SearchResult {
+	return models.SearchResult{
+		Task:    t,
+		Rank:    d.rank(terms),
+		Title:   markHTML(highlight(t.Title, terms)),
+		Snippet: snippet(t.Description, terms),
+	}
+}
+
+// sortResults orders by rank like the Postgres query when no sort is given;
+// otherwise the results are already in the requested order.
+func sortResults(res []models.SearchResult, sortBy string) {
+	if sortBy != "" {
+		return
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Rank > res[j].Rank })
+}
+
+func matchesTerms(d searchFields, terms, exclude []string) bool {
+	for _, term := range terms {
+		if !d.matches(term) {
+			return false
+		}
+	}
+	for _, term := range exclude {
+		if d.matches(term) {
+			return false
+		}
+	}
+	return true
+}
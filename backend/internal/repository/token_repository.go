@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+var ErrTokenRevoked = errors.New("refresh token already used")
+
+type TokenRepository interface {
+	Create(userID int64, tokenHash string, expiresAt time.Time) (models.RefreshToken, error)
+	GetByHash(tokenHash string) (models.RefreshToken, error)
+	Rotate(old models.RefreshToken, newHash string, expiresAt time.Time) (models.RefreshToken, error)
+	Revoke(tokenHash string) error
+	RevokeAll(userID int64) error
+}
+
+type tokenRepo struct{ db *sql.DB }
+
+func NewTokenRepository(db *sql.DB) TokenRepository {
+	return &tokenRepo{db: db}
+}
+
+const refreshTokenColumns = `id, user_id, token_hash, created_at, expires_at, revoked_at, replaced_by, coalesce(session_id, id)`
+
+func scanRefreshToken(s scanner) (models.RefreshToken, error) {
+	var (
+		t          models.RefreshToken
+		revokedAt  sql.NullTime
+		replacedBy sql.NullInt64
+	)
+	if err := s.Scan(&t.ID, &t.UserID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &revokedAt, &replacedBy, &t.SessionID); err != nil {
+		return models.RefreshToken{}, err
+	}
+	if revokedAt.Valid {
+		v := revokedAt.Time
+		t.RevokedAt = &v
+	}
+	if replacedBy.Valid {
+		v := replacedBy.Int64
+		t.ReplacedBy = &v
+	}
+	return t, nil
+}
+
+// createRefreshToken starts a new session when sessionID is 0.
+func createRefreshToken(q querier, userID int64, tokenHash string, expiresAt time.Time, sessionID int64) (models.RefreshToken, error) {
+	return scanRefreshToken(q.QueryRow(`
+		insert into refresh_tokens (user_id, token_hash, created_at, expires_at, session_id)
+		values ($1,$2,now(),$3,nullif($4::bigint, 0))
+		returning `+refreshTokenColumns, userID, tokenHash, expiresAt, sessionID))
+}
+
+func (r *tokenRepo) Create(userID int64, tokenHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	return createRefreshToken(r.db, userID, tokenHash, expiresAt, 0)
+}
+
+// GetByHash returns a zero RefreshToken when the hash is unknown.
+func (r *tokenRepo) GetByHash(tokenHash string) (models.RefreshToken, error) {
+	t, err := scanRefreshToken(r.db.QueryRow(`select `+refreshTokenColumns+` from refresh_tokens where token_hash=$1`, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.RefreshToken{}, nil
+	}
+	return t, err
+}
+
+// Rotate revokes old and issues its replacement atomically. It fails with
+// ErrTokenRevoked when old was already rotated by a concurrent caller.
+func (r *tokenRepo) Rotate(old models.RefreshToken, newHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	defer tx.Rollback()
+	next, err := createRefreshToken(tx, old.UserID, newHash, expiresAt, old.SessionID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	res, err := tx.Exec(`update refresh_tokens set revoked_at=now(), replaced_by=$1 where id=$2 and revoked_at is null`, next.ID, old.ID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.RefreshToken{}, ErrTokenRevoked
+	}
+	return next, tx.Commit()
+}
+
+func (r *tokenRepo) Revoke(tokenHash string) error {
+	_, err := r.db.Exec(`update refresh_tokens set revoked_at=now() where token_hash=$1 and revoked_at is null`, tokenHash)
+	return err
+}
+
+func (r *tokenRepo) RevokeAll(userID int64) error {
+	_, err := r.db.Exec(`update refresh_tokens set revoked_at=now() where user_id=$1 and revoked_at is null`, userID)
+	return err
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+// TrashRepository reads and empties the trash that Delete on the task,
+// subtask and category repositories moves rows to.
+type TrashRepository interface {
+	List(userID int64) (models.Trash, error)
+	// RestoreTask, RestoreSubtask and RestoreCategory take a row out of the
+	// trash; ErrNotFound means it is not there. A restored row's version
+	// moves forward.
+	RestoreTask(userID, id int64) (*models.Task, error)
+	RestoreSubtask(userID, id int64) (*models.Subtask, error)
+	RestoreCategory(userID, id int64) (models.Category, error)
+	// Purge permanently deletes everything in the user's trash and returns
+	// how many rows it removed.
+	Purge(userID int64) (int64, error)
+	// PurgeBefore permanently deletes every user's rows trashed before t.
+	PurgeBefore(t time.Time) (int64, error)
+}
+
+type trashRepository struct{ db *sql.DB }
+
+func NewTrashRepository(db *sql.DB) TrashRepository { return &trashRepository{db: db} }
+
+// trashedSubtask matches subtasks trashed on their own; those of a trashed
+// task are restored and purged with it.
+const trashedSubtask = `deleted_at is not null and task_id in (select id from tasks where deleted_at is null)`
+
+func (r *trashRepository) List(userID int64) (models.Trash, error) {
+	var res models.Trash
+	rows, err := r.db.Query(`select `+taskColumns+`, deleted_at from tasks
+		where user_id=$1 and deleted_at is not null order by deleted_at desc, id desc`, userID)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var deletedAt time.Time
+		t, err := scanTask(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &deletedAt)...)
+		}))
+		if err != nil {
+			return res, err
+		}
+		t.DeletedAt = &deletedAt
+		res.Tasks = append(res.Tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return res, err
+	}
+	if res.Subtasks, err = listTrashedSubtasks(r.db, userID); err != nil {
+		return res, err
+	}
+	res.Categories, err = listTrashedCategories(r.db, userID)
+	return res, err
+}
+
+func listTrashedSubtasks(q querier, userID int64) ([]models.Subtask, error) {
+	rows, err := q.Query(`select `+subtaskColumns+`, deleted_at from subtasks
+		where user_id=$1 and `+trashedSubtask+` order by deleted_at desc, id desc`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Subtask
+	for rows.Next() {
+		var (
+			st        models.Subtask
+			deletedAt time.Time
+		)
+		if err := rows.Scan(&st.ID, &st.UserID, &st.TaskID, &st.Title, &st.Completed, &st.CreatedAt, &st.Version, &deletedAt); err != nil {
+			return nil, err
+		}
+		st.DeletedAt = &deletedAt
+		res = append(res, st)
+	}
+	return res, rows.Err()
+}
+
+func listTrashedCategories(q querier, userID int64) ([]models.Category, error) {
+	rows, err := q.Query(`select `+categoryColumns+`, deleted_at from categories
+		where user_id=$1 and deleted_at is not null order by deleted_at desc, id desc`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.Category
+	for rows.Next() {
+		var deletedAt time.Time
+		c, err := scanCategory(scanFunc(func(dest ...any) error {
+			return rows.Scan(append(dest, &deletedAt)...)
+		}))
+		if err != nil {
+			return nil, err
+		}
+		c.DeletedAt = &deletedAt
+		res = append(res, c)
+	}
+	return res, rows.Err()
+}
+
+// restoreCategory untrashes a category and puts it back in the tree.
+func restoreCategory(db *sql.DB, userID, id int64) (models.Category, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return models.Category{}, err
+	}
+	defer tx.Rollback()
+	if err := untrash(tx, "categories", "deleted_at is not null", userID, id); err != nil {
+		return models.Category{}, err
+	}
+	if err := reattachCategory(tx, userID, id); err != nil {
+		return models.Category{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.Category{}, err
+	}
+	return getCategory(db, userID, id)
+}
+
+// untrash clears deleted_at on one of the user's trashed rows of table.
+func untrash(q querier, table, cond string, userID, id int64) error {
+	res, err := q.Exec(`update `+table+` set deleted_at=null, version=version+1
+		where id=$1 and user_id=$2 and `+cond, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *trashRepository) RestoreTask(userID, id int64) (*models.Task, error) {
+	if err := untrash(r.db, "tasks", "deleted_at is not null", userID, id); err != nil {
+		return nil, err
+	}
+	return getTask(r.db, userID, id)
+}
+
+func (r *trashRepository) RestoreSubtask(userID, id int64) (*models.Subtask, error) {
+	if err := untrash(r.db, "subtasks", trashedSubtask, userID, id); err != nil {
+		return nil, err
+	}
+	return NewSubtaskRepository(r.db).Get(userID, id)
+}
+
+func (r *trashRepository) RestoreCategory(userID, id int64) (models.Category, error) {
+	return restoreCategory(r.db, userID, id)
+}
+
+// purge deletes the trashed rows matching cond, whose arguments are args.
+// A trashed task takes its subtasks with it.
+func purge(db *sql.DB, cond string, args ...any) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	var total int64
+	for _, table := range []string{"subtasks", "tasks", "categories"} {
+		res, err := tx.Exec(`delete from `+table+` where deleted_at is not null and `+cond, args...)
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, tx.Commit()
+}
+
+func (r *trashRepository) Purge(userID int64) (int64, error) {
+	return purge(r.db, "user_id=$1", userID)
+}
+
+func (r *trashRepository) PurgeBefore(t time.Time) (int64, error) {
+	return purge(r.db, "deleted_at < $1", t)
+}
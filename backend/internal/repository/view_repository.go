@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"todo-app/backend/internal/models"
+)
+
+type ViewRepository interface {
+	// List returns the user's saved views in position order.
+	List(userID int64) ([]models.SavedView, error)
+	Get(userID, id int64) (*models.SavedView, error)
+	// Create appends the view after the user's other views.
+	Create(v *models.SavedView) (*models.SavedView, error)
+	// Update changes the view's name and filter.
+	Update(v *models.SavedView) (*models.SavedView, error)
+	// Reorder sets each view's position to its index in ids.
+	Reorder(userID int64, ids []int64) error
+	Delete(userID, id int64) error
+}
+
+type viewRepository struct{ db *sql.DB }
+
+func NewViewRepository(db *sql.DB) ViewRepository { return &viewRepository{db: db} }
+
+const viewColumns = `id, user_id, name, filter, position`
+
+func scanView(s scanner) (*models.SavedView, error) {
+	var (
+		v      models.SavedView
+		filter []byte
+	)
+	err := s.Scan(&v.ID, &v.UserID, &v.Name, &filter, &v.Position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &v.Filter); err != nil {
+		return nil, err
+	}
+	v.Filter.UserID = v.UserID
+	return &v, nil
+}
+
+func queryViews(q querier, query string, args ...any) ([]models.SavedView, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []models.SavedView
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *v)
+	}
+	return res, rows.Err()
+}
+
+func encodeFilter(f models.TaskFilter) (string, error) {
+	b, err := json.Marshal(f)
+	return string(b), err
+}
+
+func (r *viewRepository) List(userID int64) ([]models.SavedView, error) {
+	return queryViews(r.db, `select `+viewColumns+` from saved_views where user_id=$1 order by position, id`, userID)
+}
+
+func (r *viewRepository) Get(userID, id int64) (*models.SavedView, error) {
+	return scanView(r.db.QueryRow(`select `+viewColumns+` from saved_views where user_id=$1 and id=$2`, userID, id))
+}
+
+func (r *viewRepository) Create(v *models.SavedView) (*models.SavedView, error) {
+	filter, err := encodeFilter(v.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return scanView(r.db.QueryRow(`
+		insert into saved_views (user_id, name, filter, position)
+		values ($1, $2, $3, (select coalesce(max(position) + 1, 0) from saved_views where user_id=$1))
+		returning `+viewColumns,
+		v.UserID, v.Name, filter,
+	))
+}
+
+func (r *viewRepository) Update(v *models.SavedView) (*models.SavedView, error) {
+	filter, err := encodeFilter(v.Filter)
+	if err != nil {
+		return nil, err
+	}
+	return scanView(r.db.QueryRow(`
+		update saved_views set name=$1, filter=$2 where id=$3 and user_id=$4
+		returning `+viewColumns,
+		v.Name, filter, v.ID, v.UserID,
+	))
+}
+
+func reorderViews(db *sql.DB, userID int64, ids []int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i, id := range ids {
+		res, err := tx.Exec(`update saved_views set position=$1 where id=$2 and user_id=$3`, i, id, userID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *viewRepository) Reorder(userID int64, ids []int64) error {
+	return reorderViews(r.db, userID, ids)
+}
+
+func deleteView(db *sql.DB, userID, id int64) error {
+	res, err := db.Exec(`delete from saved_views where id=$1 and user_id=$2`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *viewRepository) Delete(userID, id int64) error {
+	return deleteView(r.db, userID, id)
+}
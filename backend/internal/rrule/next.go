@@ -0,0 +1,140 @@
+package rrule
+
+import "time"
+
+// maxPeriods bounds the search so that a rule which can never fire again
+// (BYMONTHDAY=30 every 12 months starting in February) ends instead of
+// spinning.
+const maxPeriods = 4000
+
+// civil truncates t to its calendar day. Days are counted in UTC so that date
+// arithmetic never meets a daylight saving transition.
+func civil(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Next returns the occurrence after cur, the current one. The result keeps
+// cur's wall-clock time in cur's location, so a task due at 09:00 stays at
+// 09:00 across daylight saving changes. ok is false once COUNT or UNTIL is
+// exhausted. COUNT includes cur, so the follow-up should carry Advance().
+func (r Rule) Next(cur time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+	day := civil(cur)
+	interval := max(r.Interval, 1)
+	for k := 0; k < maxPeriods; k++ {
+		for _, c := range r.candidates(day, k*interval) {
+			if !c.After(day) {
+				continue
+			}
+			next := time.Date(c.Year(), c.Month(), c.Day(), cur.Hour(), cur.Minute(), cur.Second(), cur.Nanosecond(), cur.Location())
+			if r.pastUntil(c, next) {
+				return time.Time{}, false
+			}
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Advance returns the rule that governs the occurrence after the current one:
+// a COUNT shrinks by one, everything else is unchanged.
+func (r Rule) Advance() Rule {
+	if r.Count > 1 {
+		r.Count--
+	}
+	return r
+}
+
+// candidates lists, in order, the days of the period that lies offset
+// periods (days, weeks, months or years) after the one containing day.
+func (r Rule) candidates(day time.Time, offset int) []time.Time {
+	var res []time.Time
+	switch r.Freq {
+	case Daily:
+		d := day.AddDate(0, 0, offset)
+		if r.matchesWeekday(d) && r.matchesMonthDay(d) {
+			res = append(res, d)
+		}
+	case Weekly:
+		start := day.AddDate(0, 0, -((int(day.Weekday())-int(r.WeekStart)+7)%7)+7*offset)
+		for i := 0; i < 7; i++ {
+			d := start.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && d.Weekday() != day.Weekday() {
+				continue
+			}
+			if r.matchesWeekday(d) && r.matchesMonthDay(d) {
+				res = append(res, d)
+			}
+		}
+	case Monthly:
+		first := time.Date(day.Year(), day.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+			if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && d.Day() != day.Day() {
+				continue
+			}
+			if r.matchesWeekday(d) && r.matchesMonthDay(d) {
+				res = append(res, d)
+			}
+		}
+	case Yearly:
+		// Feb 29 only recurs in leap years; time.Date would roll it over to
+		// March 1 otherwise.
+		d := time.Date(day.Year()+offset, day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+		if d.Month() == day.Month() {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+func daysIn(d time.Time) int {
+	return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (r Rule) matchesWeekday(d time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day != d.Weekday() {
+			continue
+		}
+		switch {
+		case wd.N == 0:
+			return true
+		case wd.N > 0 && (d.Day()-1)/7+1 == wd.N:
+			return true
+		case wd.N < 0 && (daysIn(d)-d.Day())/7+1 == -wd.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchesMonthDay(d time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	for _, n := range r.ByMonthDay {
+		if n > 0 && d.Day() == n || n < 0 && d.Day() == daysIn(d)+1+n {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) pastUntil(day, next time.Time) bool {
+	switch {
+	case r.Until.IsZero():
+		return false
+	case r.UntilDate:
+		return day.After(r.Until)
+	case r.Until.Location() == floating:
+		wall := time.Date(next.Year(), next.Month(), next.Day(), next.Hour(), next.Minute(), next.Second(), next.Nanosecond(), floating)
+		return wall.After(r.Until)
+	default:
+		return next.After(r.Until)
+	}
+}
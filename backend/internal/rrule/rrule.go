@@ -0,0 +1,355 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalid = errors.New("invalid repeat rule")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry: a weekday with an optional ordinal, so that
+// -1FR reads "the last Friday" and plain FR "every Friday".
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is the subset of an iCalendar RRULE (RFC 5545) that tasks repeat by.
+// The zero Count and Until mean the rule repeats forever.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+	// UntilDate marks an UNTIL given as a date: it then bounds occurrences by
+	// their calendar day in the task's time zone rather than by instant.
+	UntilDate bool
+	WeekStart time.Weekday
+}
+
+// floating marks an UNTIL date-time written without a zone.
+var floating = time.FixedZone("floating", 0)
+
+var dayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func parseDayCode(s string) (time.Weekday, bool) {
+	for i, c := range dayCodes {
+		if c == s {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalid}, args...)...)
+}
+
+// Parse accepts an RRULE value ("FREQ=WEEKLY;BYDAY=MO,WE", optionally with
+// the "RRULE:" prefix) or one of the English phrases understood by
+// parsePhrase, such as "every weekday" or "last Friday of the month".
+func Parse(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Rule{}, invalid("empty rule")
+	}
+	upper := strings.ToUpper(s)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") || strings.Contains(upper, ";") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	}
+	r, ok := parsePhrase(strings.ToLower(s))
+	if !ok {
+		return Rule{}, invalid("%q is neither an RRULE nor a known phrase", s)
+	}
+	return r, nil
+}
+
+func parseRRule(s string) (Rule, error) {
+	r := Rule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(strings.Trim(s, ";"), ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, invalid("malformed part %q", part)
+		}
+		if seen[key] {
+			return Rule{}, invalid("%s given twice", key)
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = invalid("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(key, value)
+		case "COUNT":
+			r.Count, err = positive(key, value)
+		case "UNTIL":
+			r.Until, r.UntilDate, err = parseUntil(value)
+		case "WKST":
+			var ok bool
+			if r.WeekStart, ok = parseDayCode(value); !ok {
+				err = invalid("bad WKST %q", value)
+			}
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		default:
+			err = invalid("unsupported part %s", key)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+	return r, r.validate()
+}
+
+func positive(key, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, invalid("%s must be a positive number, got %q", key, value)
+	}
+	return n, nil
+}
+
+func parseUntil(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, false, nil
+	}
+	// A floating date-time has no zone; it is compared with the wall clock
+	// of occurrences in the task's time zone.
+	if t, err := time.ParseInLocation("20060102T150405", value, floating); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, invalid("bad UNTIL %q", value)
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var res []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, invalid("bad BYDAY entry %q", item)
+		}
+		day, ok := parseDayCode(item[len(item)-2:])
+		if !ok {
+			return nil, invalid("bad BYDAY entry %q", item)
+		}
+		wd := WeekdayNum{Day: day}
+		if num := item[:len(item)-2]; num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, invalid("bad BYDAY ordinal in %q", item)
+			}
+			wd.N = n
+		}
+		res = append(res, wd)
+	}
+	return res, nil
+}
+
+func parseByMonthDay(value string) ([]int, error) {
+	var res []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < -31 || n > 31 {
+			return nil, invalid("bad BYMONTHDAY entry %q", item)
+		}
+		res = append(res, n)
+	}
+	return res, nil
+}
+
+func (r Rule) validate() error {
+	if r.Freq == "" {
+		return invalid("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return invalid("COUNT and UNTIL are mutually exclusive")
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly {
+			return invalid("BYDAY ordinals are only supported with FREQ=MONTHLY")
+		}
+	}
+	if r.Freq == Yearly && (len(r.ByDay) > 0 || len(r.ByMonthDay) > 0) {
+		return invalid("BYDAY and BYMONTHDAY are not supported with FREQ=YEARLY")
+	}
+	return nil
+}
+
+// String renders the rule as a canonical RRULE value, which is what tasks
+// store regardless of how the rule was entered.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+dayCodes[r.WeekStart])
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = dayCodes[d.Day]
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		switch {
+		case r.UntilDate:
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		case r.Until.Location() == floating:
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102T150405"))
+		default:
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+var (
+	weekdayNames = map[string]time.Weekday{
+		"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+		"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+		"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	}
+	ordinals = map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "last": -1}
+	units    = map[string]Frequency{
+		"day": Daily, "days": Daily, "week": Weekly, "weeks": Weekly,
+		"month": Monthly, "months": Monthly, "year": Yearly, "years": Yearly,
+	}
+)
+
+// parsePhrase understands the handful of English forms the UI offers:
+//
+//	daily, weekly, monthly, yearly
+//	every day | every 2 weeks | every weekday | every weekend
+//	every monday, wednesday and friday
+//	last friday of the month | second tuesday of every month
+//	last day of the month
+func parsePhrase(s string) (Rule, bool) {
+	r := Rule{Interval: 1, WeekStart: time.Monday}
+	switch s {
+	case "daily":
+		r.Freq = Daily
+		return r, true
+	case "weekly":
+		r.Freq = Weekly
+		return r, true
+	case "monthly":
+		r.Freq = Monthly
+		return r, true
+	case "yearly", "annually":
+		r.Freq = Yearly
+		return r, true
+	case "weekdays", "every weekday":
+		r.Freq = Weekly
+		for d := time.Monday; d <= time.Friday; d++ {
+			r.ByDay = append(r.ByDay, WeekdayNum{Day: d})
+		}
+		return r, true
+	case "weekends", "every weekend":
+		r.Freq = Weekly
+		r.ByDay = []WeekdayNum{{Day: time.Saturday}, {Day: time.Sunday}}
+		return r, true
+	}
+
+	words := strings.Fields(strings.NewReplacer(",", " ", " and ", " ").Replace(s))
+	if len(words) >= 4 && (strings.Join(words[len(words)-3:], " ") == "of the month" || strings.Join(words[len(words)-3:], " ") == "of every month") {
+		words = words[:len(words)-3]
+		if len(words) != 2 {
+			return Rule{}, false
+		}
+		n, ok := ordinals[words[0]]
+		if !ok {
+			return Rule{}, false
+		}
+		r.Freq = Monthly
+		if words[1] == "day" {
+			r.ByMonthDay = []int{n}
+			return r, true
+		}
+		day, ok := weekdayNames[words[1]]
+		if !ok {
+			return Rule{}, false
+		}
+		r.ByDay = []WeekdayNum{{N: n, Day: day}}
+		return r, true
+	}
+
+	if len(words) < 2 || words[0] != "every" {
+		return Rule{}, false
+	}
+	words = words[1:]
+	if n, err := strconv.Atoi(words[0]); err == nil && len(words) == 2 && n > 0 {
+		freq, ok := units[words[1]]
+		if !ok {
+			return Rule{}, false
+		}
+		r.Freq, r.Interval = freq, n
+		return r, true
+	}
+	if len(words) == 1 {
+		if freq, ok := units[words[0]]; ok {
+			r.Freq = freq
+			return r, true
+		}
+	}
+	seen := map[time.Weekday]bool{}
+	for _, w := range words {
+		day, ok := weekdayNames[strings.TrimSuffix(w, "s")]
+		if !ok {
+			day, ok = weekdayNames[w]
+		}
+		if !ok {
+			return Rule{}, false
+		}
+		seen[day] = true
+	}
+	r.Freq = Weekly
+	for day := range seen {
+		r.ByDay = append(r.ByDay, WeekdayNum{Day: day})
+	}
+	sort.Slice(r.ByDay, func(i, j int) bool {
+		return (r.ByDay[i].Day+6)%7 < (r.ByDay[j].Day+6)%7
+	})
+	return r, true
+}
@@ -0,0 +1,181 @@
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCanonicalizes(t *testing.T) {
+	cases := map[string]string{
+		"daily":                              "FREQ=DAILY",
+		"Every 2 weeks":                      "FREQ=WEEKLY;INTERVAL=2",
+		"every weekday":                      "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR",
+		"every friday, monday and wed":       "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+		"last Friday of the month":           "FREQ=MONTHLY;BYDAY=-1FR",
+		"second tuesday of every month":      "FREQ=MONTHLY;BYDAY=2TU",
+		"last day of the month":              "FREQ=MONTHLY;BYMONTHDAY=-1",
+		"rrule:freq=monthly;bymonthday=1,15": "FREQ=MONTHLY;BYMONTHDAY=1,15",
+		"FREQ=DAILY;COUNT=3;INTERVAL=1":      "FREQ=DAILY;COUNT=3",
+		"FREQ=WEEKLY;UNTIL=20250301":         "FREQ=WEEKLY;UNTIL=20250301",
+		"FREQ=WEEKLY;UNTIL=20250301T120000Z": "FREQ=WEEKLY;UNTIL=20250301T120000Z",
+		"FREQ=WEEKLY;WKST=SU;BYDAY=SU,SA":    "FREQ=WEEKLY;WKST=SU;BYDAY=SU,SA",
+	}
+	for in, want := range cases {
+		r, err := Parse(in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", in, err)
+			continue
+		}
+		if got := r.String(); got != want {
+			t.Errorf("Parse(%q) = %s, want %s", in, got, want)
+		}
+		again, err := Parse(r.String())
+		if err != nil || again.String() != want {
+			t.Errorf("%s does not round-trip: %v", want, err)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"fortnightly-ish",
+		"FREQ=HOURLY",
+		"INTERVAL=2",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=YEARLY;BYDAY=MO",
+	} {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) = %v, want ErrInvalid", in, err)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Rule {
+	t.Helper()
+	r, err := Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// occurrences follows the rule the way completed tasks do: each occurrence
+// spawns the next with the advanced rule.
+func occurrences(t *testing.T, rule string, start time.Time, n int) []time.Time {
+	t.Helper()
+	r := mustParse(t, rule)
+	res := []time.Time{start}
+	cur := start
+	for len(res) < n {
+		next, ok := r.Next(cur)
+		if !ok {
+			break
+		}
+		res = append(res, next)
+		cur, r = next, r.Advance()
+	}
+	return res
+}
+
+func dates(ts []time.Time) []string {
+	res := make([]string, len(ts))
+	for i, t := range ts {
+		res[i] = t.Format("2006-01-02 Mon 15:04")
+	}
+	return res
+}
+
+func TestNext(t *testing.T) {
+	utc := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 9, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		rule  string
+		start time.Time
+		n     int
+		want  []string
+	}{
+		{"every weekday", utc(2025, 1, 9), 5, []string{
+			"2025-01-09 Thu 09:00", "2025-01-10 Fri 09:00", "2025-01-13 Mon 09:00", "2025-01-14 Tue 09:00", "2025-01-15 Wed 09:00",
+		}},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", utc(2025, 1, 6), 5, []string{
+			"2025-01-06 Mon 09:00", "2025-01-10 Fri 09:00", "2025-01-20 Mon 09:00", "2025-01-24 Fri 09:00", "2025-02-03 Mon 09:00",
+		}},
+		{"last friday of the month", utc(2025, 1, 31), 4, []string{
+			"2025-01-31 Fri 09:00", "2025-02-28 Fri 09:00", "2025-03-28 Fri 09:00", "2025-04-25 Fri 09:00",
+		}},
+		{"monthly", utc(2025, 1, 31), 3, []string{
+			"2025-01-31 Fri 09:00", "2025-03-31 Mon 09:00", "2025-05-31 Sat 09:00",
+		}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", utc(2025, 1, 31), 3, []string{
+			"2025-01-31 Fri 09:00", "2025-02-28 Fri 09:00", "2025-03-31 Mon 09:00",
+		}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", utc(2025, 1, 15), 4, []string{
+			"2025-01-15 Wed 09:00", "2025-02-01 Sat 09:00", "2025-02-15 Sat 09:00", "2025-03-01 Sat 09:00",
+		}},
+		{"FREQ=DAILY;INTERVAL=3;COUNT=3", utc(2025, 1, 1), 10, []string{
+			"2025-01-01 Wed 09:00", "2025-01-04 Sat 09:00", "2025-01-07 Tue 09:00",
+		}},
+		{"FREQ=WEEKLY;UNTIL=20250115", utc(2025, 1, 1), 10, []string{
+			"2025-01-01 Wed 09:00", "2025-01-08 Wed 09:00", "2025-01-15 Wed 09:00",
+		}},
+		{"FREQ=DAILY;UNTIL=20250102T085959Z", utc(2025, 1, 1), 10, []string{
+			"2025-01-01 Wed 09:00",
+		}},
+		{"yearly", utc(2024, 2, 29), 3, []string{
+			"2024-02-29 Thu 09:00", "2028-02-29 Tue 09:00", "2032-02-29 Sun 09:00",
+		}},
+	}
+	for _, c := range cases {
+		got := dates(occurrences(t, c.rule, c.start, c.n))
+		if len(got) != len(c.want) {
+			t.Errorf("%s: got %v, want %v", c.rule, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: got %v, want %v", c.rule, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestNextKeepsWallClockAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tz database not available")
+	}
+	// Clocks go forward on 2025-03-30 and back on 2025-10-26.
+	start := time.Date(2025, 3, 29, 9, 0, 0, 0, berlin)
+	r := mustParse(t, "daily")
+	next, ok := r.Next(start)
+	if !ok {
+		t.Fatal("no next occurrence")
+	}
+	if next.Hour() != 9 || next.Day() != 30 {
+		t.Fatalf("next = %v, want 2025-03-30 09:00 local", next)
+	}
+	if d := next.Sub(start); d != 23*time.Hour {
+		t.Fatalf("spring-forward day should be 23h long, got %v", d)
+	}
+
+	start = time.Date(2025, 10, 25, 9, 0, 0, 0, berlin)
+	next, _ = mustParse(t, "weekly").Next(start)
+	if next.Hour() != 9 || next.Day() != 1 || next.Month() != time.November {
+		t.Fatalf("next = %v, want 2025-11-01 09:00 local", next)
+	}
+}
+
+func TestNextGivesUpOnImpossibleRules(t *testing.T) {
+	r := mustParse(t, "FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=30")
+	if next, ok := r.Next(time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)); ok {
+		t.Fatalf("impossible rule produced %v", next)
+	}
+}
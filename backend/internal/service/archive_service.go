@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"todo-app/backend/internal/repository"
+)
+
+// ArchiveService archives tasks some time after they were completed, as if
+// ClearCompleted had been called on them.
+type ArchiveService struct {
+	repo  repository.TaskRepository
+	after time.Duration
+	now   func() time.Time
+}
+
+// NewArchiveService archives tasks completed more than after ago; 0 or less
+// leaves them until completed tasks are cleared by hand.
+func NewArchiveService(r repository.TaskRepository, after time.Duration) *ArchiveService {
+	return &ArchiveService{repo: r, after: after, now: time.Now}
+}
+
+func (s *ArchiveService) ArchiveExpired() (int64, error) {
+	if s.after <= 0 {
+		return 0, nil
+	}
+	return s.repo.ArchiveCompleted(s.now().Add(-s.after))
+}
+
+// RunArchive calls ArchiveExpired right away and then every interval until
+// ctx is done.
+func (s *ArchiveService) RunArchive(ctx context.Context, every time.Duration) {
+	if s.after <= 0 {
+		return
+	}
+	runEvery(ctx, every, "archive", s.ArchiveExpired)
+}
@@ -2,55 +2,193 @@ package service
 
 import (
 	"errors"
+	"net/mail"
+	"strings"
+	"time"
 
+	"todo-app/backend/internal/config"
 	"todo-app/backend/internal/models"
 	"todo-app/backend/internal/repository"
+	"todo-app/backend/internal/token"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUserExists         = errors.New("user already exists")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidEmail       = errors.New("invalid email")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrSessionExpired     = errors.New("session expired, please log in again")
+	ErrTokenExpired       = token.ErrExpired
+	ErrInvalidToken       = token.ErrInvalid
+)
+
+const minPasswordLen = 8
+
 type AuthService interface {
 	Register(email, password string) (models.UserPublic, error)
-	Login(email, password string) (models.UserPublic, error)
+	Login(email, password string) (models.Session, error)
+	Refresh(refreshToken string) (models.Session, error)
+	Logout(refreshToken string) error
+	Verify(accessToken string) (models.UserPublic, error)
 	GetProfile(userID int64) (models.UserPublic, error)
 }
 
 type authService struct {
-	repo repository.AuthRepository
+	repo       repository.AuthRepository
+	tokens     repository.TokenRepository
+	signer     *token.Signer
+	refreshTTL time.Duration
+	now        func() time.Time
 }
 
-func NewAuthService(r repository.AuthRepository) AuthService {
-	return &authService{repo: r}
+func NewAuthService(r repository.AuthRepository, tokens repository.TokenRepository, cfg config.Config) AuthService {
+	return &authService{
+		repo:       r,
+		tokens:     tokens,
+		signer:     token.NewSigner(cfg.JWTSecret, cfg.AccessTTL),
+		refreshTTL: cfg.RefreshTTL,
+		now:        time.Now,
+	}
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 func (s *authService) Register(email, password string) (models.UserPublic, error) {
-	existing, _ := s.repo.GetUserByEmail(email)
+	email = normalizeEmail(email)
+	if _, err := mail.ParseAddress(email); err != nil {
+		return models.UserPublic{}, ErrInvalidEmail
+	}
+	if len(password) < minPasswordLen {
+		return models.UserPublic{}, ErrWeakPassword
+	}
+	existing, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		return models.UserPublic{}, err
+	}
 	if existing.ID != 0 {
-		return models.UserPublic{}, errors.New("user already exists")
+		return models.UserPublic{}, ErrUserExists
 	}
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return models.UserPublic{}, err
 	}
-	u, err := s.repo.CreateUser(email, string(hash))
+	u, err := s.repo.ClaimLegacyUser(email, string(hash))
 	if err != nil {
 		return models.UserPublic{}, err
 	}
+	if u.ID == 0 {
+		u, err = s.repo.CreateUser(email, string(hash))
+		if err != nil {
+			return models.UserPublic{}, err
+		}
+	}
 	return models.UserPublic{ID: u.ID, Email: u.Email}, nil
 }
 
-func (s *authService) Login(email, password string) (models.UserPublic, error) {
-	u, err := s.repo.GetUserByEmail(email)
+func (s *authService) Login(email, password string) (models.Session, error) {
+	u, err := s.repo.GetUserByEmail(normalizeEmail(email))
 	if err != nil {
-		return models.UserPublic{}, err
+		return models.Session{}, err
 	}
-	if u.ID == 0 {
-		return models.UserPublic{}, errors.New("invalid email or password")
+	if u.ID == 0 || u.PasswordHash == "" {
+		return models.Session{}, ErrInvalidCredentials
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return models.UserPublic{}, errors.New("invalid email or password")
+		return models.Session{}, ErrInvalidCredentials
 	}
-	return models.UserPublic{ID: u.ID, Email: u.Email}, nil
+	raw, hash, err := token.NewRefreshToken()
+	if err != nil {
+		return models.Session{}, err
+	}
+	rt, err := s.tokens.Create(u.ID, hash, s.now().Add(s.refreshTTL))
+	if err != nil {
+		return models.Session{}, err
+	}
+	return s.session(models.UserPublic{ID: u.ID, Email: u.Email}, raw, rt)
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// works once; presenting one that was already rotated revokes every session of
+// its owner, since only a leaked copy can still be holding it.
+func (s *authService) Refresh(refreshToken string) (models.Session, error) {
+	rt, err := s.tokens.GetByHash(token.HashRefreshToken(refreshToken))
+	if err != nil {
+		return models.Session{}, err
+	}
+	if rt.ID == 0 {
+		return models.Session{}, ErrSessionExpired
+	}
+	if rt.RevokedAt != nil {
+		if rt.ReplacedBy != nil {
+			if err := s.tokens.RevokeAll(rt.UserID); err != nil {
+				return models.Session{}, err
+			}
+		}
+		return models.Session{}, ErrSessionExpired
+	}
+	if !s.now().Before(rt.ExpiresAt) {
+		return models.Session{}, ErrSessionExpired
+	}
+	u, err := s.repo.GetUserByID(rt.UserID)
+	if err != nil {
+		return models.Session{}, err
+	}
+	if u.ID == 0 {
+		return models.Session{}, ErrSessionExpired
+	}
+	raw, hash, err := token.NewRefreshToken()
+	if err != nil {
+		return models.Session{}, err
+	}
+	next, err := s.tokens.Rotate(rt, hash, s.now().Add(s.refreshTTL))
+	if errors.Is(err, repository.ErrTokenRevoked) {
+		if err := s.tokens.RevokeAll(rt.UserID); err != nil {
+			return models.Session{}, err
+		}
+		return models.Session{}, ErrSessionExpired
+	}
+	if err != nil {
+		return models.Session{}, err
+	}
+	return s.session(models.UserPublic{ID: u.ID, Email: u.Email}, raw, next)
+}
+
+func (s *authService) Logout(refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+	return s.tokens.Revoke(token.HashRefreshToken(refreshToken))
+}
+
+func (s *authService) Verify(accessToken string) (models.UserPublic, error) {
+	c, err := s.signer.Verify(accessToken)
+	if err != nil {
+		return models.UserPublic{}, err
+	}
+	id, err := c.UserID()
+	if err != nil {
+		return models.UserPublic{}, err
+	}
+	return models.UserPublic{ID: id, Email: c.Email, SessionID: c.SessionID}, nil
+}
+
+func (s *authService) session(u models.UserPublic, refreshToken string, rt models.RefreshToken) (models.Session, error) {
+	access, exp, err := s.signer.Sign(u.ID, u.Email, rt.SessionID)
+	if err != nil {
+		return models.Session{}, err
+	}
+	return models.Session{
+		User:             u,
+		AccessToken:      access,
+		AccessExpiresAt:  exp,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: rt.ExpiresAt,
+	}, nil
 }
 
 func (s *authService) GetProfile(userID int64) (models.UserPublic, error) {
@@ -59,7 +197,7 @@ func (s *authService) GetProfile(userID int64) (models.UserPublic, error) {
 		return models.UserPublic{}, err
 	}
 	if u.ID == 0 {
-		return models.UserPublic{}, errors.New("user not found")
+		return models.UserPublic{}, ErrUserNotFound
 	}
 	return models.UserPublic{ID: u.ID, Email: u.Email}, nil
 }
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"todo-app/backend/internal/config"
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+var testAuthConfig = config.Config{JWTSecret: "test-secret", AccessTTL: time.Minute, RefreshTTL: time.Hour}
+
+type fakeTokenRepo struct {
+	tokens []models.RefreshToken
+}
+
+func (r *fakeTokenRepo) Create(userID int64, tokenHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	id := int64(len(r.tokens) + 1)
+	t := models.RefreshToken{ID: id, UserID: userID, TokenHash: tokenHash, CreatedAt: time.Now(), ExpiresAt: expiresAt, SessionID: id}
+	r.tokens = append(r.tokens, t)
+	return t, nil
+}
+
+func (r *fakeTokenRepo) GetByHash(tokenHash string) (models.RefreshToken, error) {
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return models.RefreshToken{}, nil
+}
+
+func (r *fakeTokenRepo) Rotate(old models.RefreshToken, newHash string, expiresAt time.Time) (models.RefreshToken, error) {
+	cur := &r.tokens[old.ID-1]
+	if cur.RevokedAt != nil {
+		return models.RefreshToken{}, repository.ErrTokenRevoked
+	}
+	next, _ := r.Create(old.UserID, newHash, expiresAt)
+	next.SessionID = old.SessionID
+	r.tokens[next.ID-1] = next
+	now := time.Now()
+	cur = &r.tokens[old.ID-1]
+	cur.RevokedAt = &now
+	cur.ReplacedBy = &next.ID
+	return next, nil
+}
+
+func (r *fakeTokenRepo) Revoke(tokenHash string) error {
+	for i := range r.tokens {
+		if r.tokens[i].TokenHash == tokenHash && r.tokens[i].RevokedAt == nil {
+			now := time.Now()
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokenRepo) RevokeAll(userID int64) error {
+	for i := range r.tokens {
+		if r.tokens[i].UserID == userID && r.tokens[i].RevokedAt == nil {
+			now := time.Now()
+			r.tokens[i].RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeAuthRepo struct {
+	users []models.User
+}
+
+func (r *fakeAuthRepo) CreateUser(email, passwordHash string) (models.User, error) {
+	u := models.User{ID: int64(len(r.users) + 1), Email: email, PasswordHash: passwordHash}
+	r.users = append(r.users, u)
+	return u, nil
+}
+
+func (r *fakeAuthRepo) GetUserByEmail(email string) (models.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return models.User{}, nil
+}
+
+func (r *fakeAuthRepo) GetUserByID(id int64) (models.User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return models.User{}, nil
+}
+
+func (r *fakeAuthRepo) ClaimLegacyUser(email, passwordHash string) (models.User, error) {
+	for _, u := range r.users {
+		if u.PasswordHash != "" {
+			return models.User{}, nil
+		}
+	}
+	for i, u := range r.users {
+		if u.PasswordHash == "" {
+			r.users[i].Email = email
+			r.users[i].PasswordHash = passwordHash
+			return r.users[i], nil
+		}
+	}
+	return models.User{}, nil
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	svc := NewAuthService(&fakeAuthRepo{}, &fakeTokenRepo{}, testAuthConfig)
+
+	if _, err := svc.Register("not-an-email", "password123"); !errors.Is(err, ErrInvalidEmail) {
+		t.Fatalf("expected ErrInvalidEmail, got %v", err)
+	}
+	if _, err := svc.Register("a@example.com", "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Fatalf("expected ErrWeakPassword, got %v", err)
+	}
+
+	u, err := svc.Register(" A@Example.com ", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "a@example.com" {
+		t.Errorf("email not normalized: %q", u.Email)
+	}
+	if _, err := svc.Register("a@example.com", "password123"); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("expected ErrUserExists, got %v", err)
+	}
+
+	if _, err := svc.Login("a@example.com", "wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	got, err := svc.Login("A@EXAMPLE.COM", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.User.ID != u.ID {
+		t.Errorf("logged in as %d, want %d", got.User.ID, u.ID)
+	}
+	verified, err := svc.Verify(got.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.ID != u.ID {
+		t.Errorf("access token carries user %d, want %d", verified.ID, u.ID)
+	}
+}
+
+func TestRegisterClaimsLegacyUserOnce(t *testing.T) {
+	repo := &fakeAuthRepo{users: []models.User{{ID: 1, Email: "user1@localhost"}}}
+	svc := NewAuthService(repo, &fakeTokenRepo{}, testAuthConfig)
+
+	first, err := svc.Register("owner@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != 1 {
+		t.Fatalf("first account should take over legacy user 1, got %d", first.ID)
+	}
+	second, err := svc.Register("guest@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == 1 {
+		t.Fatal("second account must not reuse the legacy user")
+	}
+	if _, err := svc.Login("user1@localhost", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("placeholder login should fail, got %v", err)
+	}
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	tokens := &fakeTokenRepo{}
+	svc := NewAuthService(&fakeAuthRepo{}, tokens, testAuthConfig)
+	if _, err := svc.Register("a@example.com", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	first, err := svc.Login("a@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := svc.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	a, _ := svc.Verify(first.AccessToken)
+	b, _ := svc.Verify(second.AccessToken)
+	if a.SessionID == 0 || b.SessionID != a.SessionID {
+		t.Fatalf("refreshed access token has session %d, want %d", b.SessionID, a.SessionID)
+	}
+
+	if _, err := svc.Refresh(first.RefreshToken); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("reusing a rotated token: got %v", err)
+	}
+	if _, err := svc.Refresh(second.RefreshToken); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("reuse must revoke the whole session family, got %v", err)
+	}
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	svc := NewAuthService(&fakeAuthRepo{}, &fakeTokenRepo{}, testAuthConfig)
+	if _, err := svc.Register("a@example.com", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	sess, err := svc.Login("a@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Logout(sess.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Refresh(sess.RefreshToken); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("expected ErrSessionExpired after logout, got %v", err)
+	}
+}
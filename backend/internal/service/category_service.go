@@ -1,3 +1,194 @@
 package service
 
-type CategoryService struct{}
+import (
+	"errors"
+	"strings"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+var (
+	ErrNameRequired    = errors.New("name is required")
+	ErrCategoryExists  = errors.New("a category with this name already exists here")
+	ErrParentNotFound  = errors.New("parent category not found")
+	ErrCategoryCycle   = errors.New("cannot move a category into itself or its subcategories")
+	ErrInvalidDeletion = errors.New(`tasks must be "move", "clear" or "delete"`)
+	ErrMoveTarget      = errors.New("tasks must move to another existing category")
+)
+
+type CategoryService struct {
+	repo repository.CategoryRepository
+}
+
+func NewCategoryService(r repository.CategoryRepository) *CategoryService {
+	return &CategoryService{repo: r}
+}
+
+// List returns the category tree depth first, each category followed by its
+// subcategories in order, with TotalCount set.
+func (s *CategoryService) List(userID int64) ([]models.Category, error) {
+	cs, err := s.repo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	return tree(cs), nil
+}
+
+// tree orders cs, which are sorted by position, depth first.
+func tree(cs []models.Category) []models.Category {
+	ids := map[int64]bool{}
+	for _, c := range cs {
+		ids[c.ID] = true
+	}
+	children := map[int64][]models.Category{}
+	for _, c := range cs {
+		var parent int64
+		if c.ParentID != nil && ids[*c.ParentID] {
+			parent = *c.ParentID
+		}
+		children[parent] = append(children[parent], c)
+	}
+	res := make([]models.Category, 0, len(cs))
+	var walk func(parent int64) int
+	walk = func(parent int64) int {
+		total := 0
+		for _, c := range children[parent] {
+			i := len(res)
+			res = append(res, c)
+			res[i].TotalCount = c.TaskCount + walk(c.ID)
+			total += res[i].TotalCount
+		}
+		return total
+	}
+	walk(0)
+	return res
+}
+
+func normalizeCategory(c *models.Category) error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return ErrNameRequired
+	}
+	c.Color = strings.ToLower(strings.TrimSpace(c.Color))
+	if c.Color != "" && !colorPattern.MatchString(c.Color) {
+		return ErrInvalidColor
+	}
+	c.Icon = strings.TrimSpace(c.Icon)
+	return nil
+}
+
+// place checks that c, named name, may sit under parentID: the parent
+// exists, is not c or below it, and has no other child called name.
+func (s *CategoryService) place(userID, id int64, parentID *int64, name string) error {
+	cs, err := s.repo.List(userID)
+	if err != nil {
+		return err
+	}
+	byID := map[int64]models.Category{}
+	for _, c := range cs {
+		byID[c.ID] = c
+	}
+	for p := parentID; p != nil; p = byID[*p].ParentID {
+		if _, ok := byID[*p]; !ok {
+			return ErrParentNotFound
+		}
+		if *p == id {
+			return ErrCategoryCycle
+		}
+	}
+	for _, c := range cs {
+		if c.ID != id && sameParent(c.ParentID, parentID) && strings.EqualFold(c.Name, name) {
+			return ErrCategoryExists
+		}
+	}
+	return nil
+}
+
+func sameParent(a, b *int64) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+// Create adds c as the last subcategory of c.ParentID, or at the top level.
+func (s *CategoryService) Create(userID int64, c models.Category) (models.Category, error) {
+	c.UserID = userID
+	if err := normalizeCategory(&c); err != nil {
+		return models.Category{}, err
+	}
+	if err := s.place(userID, 0, c.ParentID, c.Name); err != nil {
+		return models.Category{}, err
+	}
+	return s.repo.Create(c)
+}
+
+// Update renames and restyles c. It fails with a ConflictError unless
+// c.Version is 0 or the stored one.
+func (s *CategoryService) Update(userID int64, c models.Category) (models.Category, error) {
+	c.UserID = userID
+	if err := normalizeCategory(&c); err != nil {
+		return models.Category{}, err
+	}
+	cur, err := s.repo.Get(userID, c.ID)
+	if err != nil {
+		return models.Category{}, err
+	}
+	if err := s.place(userID, c.ID, cur.ParentID, c.Name); err != nil {
+		return models.Category{}, err
+	}
+	res, err := s.repo.Update(c)
+	if err != nil {
+		return res, conflict(err, func() (models.Category, error) { return s.repo.Get(userID, c.ID) })
+	}
+	return res, nil
+}
+
+// Rename fails with a ConflictError unless version is 0 or the stored one.
+func (s *CategoryService) Rename(userID, id int64, name string, version int64) (models.Category, error) {
+	cur, err := s.repo.Get(userID, id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	cur.Name, cur.Version = name, version
+	return s.Update(userID, cur)
+}
+
+// Move makes id a subcategory of parentID, or top-level when parentID is
+// nil, at position among its new siblings. It fails with a ConflictError
+// unless version is 0 or the stored one.
+func (s *CategoryService) Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error) {
+	cur, err := s.repo.Get(userID, id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	if err := s.place(userID, id, parentID, cur.Name); err != nil {
+		return models.Category{}, err
+	}
+	res, err := s.repo.Move(userID, id, parentID, position, version)
+	if err != nil {
+		return res, conflict(err, func() (models.Category, error) { return s.repo.Get(userID, id) })
+	}
+	return res, nil
+}
+
+// Delete moves the category to the trash; its subcategories move up to its
+// parent. tasks says what happens to the tasks in it: they move to the
+// category moveTo, lose their category, or go to the trash as well.
+func (s *CategoryService) Delete(userID, id int64, tasks string, moveTo *int64, version int64) error {
+	switch tasks {
+	case models.CategoryMoveTasks:
+		if moveTo == nil || *moveTo == id {
+			return ErrMoveTarget
+		}
+		if _, err := s.repo.Get(userID, *moveTo); errors.Is(err, repository.ErrNotFound) {
+			return ErrMoveTarget
+		} else if err != nil {
+			return err
+		}
+	case models.CategoryClearTasks, models.CategoryDeleteTasks:
+		moveTo = nil
+	default:
+		return ErrInvalidDeletion
+	}
+	err := s.repo.Delete(userID, id, tasks, moveTo, version)
+	return conflict(err, func() (models.Category, error) { return s.repo.Get(userID, id) })
+}
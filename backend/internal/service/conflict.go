@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+// ConflictError is returned instead of overwriting an edit made since the
+// caller loaded the item. Current is the stored *models.Task,
+// *models.Subtask, models.Category, models.Priority or models.Tag, whose
+// version a retry should carry.
+type ConflictError struct {
+	Current any
+}
+
+func (e *ConflictError) Error() string {
+	return "conflict: the item was changed elsewhere since it was loaded"
+}
+
+func (e *ConflictError) Unwrap() error { return repository.ErrConflict }
+
+// conflict turns repository.ErrConflict into a ConflictError carrying the
+// stored state that get returns.
+func conflict[T any](err error, get func() (T, error)) error {
+	if !errors.Is(err, repository.ErrConflict) {
+		return err
+	}
+	cur, gerr := get()
+	if gerr != nil {
+		return gerr
+	}
+	return &ConflictError{Current: cur}
+}
+
+// checkVersion fails with a ConflictError carrying t unless version is 0 or
+// t's. Commands that write more than the task itself check it up front, so
+// that a stale call writes nothing.
+func checkVersion(t *models.Task, version int64) error {
+	if version != 0 && version != t.Version {
+		return &ConflictError{Current: t}
+	}
+	return nil
+}
+
+// save writes t, failing with a ConflictError if t.Version is stale.
+func (s *taskService) save(t *models.Task) (*models.Task, error) {
+	res, err := s.repo.Update(t)
+	if err != nil {
+		return nil, conflict(err, func() (*models.Task, error) { return s.repo.GetByID(t.UserID, t.ID) })
+	}
+	return res, nil
+}
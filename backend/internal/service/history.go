@@ -0,0 +1,380 @@
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrHistoryStale means a task was changed outside the history since the
+	// step was recorded; the step is dropped rather than overwrite that.
+	ErrHistoryStale = errors.New("the tasks were changed elsewhere and can no longer be restored")
+)
+
+const DefaultHistoryLimit = 50
+
+// A step is one recorded command. It holds every task the command touched,
+// with subtasks, as it was before (nil: did not exist yet) and after (nil:
+// deleted). Undo writes the before states back, redo the after states.
+type step struct {
+	label  string
+	mu     sync.Mutex // guards ids and before while the command runs
+	ids    []int64
+	before map[int64]*models.Task
+	after  map[int64]*models.Task
+}
+
+type sessionKey struct{ userID, session int64 }
+
+type sessionHistory struct {
+	mu   sync.Mutex // held for the whole of a Record, Undo or Redo
+	undo []*step
+	redo []*step
+}
+
+// History keeps an undo and a redo stack of task commands per session, so
+// two clients signed in as the same user do not undo each other's changes.
+// A command sees only its own writes: Record hands it repositories tied to
+// its step, and the task service it runs must write through them, so a step
+// also covers cascaded subtasks and the occurrence created when a repeating
+// task is completed. Writes made outside Record are not undoable, and series
+// templates are left as they are.
+type History struct {
+	tasks    repository.TaskRepository
+	subtasks repository.SubtaskRepository
+	limit    int
+
+	mu       sync.Mutex
+	sessions map[sessionKey]*sessionHistory
+}
+
+func NewHistory(tasks repository.TaskRepository, subtasks repository.SubtaskRepository, limit int) *History {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	return &History{tasks: tasks, subtasks: subtasks, limit: limit, sessions: map[sessionKey]*sessionHistory{}}
+}
+
+func (h *History) session(userID, session int64) *sessionHistory {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := sessionKey{userID, session}
+	s, ok := h.sessions[k]
+	if !ok {
+		s = &sessionHistory{}
+		h.sessions[k] = s
+	}
+	return s
+}
+
+// snapshot reads a task with its subtasks, or nil if it does not exist.
+func (h *History) snapshot(userID, id int64) (*models.Task, error) {
+	t, err := h.tasks.GetByID(userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.Subtasks, err = h.subtasks.List(userID, id); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// touch records the state of a task about to be written by the step's
+// command, unless the step has seen the task already. created marks a task
+// that is being inserted.
+func (h *History) touch(st *step, userID, id int64, created bool) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if _, seen := st.before[id]; seen {
+		return nil
+	}
+	var before *models.Task
+	if !created {
+		var err error
+		if before, err = h.snapshot(userID, id); err != nil {
+			return err
+		}
+	}
+	st.ids = append(st.ids, id)
+	st.before[id] = before
+	return nil
+}
+
+// Record runs fn as one undoable command named label in the session's
+// history. fn must make its writes through the repositories it is passed.
+func (h *History) Record(userID, session int64, label string, fn func(repository.TaskRepository, repository.SubtaskRepository) error) error {
+	s := h.session(userID, session)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &step{label: label, before: map[int64]*models.Task{}}
+	err := fn(&historyTasks{TaskRepository: h.tasks, h: h, st: st}, &historySubtasks{SubtaskRepository: h.subtasks, h: h, st: st})
+	if err != nil || len(st.ids) == 0 {
+		return err
+	}
+	if st.after, err = h.snapshots(userID, st.ids); err != nil {
+		return err
+	}
+	s.undo = append(s.undo, st)
+	if len(s.undo) > h.limit {
+		s.undo = s.undo[len(s.undo)-h.limit:]
+	}
+	s.redo = nil
+	return nil
+}
+
+func (h *History) snapshots(userID int64, ids []int64) (map[int64]*models.Task, error) {
+	res := make(map[int64]*models.Task, len(ids))
+	for _, id := range ids {
+		t, err := h.snapshot(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		res[id] = t
+	}
+	return res, nil
+}
+
+// apply moves the step's tasks from the from states to the to states, after
+// checking that nothing else has changed them since. It returns the states
+// as written, whose versions the next check compares against.
+func (h *History) apply(userID int64, st *step, from, to map[int64]*models.Task) (map[int64]*models.Task, error) {
+	cur, err := h.snapshots(userID, st.ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range st.ids {
+		if !sameVersion(cur[id], from[id]) {
+			return nil, ErrHistoryStale
+		}
+	}
+	var gone []int64
+	for _, id := range st.ids {
+		if to[id] == nil {
+			gone = append(gone, id)
+			continue
+		}
+		if err := h.tasks.Restore(to[id]); err != nil {
+			return nil, err
+		}
+	}
+	if len(gone) > 0 {
+		if _, err := h.tasks.DeleteMany(userID, gone); err != nil {
+			return nil, err
+		}
+	}
+	return h.snapshots(userID, st.ids)
+}
+
+// sameVersion reports whether a and b are the same state of a task and its
+// subtasks; subtask writes do not bump the task's own version.
+func sameVersion(a, b *models.Task) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Version != b.Version || len(a.Subtasks) != len(b.Subtasks) {
+		return false
+	}
+	for i, st := range a.Subtasks {
+		if st.ID != b.Subtasks[i].ID || st.Version != b.Subtasks[i].Version {
+			return false
+		}
+	}
+	return true
+}
+
+// Undo reverts the session's last recorded command and returns its label.
+func (h *History) Undo(userID, session int64) (string, error) {
+	s := h.session(userID, session)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.undo) == 0 {
+		return "", ErrNothingToUndo
+	}
+	st := s.undo[len(s.undo)-1]
+	s.undo = s.undo[:len(s.undo)-1]
+	before, err := h.apply(userID, st, st.after, st.before)
+	if err != nil {
+		return "", err
+	}
+	st.before = before
+	s.redo = append(s.redo, st)
+	return st.label, nil
+}
+
+// Redo repeats the last undone command and returns its label.
+func (h *History) Redo(userID, session int64) (string, error) {
+	s := h.session(userID, session)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.redo) == 0 {
+		return "", ErrNothingToRedo
+	}
+	st := s.redo[len(s.redo)-1]
+	s.redo = s.redo[:len(s.redo)-1]
+	after, err := h.apply(userID, st, st.before, st.after)
+	if err != nil {
+		return "", err
+	}
+	st.after = after
+	s.undo = append(s.undo, st)
+	return st.label, nil
+}
+
+// Labels returns the labels of the commands Undo and Redo would act on next,
+// or "" when there are none.
+func (h *History) Labels(userID, session int64) (undo, redo string) {
+	s := h.session(userID, session)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.undo); n > 0 {
+		undo = s.undo[n-1].label
+	}
+	if n := len(s.redo); n > 0 {
+		redo = s.redo[n-1].label
+	}
+	return undo, redo
+}
+
+// Clear forgets the session's history, as when it ends.
+func (h *History) Clear(userID, session int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, sessionKey{userID, session})
+}
+
+// historyTasks captures a step's task writes into the step.
+type historyTasks struct {
+	repository.TaskRepository
+	h  *History
+	st *step
+}
+
+func (r *historyTasks) Create(task *models.Task) (*models.Task, error) {
+	t, err := r.TaskRepository.Create(task)
+	if err != nil {
+		return nil, err
+	}
+	return t, r.h.touch(r.st, t.UserID, t.ID, true)
+}
+
+func (r *historyTasks) Update(task *models.Task) (*models.Task, error) {
+	if err := r.h.touch(r.st, task.UserID, task.ID, false); err != nil {
+		return nil, err
+	}
+	return r.TaskRepository.Update(task)
+}
+
+func (r *historyTasks) UpdateWithSubtasks(task *models.Task, subtasks []models.Subtask) (*models.Task, error) {
+	if err := r.h.touch(r.st, task.UserID, task.ID, false); err != nil {
+		return nil, err
+	}
+	return r.TaskRepository.UpdateWithSubtasks(task, subtasks)
+}
+
+func (r *historyTasks) SetCompleted(userID, id int64, completed bool, version int64, next *models.Task) (*models.Task, error) {
+	if err := r.h.touch(r.st, userID, id, false); err != nil {
+		return nil, err
+	}
+	t, err := r.TaskRepository.SetCompleted(userID, id, completed, version, next)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		if err := r.h.touch(r.st, userID, next.ID, true); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (r *historyTasks) Delete(userID, id, version int64) error {
+	if err := r.h.touch(r.st, userID, id, false); err != nil {
+		return err
+	}
+	return r.TaskRepository.Delete(userID, id, version)
+}
+
+func (r *historyTasks) DeleteMany(userID int64, ids []int64) (int64, error) {
+	for _, id := range ids {
+		if err := r.h.touch(r.st, userID, id, false); err != nil {
+			return 0, err
+		}
+	}
+	return r.TaskRepository.DeleteMany(userID, ids)
+}
+
+func (r *historyTasks) ClearCompleted(userID int64) (int64, error) {
+	done, err := r.TaskRepository.Query(models.TaskFilter{UserID: userID, Status: "completed"})
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range done {
+		if err := r.h.touch(r.st, userID, t.ID, false); err != nil {
+			return 0, err
+		}
+	}
+	return r.TaskRepository.ClearCompleted(userID)
+}
+
+func (r *historyTasks) Unarchive(userID, id, version int64) (*models.Task, error) {
+	if err := r.h.touch(r.st, userID, id, false); err != nil {
+		return nil, err
+	}
+	return r.TaskRepository.Unarchive(userID, id, version)
+}
+
+// historySubtasks captures a step's subtask writes as writes to the tasks
+// they belong to.
+type historySubtasks struct {
+	repository.SubtaskRepository
+	h  *History
+	st *step
+}
+
+// touchParent records the task the subtask id belongs to.
+func (r *historySubtasks) touchParent(userID, id int64) error {
+	st, err := r.SubtaskRepository.Get(userID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return r.h.touch(r.st, userID, st.TaskID, false)
+}
+
+func (r *historySubtasks) Create(s *models.Subtask) (*models.Subtask, error) {
+	if err := r.h.touch(r.st, s.UserID, s.TaskID, false); err != nil {
+		return nil, err
+	}
+	return r.SubtaskRepository.Create(s)
+}
+
+func (r *historySubtasks) Update(s *models.Subtask) (*models.Subtask, error) {
+	if err := r.touchParent(s.UserID, s.ID); err != nil {
+		return nil, err
+	}
+	return r.SubtaskRepository.Update(s)
+}
+
+func (r *historySubtasks) Toggle(userID, id, version int64) (*models.Subtask, error) {
+	if err := r.touchParent(userID, id); err != nil {
+		return nil, err
+	}
+	return r.SubtaskRepository.Toggle(userID, id, version)
+}
+
+func (r *historySubtasks) Delete(userID, id, version int64) error {
+	if err := r.touchParent(userID, id); err != nil {
+		return err
+	}
+	return r.SubtaskRepository.Delete(userID, id, version)
+}
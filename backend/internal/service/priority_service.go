@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+var (
+	ErrInvalidPriority  = errors.New("unknown priority")
+	ErrPriorityName     = errors.New(`priority names are lowercase letters, digits, "-" and "_"`)
+	ErrPriorityExists   = errors.New("a priority with this name already exists")
+	ErrInvalidWeight    = errors.New("weight must be between 0 and 1000")
+	ErrDefaultPriority  = errors.New("make another priority the default instead")
+	ErrPriorityTarget   = errors.New("tasks must move to another existing priority")
+	priorityNamePattern = regexp.MustCompile(`^[\p{Ll}\p{Nd}_-]+$`)
+)
+
+// MaxWeight bounds priority weights; tasks at no level sort below weight 0.
+const MaxWeight = 1000
+
+type PriorityService struct {
+	repo repository.PriorityRepository
+}
+
+func NewPriorityService(r repository.PriorityRepository) *PriorityService {
+	return &PriorityService{repo: r}
+}
+
+// List returns the user's priority levels, highest weight first.
+func (s *PriorityService) List(userID int64) ([]models.Priority, error) {
+	return s.repo.List(userID)
+}
+
+func normalizePriority(p *models.Priority) error {
+	p.Name = strings.ToLower(strings.TrimSpace(p.Name))
+	if p.Name == "" {
+		return ErrNameRequired
+	}
+	if !priorityNamePattern.MatchString(p.Name) {
+		return ErrPriorityName
+	}
+	p.Label = strings.TrimSpace(p.Label)
+	if p.Label == "" {
+		p.Label = p.Name
+	}
+	p.Color = strings.ToLower(strings.TrimSpace(p.Color))
+	if p.Color != "" && !colorPattern.MatchString(p.Color) {
+		return ErrInvalidColor
+	}
+	if p.Weight < 0 || p.Weight > MaxWeight {
+		return ErrInvalidWeight
+	}
+	return nil
+}
+
+// unique fails with ErrPriorityExists when another level than id is called
+// name.
+func (s *PriorityService) unique(userID, id int64, name string) error {
+	ps, err := s.repo.List(userID)
+	if err != nil {
+		return err
+	}
+	for _, p := range ps {
+		if p.ID != id && p.Name == name {
+			return ErrPriorityExists
+		}
+	}
+	return nil
+}
+
+func (s *PriorityService) Create(userID int64, p models.Priority) (models.Priority, error) {
+	p.UserID = userID
+	if err := normalizePriority(&p); err != nil {
+		return models.Priority{}, err
+	}
+	if err := s.unique(userID, 0, p.Name); err != nil {
+		return models.Priority{}, err
+	}
+	return s.repo.Create(p)
+}
+
+// Update renames, relabels and reweighs a level; a new name is applied to
+// every task. Setting Default moves the default here; the default level
+// cannot give it up otherwise. It fails with a ConflictError unless
+// p.Version is 0 or the stored one.
+func (s *PriorityService) Update(userID int64, p models.Priority) (models.Priority, error) {
+	p.UserID = userID
+	if err := normalizePriority(&p); err != nil {
+		return models.Priority{}, err
+	}
+	cur, err := s.repo.Get(userID, p.ID)
+	if err != nil {
+		return models.Priority{}, err
+	}
+	if cur.Default && !p.Default {
+		return models.Priority{}, ErrDefaultPriority
+	}
+	if err := s.unique(userID, p.ID, p.Name); err != nil {
+		return models.Priority{}, err
+	}
+	res, err := s.repo.Update(p)
+	if err != nil {
+		return res, conflict(err, func() (models.Priority, error) { return s.repo.Get(userID, p.ID) })
+	}
+	return res, nil
+}
+
+// Delete removes a level after moving its tasks to the level moveTo. If the
+// removed level was the default, moveTo becomes the default.
+func (s *PriorityService) Delete(userID, id, moveTo, version int64) error {
+	if moveTo == id {
+		return ErrPriorityTarget
+	}
+	if _, err := s.repo.Get(userID, moveTo); errors.Is(err, repository.ErrNotFound) {
+		return ErrPriorityTarget
+	} else if err != nil {
+		return err
+	}
+	err := s.repo.Delete(userID, id, moveTo, version)
+	return conflict(err, func() (models.Priority, error) { return s.repo.Get(userID, id) })
+}
+
+// levelOf finds the level name names among ps, or the default level when
+// name is empty. It fails with ErrInvalidPriority, listing the names.
+func levelOf(ps []models.Priority, name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range ps {
+		if p.Name == name || name == "" && p.Default {
+			return p.Name, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q; use one of %s", ErrInvalidPriority, name, strings.Join(priorityNames(ps), ", "))
+}
+
+func priorityNames(ps []models.Priority) []string {
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.Name
+	}
+	return names
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/rrule"
+)
+
+var (
+	ErrInvalidRepeatRule   = rrule.ErrInvalid
+	ErrRepeatNeedsDueDate  = errors.New("a repeating task needs a due date")
+	ErrNotRepeating        = errors.New("task does not repeat")
+	ErrOccurrenceCompleted = errors.New("a completed occurrence cannot be skipped")
+	ErrInvalidScope        = errors.New(`scope must be "this" or "future"`)
+)
+
+// canonicalRule validates a rule as entered by the user and returns the
+// RRULE form that is stored; a blank rule means the task does not repeat.
+func canonicalRule(rule *string) (*string, error) {
+	if rule == nil || strings.TrimSpace(*rule) == "" {
+		return nil, nil
+	}
+	r, err := rrule.Parse(*rule)
+	if err != nil {
+		return nil, err
+	}
+	s := r.String()
+	return &s, nil
+}
+
+// Scopes of an edit to an occurrence of a series.
+const (
+	ScopeThis   = "this"
+	ScopeFuture = "future"
+)
+
+// ensureSeries makes a repeating task the first occurrence of a new series,
+// with the task as its template. Tasks given a rule before series existed are
+// converted the same way the first time they are needed. The caller saves t.
+func (s *taskService) ensureSeries(t *models.Task) error {
+	if t.SeriesID != nil || t.RepeatRule == nil || t.DueDate == nil {
+		return nil
+	}
+	series := &models.TaskSeries{
+		UserID:      t.UserID,
+		Title:       t.Title,
+		Description: t.Description,
+		Priority:    t.Priority,
+		RepeatRule:  *t.RepeatRule,
+		CategoryID:  t.CategoryID,
+		Tags:        append([]string{}, t.Tags...),
+		Subtasks:    []string{},
+	}
+	subtasks := t.Subtasks
+	if t.ID != 0 {
+		var err error
+		if subtasks, err = s.subtasks.List(t.UserID, t.ID); err != nil {
+			return err
+		}
+	}
+	for _, st := range subtasks {
+		series.Subtasks = append(series.Subtasks, st.Title)
+	}
+	created, err := s.series.Create(series)
+	if err != nil {
+		return err
+	}
+	due := *t.DueDate
+	t.SeriesID = &created.ID
+	t.OccurrenceAt = &due
+	return nil
+}
+
+// nextOccurrence builds the occurrence that follows t from its series'
+// template, or nil when t does not repeat, its series has ended or its rule
+// is exhausted. A repeating task without a series gets one first, which
+// saves t and advances t.Version. The schedule follows OccurrenceAt, so moving one occurrence's
+// due date does not shift the ones after it, and is computed on the wall
+// clock of s.loc: a task due at 09:00 stays at 09:00 across DST.
+func (s *taskService) nextOccurrence(t *models.Task) (*models.Task, error) {
+	if t.RepeatRule == nil || t.DueDate == nil {
+		return nil, nil
+	}
+	r, err := rrule.Parse(*t.RepeatRule)
+	if err != nil {
+		return nil, fmt.Errorf("task %d: %w", t.ID, err)
+	}
+	if t.SeriesID == nil {
+		if err := s.ensureSeries(t); err != nil {
+			return nil, err
+		}
+		saved, err := s.save(t)
+		if err != nil {
+			return nil, err
+		}
+		t.Version = saved.Version
+	}
+	series, err := s.series.Get(t.UserID, *t.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	if series.EndedAt != nil {
+		return nil, nil
+	}
+	anchor := *t.DueDate
+	if t.OccurrenceAt != nil {
+		anchor = *t.OccurrenceAt
+	}
+	next, ok := r.Next(anchor.In(s.loc))
+	if !ok {
+		return nil, nil
+	}
+	rule := r.Advance().String()
+	subtasks := make([]models.Subtask, len(series.Subtasks))
+	for i, title := range series.Subtasks {
+		subtasks[i].Title = title
+	}
+	occurrence := next
+	return &models.Task{
+		UserID:       t.UserID,
+		Title:        series.Title,
+		Description:  series.Description,
+		Priority:     series.Priority,
+		DueDate:      &next,
+		RepeatRule:   &rule,
+		CategoryID:   series.CategoryID,
+		Tags:         append([]string{}, series.Tags...),
+		SeriesID:     &series.ID,
+		OccurrenceAt: &occurrence,
+		Subtasks:     subtasks,
+		Position:     t.Position,
+	}, nil
+}
+
+// UpdateOccurrence saves an edited occurrence. With ScopeFuture the edit also
+// becomes the series' template, and a changed due date moves the schedule of
+// all later occurrences; ScopeThis, or a task outside any series, changes
+// only the task itself.
+func (s *taskService) UpdateOccurrence(task *models.Task, scope string) (*models.Task, error) {
+	switch scope {
+	case ScopeThis, "":
+		return s.UpdateTask(task)
+	case ScopeFuture:
+	default:
+		return nil, ErrInvalidScope
+	}
+	if task.SeriesID == nil {
+		return s.UpdateTask(task)
+	}
+	if err := normalizeTask(task); err != nil {
+		return nil, err
+	}
+	if err := s.checkPriority(task); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(task); err != nil {
+		return nil, err
+	}
+	cur, err := s.repo.GetByID(task.UserID, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Version != 0 && task.Version != cur.Version {
+		return nil, &ConflictError{Current: cur}
+	}
+	series, err := s.series.Get(task.UserID, *task.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	subtasks, err := s.subtasks.List(task.UserID, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	series.Title = task.Title
+	series.Description = task.Description
+	series.Priority = task.Priority
+	series.CategoryID = task.CategoryID
+	series.Tags = append([]string{}, task.Tags...)
+	series.Subtasks = []string{}
+	for _, st := range subtasks {
+		series.Subtasks = append(series.Subtasks, st.Title)
+	}
+	if _, err := s.series.Update(series); err != nil {
+		return nil, err
+	}
+	if task.DueDate != nil {
+		due := *task.DueDate
+		task.OccurrenceAt = &due
+	}
+	return s.save(task)
+}
+
+// SkipOccurrence drops an open occurrence without completing it, records the
+// skip in the series' history and returns the occurrence that replaces it, or
+// nil if the series has no more.
+func (s *taskService) SkipOccurrence(userID, id, version int64) (*models.Task, error) {
+	t, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(t, version); err != nil {
+		return nil, err
+	}
+	if t.RepeatRule == nil || t.DueDate == nil {
+		return nil, ErrNotRepeating
+	}
+	if t.Completed {
+		return nil, ErrOccurrenceCompleted
+	}
+	next, err := s.nextOccurrence(t)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 {
+		version = t.Version
+	}
+	if err := s.series.Skip(userID, id, version, next); err != nil {
+		return nil, conflict(err, func() (*models.Task, error) { return s.repo.GetByID(userID, id) })
+	}
+	return next, nil
+}
+
+func (s *taskService) SeriesHistory(userID, seriesID int64) ([]models.SeriesOccurrence, error) {
+	if _, err := s.series.Get(userID, seriesID); err != nil {
+		return nil, err
+	}
+	return s.series.History(userID, seriesID)
+}
@@ -1,15 +1,18 @@
 package service
 
-import "todo-app/backend/internal/repository"
+import (
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
 
 type Stats struct {
-	Total          int `json:"total"`
-	Active         int `json:"active"`
-	Completed      int `json:"completed"`
-	Overdue        int `json:"overdue"`
-	HighPriority   int `json:"highPriority"`
-	MediumPriority int `json:"mediumPriority"`
-	LowPriority    int `json:"lowPriority"`
+	Total     int `json:"total"`
+	Active    int `json:"active"`
+	Completed int `json:"completed"`
+	Overdue   int `json:"overdue"`
+	// Priorities counts the tasks at each of the user's levels, highest
+	// weight first.
+	Priorities []models.Priority `json:"priorities"`
 }
 
 type StatsService struct {
@@ -20,18 +23,16 @@ func NewStatsService(r repository.StatsRepository) *StatsService {
 	return &StatsService{repo: r}
 }
 
-func (s *StatsService) Snapshot() (Stats, error) {
-	ss, err := s.repo.Snapshot()
+func (s *StatsService) Snapshot(userID int64) (Stats, error) {
+	ss, err := s.repo.Snapshot(userID)
 	if err != nil {
 		return Stats{}, err
 	}
 	return Stats{
-		Total:          ss.Total,
-		Active:         ss.Active,
-		Completed:      ss.Completed,
-		Overdue:        ss.Overdue,
-		HighPriority:   ss.HighPriority,
-		MediumPriority: ss.MediumPriority,
-		LowPriority:    ss.LowPriority,
+		Total:      ss.Total,
+		Active:     ss.Active,
+		Completed:  ss.Completed,
+		Overdue:    ss.Overdue,
+		Priorities: ss.Priorities,
 	}, nil
 }
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+var (
+	ErrTagExists    = errors.New("a tag with this name already exists")
+	ErrInvalidColor = errors.New(`color must be empty or "#rrggbb"`)
+	ErrSameTag      = errors.New("cannot merge a tag into itself")
+)
+
+// DefaultSuggestLimit caps Suggest when no limit is given.
+const DefaultSuggestLimit = 10
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type TagService struct {
+	repo repository.TagRepository
+}
+
+func NewTagService(r repository.TagRepository) *TagService {
+	return &TagService{repo: r}
+}
+
+// List returns the names of the tags in use.
+func (s *TagService) List(userID int64) ([]string, error) {
+	return s.repo.All(userID)
+}
+
+// Tags returns every tag of the user by name, with usage counts.
+func (s *TagService) Tags(userID int64) ([]models.Tag, error) {
+	return s.repo.List(userID)
+}
+
+// Suggest returns up to limit tags starting with prefix, ignoring case, most
+// used first.
+func (s *TagService) Suggest(userID int64, prefix string, limit int) ([]models.Tag, error) {
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	tags, err := s.repo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	res := []models.Tag{}
+	for _, t := range tags {
+		if strings.HasPrefix(strings.ToLower(t.Name), prefix) {
+			res = append(res, t)
+		}
+	}
+	// tags are sorted by name, which breaks ties.
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Count > res[j].Count })
+	return res[:min(limit, len(res))], nil
+}
+
+func normalizeTag(t *models.Tag) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return ErrNameRequired
+	}
+	t.Color = strings.ToLower(strings.TrimSpace(t.Color))
+	if t.Color != "" && !colorPattern.MatchString(t.Color) {
+		return ErrInvalidColor
+	}
+	return nil
+}
+
+// unique fails with ErrTagExists when another tag than id is called name.
+func (s *TagService) unique(userID, id int64, name string) error {
+	t, err := s.repo.GetByName(userID, name)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if t.ID != id {
+		return ErrTagExists
+	}
+	return nil
+}
+
+func (s *TagService) Create(userID int64, name, color string) (models.Tag, error) {
+	t := models.Tag{UserID: userID, Name: name, Color: color}
+	if err := normalizeTag(&t); err != nil {
+		return t, err
+	}
+	if err := s.unique(userID, 0, t.Name); err != nil {
+		return t, err
+	}
+	return s.repo.Create(t)
+}
+
+// Update renames and recolors a tag on every task. Renaming onto another
+// tag fails with ErrTagExists; use Merge for that. It fails with a
+// ConflictError unless version is 0 or the stored one.
+func (s *TagService) Update(userID, id int64, name, color string, version int64) (models.Tag, error) {
+	t := models.Tag{ID: id, UserID: userID, Name: name, Color: color, Version: version}
+	if err := normalizeTag(&t); err != nil {
+		return t, err
+	}
+	if err := s.unique(userID, id, t.Name); err != nil {
+		return t, err
+	}
+	res, err := s.repo.Update(t)
+	if err != nil {
+		return res, conflict(err, func() (models.Tag, error) { return s.repo.Get(userID, id) })
+	}
+	return res, nil
+}
+
+// Merge moves every task from tag from to tag into and deletes from;
+// version is from's.
+func (s *TagService) Merge(userID, from, into, version int64) (models.Tag, error) {
+	if from == into {
+		return models.Tag{}, ErrSameTag
+	}
+	res, err := s.repo.Merge(userID, from, into, version)
+	if err != nil {
+		return res, conflict(err, func() (models.Tag, error) { return s.repo.Get(userID, from) })
+	}
+	return res, nil
+}
+
+// Delete removes the tag from every task.
+func (s *TagService) Delete(userID, id, version int64) error {
+	err := s.repo.Delete(userID, id, version)
+	return conflict(err, func() (models.Tag, error) { return s.repo.Get(userID, id) })
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-app/backend/internal/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// A cursor is "next" or "prev" followed by the key of the task at that edge
+// of the page it came from. Clients only pass it back, so its format may
+// change between releases.
+func encodeCursor(dir string, t models.Task) string {
+	s := dir + "|" + t.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(t.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func decodeCursor(cursor string) (dir string, k *models.Keyset, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(b), "|")
+	if len(parts) != 3 || (parts[0] != "next" && parts[0] != "prev") {
+		return "", nil, ErrInvalidCursor
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return "", nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return "", nil, ErrInvalidCursor
+	}
+	return parts[0], &models.Keyset{CreatedAt: at, ID: id}, nil
+}
+
+// listFilter maps the names GetTasks accepts onto a TaskFilter.
+func listFilter(userID int64, name string) (models.TaskFilter, error) {
+	f := models.TaskFilter{UserID: userID}
+	switch name {
+	case "", "all":
+	case "active", "completed", "archived":
+		f.Status = name
+	case "overdue", "today", "week":
+		f.DateFilter = name
+	default:
+		return f, fmt.Errorf("%w: unknown filter %q", ErrInvalidFilter, name)
+	}
+	return f, nil
+}
+
+// ListTasks returns one page of the tasks GetTasks would return, newest
+// first. An empty cursor starts at the newest task; limit defaults to
+// DefaultPageSize and is capped at MaxPageSize.
+func (s *taskService) ListTasks(userID int64, filter, cursor string, limit int) (*models.TaskPage, error) {
+	f, err := listFilter(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+	p := models.PageQuery{Filter: f, Limit: limit + 1}
+	dir := "next"
+	if cursor != "" {
+		var k *models.Keyset
+		if dir, k, err = decodeCursor(cursor); err != nil {
+			return nil, err
+		}
+		if dir == "next" {
+			p.After = k
+		} else {
+			p.Before = k
+		}
+	}
+	ts, err := s.repo.Page(p)
+	if err != nil {
+		return nil, err
+	}
+	// The extra task only tells whether there is another page that way.
+	more := len(ts) > limit
+	page := &models.TaskPage{Items: ts}
+	if dir == "next" {
+		if more {
+			page.Items = ts[:limit]
+			page.Next = encodeCursor("next", page.Items[limit-1])
+		}
+		if p.After != nil && len(page.Items) > 0 {
+			page.Prev = encodeCursor("prev", page.Items[0])
+		}
+	} else {
+		if more {
+			page.Items = ts[len(ts)-limit:]
+			page.Prev = encodeCursor("prev", page.Items[0])
+		}
+		if len(page.Items) > 0 {
+			page.Next = encodeCursor("next", page.Items[len(page.Items)-1])
+		}
+	}
+	if page.Items == nil {
+		page.Items = []models.Task{}
+	}
+	return page, nil
+}
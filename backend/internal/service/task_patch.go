@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"todo-app/backend/internal/models"
+)
+
+// FieldErrors reports invalid input field by field, keyed by the field's JSON
+// name.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for f := range e {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for i, f := range fields {
+		fields[i] = f + ": " + e[f]
+	}
+	return "invalid input: " + strings.Join(fields, "; ")
+}
+
+// PatchTask applies the fields set in in to the task. Every field is checked
+// before anything is saved, and all invalid ones are reported together as
+// FieldErrors. A stale in.Version fails with a ConflictError.
+func (s *taskService) PatchTask(in models.UpdateTaskInput) (*models.Task, error) {
+	t, err := s.repo.GetByID(in.UserID, in.ID)
+	if err != nil {
+		return nil, err
+	}
+	// The repository checks the version again when saving; checking here
+	// keeps a stale patch from touching the series as well.
+	if in.Version != 0 && in.Version != t.Version {
+		return nil, &ConflictError{Current: t}
+	}
+	fe := FieldErrors{}
+	if in.Title != nil {
+		if strings.TrimSpace(*in.Title) == "" {
+			fe["title"] = "is required"
+		} else {
+			t.Title = *in.Title
+		}
+	}
+	if in.Description != nil {
+		t.Description = *in.Description
+	}
+	if in.Priority != nil {
+		ps, err := s.priorities.List(t.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if p, err := levelOf(ps, *in.Priority); err != nil || strings.TrimSpace(*in.Priority) == "" {
+			fe["priority"] = "must be one of " + strings.Join(priorityNames(ps), ", ")
+		} else {
+			t.Priority = p
+		}
+	}
+	switch {
+	case in.DueDate != nil && in.ClearDueDate:
+		fe["dueDate"] = "cannot be set and cleared at once"
+	case in.DueDate != nil:
+		t.DueDate = in.DueDate
+	case in.ClearDueDate:
+		t.DueDate = nil
+	}
+	if in.RepeatRule != nil {
+		rule, err := canonicalRule(in.RepeatRule)
+		if err != nil {
+			fe["repeatRule"] = err.Error()
+		} else {
+			t.RepeatRule = rule
+		}
+	}
+	if (in.RepeatRule != nil || in.ClearDueDate) && t.RepeatRule != nil && t.DueDate == nil && len(fe) == 0 {
+		if in.RepeatRule != nil {
+			fe["repeatRule"] = ErrRepeatNeedsDueDate.Error()
+		} else {
+			fe["dueDate"] = ErrRepeatNeedsDueDate.Error()
+		}
+	}
+	switch {
+	case in.CategoryID != nil && in.ClearCategory:
+		fe["categoryId"] = "cannot be set and cleared at once"
+	case in.CategoryID != nil && *in.CategoryID <= 0:
+		fe["categoryId"] = "must be a category id"
+	case in.CategoryID != nil:
+		t.CategoryID = in.CategoryID
+		if err := s.checkCategory(t); errors.Is(err, ErrCategoryNotFound) {
+			fe["categoryId"] = "must be one of your categories"
+		} else if err != nil {
+			return nil, err
+		}
+	case in.ClearCategory:
+		t.CategoryID = nil
+	}
+	if in.Tags != nil {
+		t.Tags = in.Tags
+	}
+	if len(fe) > 0 {
+		return nil, fe
+	}
+	if in.RepeatRule != nil {
+		if err := s.syncSeriesRule(t); err != nil {
+			return nil, err
+		}
+	}
+	return s.UpdateTask(t)
+}
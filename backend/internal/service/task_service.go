@@ -1,59 +1,526 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"todo-app/backend/internal/markdown"
 	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/position"
+	"todo-app/backend/internal/query"
 	"todo-app/backend/internal/repository"
 )
 
+var (
+	ErrTitleRequired    = errors.New("title is required")
+	ErrInvalidFilter    = errors.New("invalid filter")
+	ErrInvalidQuery     = query.ErrInvalid
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidMove      = errors.New("a task can only move between two other tasks, the first one above the second")
+)
+
+// sorts are the TaskFilter.Sort values.
+var sorts = []string{"created", "manual", "due", "priority", "title"}
+
 type TaskService interface {
-	GetTasks(userID int64, filter string) ([]models.Task, error)
+	GetTasks(userID int64, filter, sort string) ([]models.Task, error)
+	ListTasks(userID int64, filter, cursor string, limit int) (*models.TaskPage, error)
+	SearchTasks(userID int64, query string) ([]models.Task, error)
+	Search(userID int64, query string) ([]models.SearchResult, error)
+	QueryTasks(f models.TaskFilter) ([]models.Task, error)
+	GetTask(userID, id int64) (*models.Task, error)
 	AddTask(task *models.Task) (*models.Task, error)
 	UpdateTask(task *models.Task) (*models.Task, error)
-	ToggleTask(userID, id int64) (*models.Task, error)
-	DeleteTask(userID, id int64) error
+	PatchTask(in models.UpdateTaskInput) (*models.Task, error)
+	// ToggleTask fails with a ConflictError unless version is 0 or the
+	// stored one.
+	ToggleTask(userID, id, version int64) (*models.Task, error)
+	CompleteTasks(userID int64, ids []int64) (int64, error)
+	MoveTask(userID, id, beforeID, afterID int64) (*models.Task, error)
+	DeleteTask(userID, id, version int64) error
+	DeleteTasks(userID int64, ids []int64) (int64, error)
 	ClearCompleted(userID int64) (int64, error)
+	Unarchive(userID, id, version int64) (*models.Task, error)
+	SetRepeatRule(userID, id int64, rule string, version int64) (*models.Task, error)
+	ClearRepeatRule(userID, id, version int64) (*models.Task, error)
+	UpdateOccurrence(task *models.Task, scope string) (*models.Task, error)
+	SkipOccurrence(userID, id, version int64) (*models.Task, error)
+	SeriesHistory(userID, seriesID int64) ([]models.SeriesOccurrence, error)
+	GetSubtasks(userID, taskID int64) ([]models.Subtask, error)
+	AddSubtask(userID, taskID int64, title string) (*models.Subtask, error)
+	UpdateSubtask(st *models.Subtask) (*models.Subtask, error)
+	ToggleSubtask(userID, id, version int64) (*models.Subtask, error)
+	DeleteSubtask(userID, id, version int64) error
+	ConvertChecklist(userID, id, version int64) (*models.Task, error)
+	// With returns a copy of the service that writes tasks and subtasks
+	// through the given repositories, as History.Record hands them out.
+	With(tasks repository.TaskRepository, subtasks repository.SubtaskRepository) TaskService
 }
 
 type taskService struct {
-	repo repository.TaskRepository
+	repo       repository.TaskRepository
+	subtasks   repository.SubtaskRepository
+	series     repository.SeriesRepository
+	categories repository.CategoryRepository
+	priorities repository.PriorityRepository
+	loc        *time.Location
+}
+
+func NewTaskService(repo repository.TaskRepository, subtasks repository.SubtaskRepository, series repository.SeriesRepository, categories repository.CategoryRepository, priorities repository.PriorityRepository) TaskService {
+	return &taskService{repo: repo, subtasks: subtasks, series: series, categories: categories, priorities: priorities, loc: time.Local}
+}
+
+func (s *taskService) With(tasks repository.TaskRepository, subtasks repository.SubtaskRepository) TaskService {
+	c := *s
+	c.repo, c.subtasks = tasks, subtasks
+	return &c
+}
+
+func normalizeTags(tags []string) []string {
+	res := []string{}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, t)
+	}
+	return res
+}
+
+func normalizeTask(task *models.Task) error {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" {
+		return ErrTitleRequired
+	}
+	task.Tags = normalizeTags(task.Tags)
+	rule, err := canonicalRule(task.RepeatRule)
+	if err != nil {
+		return err
+	}
+	task.RepeatRule = rule
+	return nil
+}
+
+// GetTasks lists the tasks a named filter selects in the order sort names,
+// newest first when it is empty.
+func (s *taskService) GetTasks(userID int64, filter, sort string) ([]models.Task, error) {
+	if err := oneOf("sort", sort, sorts...); err != nil {
+		return nil, err
+	}
+	return s.repo.GetAll(userID, filter, sort)
+}
+
+// SearchTasks runs a query in the syntax of package query, such as
+// `tag:work priority:high due<2026-11-01 -is:done "exact phrase"`, and returns
+// the tasks in Search's order.
+func (s *taskService) SearchTasks(userID int64, q string) ([]models.Task, error) {
+	res, err := s.Search(userID, q)
+	if err != nil {
+		return nil, err
+	}
+	ts := make([]models.Task, len(res))
+	for i, r := range res {
+		ts[i] = r.Task
+	}
+	return ts, nil
+}
+
+// Search ranks the tasks matching the query's words by relevance. A query
+// without words is a plain filter, whose results all have rank 0.
+func (s *taskService) Search(userID int64, q string) ([]models.SearchResult, error) {
+	ps, err := s.priorities.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := query.Parse(q, time.Now().In(s.loc), priorityNames(ps))
+	if err != nil {
+		return nil, err
+	}
+	f.UserID = userID
+	if f, err = normalizeFilter(f, ps); err != nil {
+		return nil, err
+	}
+	if len(f.Terms) > 0 {
+		return s.repo.Search(f)
+	}
+	ts, err := s.repo.Query(f)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]models.SearchResult, len(ts))
+	for i, t := range ts {
+		res[i] = models.SearchResult{Task: t, Title: html.EscapeString(t.Title), Snippet: html.EscapeString(t.Description)}
+	}
+	return res, nil
 }
 
-func NewTaskService(repo repository.TaskRepository) TaskService {
-	return &taskService{repo: repo}
+func oneOf(field, v string, allowed ...string) error {
+	if v == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s must be one of %s, got %q", ErrInvalidFilter, field, strings.Join(allowed, ", "), v)
 }
 
-func (s *taskService) GetTasks(userID int64, filter string) ([]models.Task, error) {
-	return s.repo.GetAll(userID, filter)
+func (s *taskService) QueryTasks(f models.TaskFilter) ([]models.Task, error) {
+	ps, err := s.priorities.List(f.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if f, err = normalizeFilter(f, ps); err != nil {
+		return nil, err
+	}
+	return s.repo.Query(f)
+}
+
+// normalizeFilter checks f, whose priority must be one of the levels ps.
+func normalizeFilter(f models.TaskFilter, ps []models.Priority) (models.TaskFilter, error) {
+	for _, err := range []error{
+		oneOf("status", f.Status, "active", "completed", "archived"),
+		oneOf("priority", f.Priority, priorityNames(ps)...),
+		oneOf("dateFilter", f.DateFilter, "overdue", "today", "week", "upcoming", "none"),
+		oneOf("sort", f.Sort, sorts...),
+	} {
+		if err != nil {
+			return f, err
+		}
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return f, fmt.Errorf("%w: from must be before to", ErrInvalidFilter)
+	}
+	f.Search = strings.TrimSpace(f.Search)
+	f.CategoryName = strings.TrimSpace(f.CategoryName)
+	f.Tags = normalizeTags(f.Tags)
+	f.ExcludeTags = normalizeTags(f.ExcludeTags)
+	return f, nil
+}
+
+func (s *taskService) GetTask(userID, id int64) (*models.Task, error) {
+	return s.repo.GetByID(userID, id)
 }
 
 func (s *taskService) AddTask(task *models.Task) (*models.Task, error) {
+	if err := normalizeTask(task); err != nil {
+		return nil, err
+	}
+	if err := s.checkPriority(task); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(task); err != nil {
+		return nil, err
+	}
+	if err := s.ensureSeries(task); err != nil {
+		return nil, err
+	}
 	return s.repo.Create(task)
 }
 
+// checkPriority gives t the default level when it has no priority and fails
+// with ErrInvalidPriority unless it names one of its user's levels.
+func (s *taskService) checkPriority(t *models.Task) error {
+	ps, err := s.priorities.List(t.UserID)
+	if err != nil {
+		return err
+	}
+	t.Priority, err = levelOf(ps, t.Priority)
+	return err
+}
+
+// checkCategory fails with ErrCategoryNotFound unless t has no category, one
+// of its user's live categories, or the one it is already stored with.
+func (s *taskService) checkCategory(t *models.Task) error {
+	if t.CategoryID == nil {
+		return nil
+	}
+	_, err := s.categories.Get(t.UserID, *t.CategoryID)
+	if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if t.ID != 0 {
+		cur, err := s.repo.GetByID(t.UserID, t.ID)
+		if err == nil && cur.CategoryID != nil && *cur.CategoryID == *t.CategoryID {
+			return nil
+		}
+	}
+	return ErrCategoryNotFound
+}
+
 func (s *taskService) UpdateTask(task *models.Task) (*models.Task, error) {
-	return s.repo.Update(task)
+	if err := normalizeTask(task); err != nil {
+		return nil, err
+	}
+	if err := s.checkPriority(task); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(task); err != nil {
+		return nil, err
+	}
+	if err := s.ensureSeries(task); err != nil {
+		return nil, err
+	}
+	return s.save(task)
 }
 
-func (s *taskService) ToggleTask(userID, id int64) (*models.Task, error) {
+func (s *taskService) ToggleTask(userID, id, version int64) (*models.Task, error) {
 	t, err := s.repo.GetByID(userID, id)
 	if err != nil {
 		return nil, err
 	}
-	if t.Completed {
-		t.Completed = false
-		t.CompletedAt = nil
-	} else {
-		t.Completed = true
-		now := (t.CreatedAt)
-		t.CompletedAt = &now
+	// nextOccurrence may save t, so the caller's version is checked first.
+	if err := checkVersion(t, version); err != nil {
+		return nil, err
 	}
-	return s.repo.Update(t)
+	var next *models.Task
+	if !t.Completed {
+		if next, err = s.nextOccurrence(t); err != nil {
+			return nil, err
+		}
+		if version != 0 {
+			version = t.Version
+		}
+	}
+	res, err := s.repo.SetCompleted(userID, id, !t.Completed, version, next)
+	if err != nil {
+		return nil, conflict(err, func() (*models.Task, error) { return s.repo.GetByID(userID, id) })
+	}
+	return res, nil
 }
 
-func (s *taskService) DeleteTask(userID, id int64) error {
-	return s.repo.Delete(userID, id)
+func (s *taskService) CompleteTasks(userID int64, ids []int64) (int64, error) {
+	var n int64
+	for _, id := range ids {
+		t, err := s.repo.GetByID(userID, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if t.Completed {
+			continue
+		}
+		next, err := s.nextOccurrence(t)
+		if err != nil {
+			return n, err
+		}
+		if _, err := s.repo.SetCompleted(userID, id, true, 0, next); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
 }
 
+// MoveTask puts task id between beforeID, the task that ends up right above
+// it in the manual order, and afterID, the one right below; 0 stands for the
+// top or the bottom of the list. Only the moved task changes, unless the
+// keys between the two have run out and all of them are spread out first.
+func (s *taskService) MoveTask(userID, id, beforeID, afterID int64) (*models.Task, error) {
+	if id == beforeID || id == afterID || beforeID != 0 && beforeID == afterID {
+		return nil, ErrInvalidMove
+	}
+	if _, err := s.repo.GetByID(userID, id); err != nil {
+		return nil, err
+	}
+	for rebalanced := false; ; rebalanced = true {
+		lo, err := s.positionOf(userID, beforeID)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := s.positionOf(userID, afterID)
+		if err != nil {
+			return nil, err
+		}
+		// An empty key sorts first, so nothing fits below one.
+		if afterID == 0 || lo < hi {
+			if k, ok := position.Between(lo, hi); ok {
+				return s.repo.Move(userID, id, k)
+			}
+		}
+		if rebalanced {
+			return nil, ErrInvalidMove
+		}
+		if err := s.repo.Rebalance(userID); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// positionOf is the position of task id, or "" for 0.
+func (s *taskService) positionOf(userID, id int64) (string, error) {
+	if id == 0 {
+		return "", nil
+	}
+	t, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return "", err
+	}
+	return t.Position, nil
+}
+
+func (s *taskService) DeleteTask(userID, id, version int64) error {
+	err := s.repo.Delete(userID, id, version)
+	return conflict(err, func() (*models.Task, error) { return s.repo.GetByID(userID, id) })
+}
+
+func (s *taskService) DeleteTasks(userID int64, ids []int64) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return s.repo.DeleteMany(userID, ids)
+}
+
+// ClearCompleted archives the completed tasks; they stay in the stats.
 func (s *taskService) ClearCompleted(userID int64) (int64, error) {
 	return s.repo.ClearCompleted(userID)
 }
+
+func (s *taskService) Unarchive(userID, id, version int64) (*models.Task, error) {
+	res, err := s.repo.Unarchive(userID, id, version)
+	if err != nil {
+		return nil, conflict(err, func() (*models.Task, error) { return s.repo.GetByID(userID, id) })
+	}
+	return res, nil
+}
+
+func (s *taskService) SetRepeatRule(userID, id int64, rule string, version int64) (*models.Task, error) {
+	t, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(t, version); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rule) == "" {
+		return nil, ErrInvalidRepeatRule
+	}
+	if t.DueDate == nil {
+		return nil, ErrRepeatNeedsDueDate
+	}
+	t.RepeatRule = &rule
+	if err := normalizeTask(t); err != nil {
+		return nil, err
+	}
+	if err := s.syncSeriesRule(t); err != nil {
+		return nil, err
+	}
+	return s.UpdateTask(t)
+}
+
+// ClearRepeatRule stops the task repeating. A series it belongs to is ended
+// rather than deleted, so its history stays available.
+func (s *taskService) ClearRepeatRule(userID, id, version int64) (*models.Task, error) {
+	t, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(t, version); err != nil {
+		return nil, err
+	}
+	t.RepeatRule = nil
+	if err := s.syncSeriesRule(t); err != nil {
+		return nil, err
+	}
+	return s.UpdateTask(t)
+}
+
+// syncSeriesRule carries the task's rule over to its series: a rule replaces
+// the series' one and resumes it, no rule ends the series.
+func (s *taskService) syncSeriesRule(t *models.Task) error {
+	if t.SeriesID == nil {
+		return nil
+	}
+	series, err := s.series.Get(t.UserID, *t.SeriesID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.RepeatRule != nil:
+		series.RepeatRule = *t.RepeatRule
+		series.EndedAt = nil
+	case series.EndedAt == nil:
+		now := time.Now()
+		series.EndedAt = &now
+	default:
+		return nil
+	}
+	_, err = s.series.Update(series)
+	return err
+}
+
+func (s *taskService) GetSubtasks(userID, taskID int64) ([]models.Subtask, error) {
+	return s.subtasks.List(userID, taskID)
+}
+
+func (s *taskService) AddSubtask(userID, taskID int64, title string) (*models.Subtask, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, ErrTitleRequired
+	}
+	return s.subtasks.Create(&models.Subtask{UserID: userID, TaskID: taskID, Title: title})
+}
+
+// UpdateSubtask saves the title and completion state of st, failing with a
+// ConflictError if st.Version is stale.
+func (s *taskService) UpdateSubtask(st *models.Subtask) (*models.Subtask, error) {
+	st.Title = strings.TrimSpace(st.Title)
+	if st.Title == "" {
+		return nil, ErrTitleRequired
+	}
+	res, err := s.subtasks.Update(st)
+	if err != nil {
+		return nil, conflict(err, func() (*models.Subtask, error) { return s.subtasks.Get(st.UserID, st.ID) })
+	}
+	return res, nil
+}
+
+func (s *taskService) ToggleSubtask(userID, id, version int64) (*models.Subtask, error) {
+	res, err := s.subtasks.Toggle(userID, id, version)
+	if err != nil {
+		return nil, conflict(err, func() (*models.Subtask, error) { return s.subtasks.Get(userID, id) })
+	}
+	return res, nil
+}
+
+func (s *taskService) DeleteSubtask(userID, id, version int64) error {
+	err := s.subtasks.Delete(userID, id, version)
+	return conflict(err, func() (*models.Subtask, error) { return s.subtasks.Get(userID, id) })
+}
+
+// ConvertChecklist turns the "- [ ]" items of the task's description into
+// subtasks, keeping their checked state, and removes them from the
+// description in the same transaction.
+func (s *taskService) ConvertChecklist(userID, id, version int64) (*models.Task, error) {
+	t, err := s.repo.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(t, version); err != nil {
+		return nil, err
+	}
+	items, rest := markdown.Checklist(t.Description)
+	if len(items) == 0 {
+		return t, nil
+	}
+	subtasks := make([]models.Subtask, len(items))
+	for i, it := range items {
+		subtasks[i] = models.Subtask{Title: it.Text, Completed: it.Done}
+	}
+	t.Description = rest
+	res, err := s.repo.UpdateWithSubtasks(t, subtasks)
+	if err != nil {
+		return nil, conflict(err, func() (*models.Task, error) { return s.repo.GetByID(userID, id) })
+	}
+	return res, nil
+}
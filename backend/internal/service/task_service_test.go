@@ -0,0 +1,1236 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/query"
+	"todo-app/backend/internal/repository"
+)
+
+type fakeTaskRepo struct {
+	tasks  map[int64]*models.Task
+	nextID int64
+}
+
+func newFakeTaskRepo() *fakeTaskRepo {
+	return &fakeTaskRepo{tasks: map[int64]*models.Task{}}
+}
+
+func (r *fakeTaskRepo) GetAll(userID int64, filter, sort string) ([]models.Task, error) {
+	var res []models.Task
+	for _, t := range r.tasks {
+		if t.UserID == userID {
+			res = append(res, *t)
+		}
+	}
+	return res, nil
+}
+
+func (r *fakeTaskRepo) Search(f models.TaskFilter) ([]models.SearchResult, error) {
+	ts, _ := r.GetAll(f.UserID, "", "")
+	res := make([]models.SearchResult, len(ts))
+	for i, t := range ts {
+		res[i] = models.SearchResult{Task: t, Title: t.Title}
+	}
+	return res, nil
+}
+
+func (r *fakeTaskRepo) Query(f models.TaskFilter) ([]models.Task, error) {
+	return r.GetAll(f.UserID, "", "")
+}
+
+func (r *fakeTaskRepo) Page(p models.PageQuery) ([]models.Task, error) {
+	return r.GetAll(p.Filter.UserID, "", "")
+}
+
+func (r *fakeTaskRepo) GetByID(userID, id int64) (*models.Task, error) {
+	t, ok := r.tasks[id]
+	if !ok || t.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	c := *t
+	return &c, nil
+}
+
+func (r *fakeTaskRepo) Create(task *models.Task) (*models.Task, error) {
+	r.nextID++
+	task.ID = r.nextID
+	task.CreatedAt = time.Now()
+	c := *task
+	r.tasks[task.ID] = &c
+	return task, nil
+}
+
+func (r *fakeTaskRepo) Update(task *models.Task) (*models.Task, error) {
+	if _, err := r.GetByID(task.UserID, task.ID); err != nil {
+		return nil, err
+	}
+	c := *task
+	r.tasks[task.ID] = &c
+	return task, nil
+}
+
+func (r *fakeTaskRepo) UpdateWithSubtasks(task *models.Task, subtasks []models.Subtask) (*models.Task, error) {
+	return nil, errors.New("fakeTaskRepo does not store subtasks")
+}
+
+func (r *fakeTaskRepo) SetCompleted(userID, id int64, completed bool, version int64, next *models.Task) (*models.Task, error) {
+	t, err := r.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Completed = completed
+	t.CompletedAt = nil
+	if completed {
+		now := time.Now()
+		t.CompletedAt = &now
+	}
+	r.tasks[id] = t
+	if next != nil {
+		r.Create(next)
+	}
+	return r.GetByID(userID, id)
+}
+
+func (r *fakeTaskRepo) Delete(userID, id, version int64) error {
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *fakeTaskRepo) DeleteMany(userID int64, ids []int64) (int64, error) {
+	var n int64
+	for _, id := range ids {
+		if _, ok := r.tasks[id]; ok {
+			delete(r.tasks, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeTaskRepo) ClearCompleted(userID int64) (int64, error) {
+	var n int64
+	for id, t := range r.tasks {
+		if t.UserID == userID && t.Completed {
+			delete(r.tasks, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeTaskRepo) Unarchive(userID, id, version int64) (*models.Task, error) {
+	t, ok := r.tasks[id]
+	if !ok || t.UserID != userID || t.ArchivedAt == nil {
+		return nil, repository.ErrNotFound
+	}
+	t.ArchivedAt = nil
+	c := *t
+	return &c, nil
+}
+
+func (r *fakeTaskRepo) ArchiveCompleted(before time.Time) (int64, error) {
+	var n int64
+	for _, t := range r.tasks {
+		if t.Completed && t.ArchivedAt == nil && t.CompletedAt != nil && t.CompletedAt.Before(before) {
+			t.ArchivedAt = &before
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeTaskRepo) Restore(task *models.Task) error {
+	c := *task
+	r.tasks[task.ID] = &c
+	return nil
+}
+
+func (r *fakeTaskRepo) Move(userID, id int64, pos string) (*models.Task, error) {
+	t, ok := r.tasks[id]
+	if !ok || t.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	t.Position = pos
+	c := *t
+	return &c, nil
+}
+
+func (r *fakeTaskRepo) Rebalance(userID int64) error {
+	return nil
+}
+
+// newFakeService pairs the fake task repository with in-memory subtasks and
+// series.
+func newFakeService(repo *fakeTaskRepo) TaskService {
+	store := repository.NewMemoryStore()
+	return NewTaskService(repo, repository.NewMemorySubtaskRepository(store), repository.NewMemorySeriesRepository(store), repository.NewMemoryCategoryRepository(store), repository.NewMemoryPriorityRepository(store))
+}
+
+func newMemoryService() TaskService {
+	store := repository.NewMemoryStore()
+	svc := NewTaskService(
+		repository.NewMemoryTaskRepository(store),
+		repository.NewMemorySubtaskRepository(store),
+		repository.NewMemorySeriesRepository(store),
+		repository.NewMemoryCategoryRepository(store),
+		repository.NewMemoryPriorityRepository(store),
+	)
+	svc.(*taskService).loc = time.UTC
+	return svc
+}
+
+func TestAddTaskValidates(t *testing.T) {
+	svc := newFakeService(newFakeTaskRepo())
+
+	if _, err := svc.AddTask(&models.Task{UserID: 1, Title: "   "}); !errors.Is(err, ErrTitleRequired) {
+		t.Fatalf("expected ErrTitleRequired, got %v", err)
+	}
+
+	if _, err := svc.AddTask(&models.Task{UserID: 1, Title: "report", Priority: "urgent"}); !errors.Is(err, ErrInvalidPriority) {
+		t.Fatalf("expected ErrInvalidPriority, got %v", err)
+	}
+
+	task, err := svc.AddTask(&models.Task{UserID: 1, Title: "  write report ", Tags: []string{" work", "", "Work", "home"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != "write report" {
+		t.Errorf("title not trimmed: %q", task.Title)
+	}
+	if task.Priority != "medium" {
+		t.Errorf("blank priority did not get the default level: %q", task.Priority)
+	}
+	if len(task.Tags) != 2 || task.Tags[0] != "work" || task.Tags[1] != "home" {
+		t.Errorf("tags not normalized: %v", task.Tags)
+	}
+}
+
+func TestToggleRepeatingTaskSpawnsNext(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := newFakeService(repo)
+	svc.(*taskService).loc = time.UTC
+	due := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	rule := "monthly"
+	task, err := svc.AddTask(&models.Task{UserID: 1, Title: "rent", Priority: "high", DueDate: &due, RepeatRule: &rule, Tags: []string{"home"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := svc.ToggleTask(1, task.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done.Completed || done.CompletedAt == nil {
+		t.Fatal("task not completed")
+	}
+	if len(repo.tasks) != 2 {
+		t.Fatalf("expected a follow-up task, have %d tasks", len(repo.tasks))
+	}
+	next := repo.tasks[task.ID+1]
+	if next.Completed || next.Title != "rent" || next.Priority != "high" || next.RepeatRule == nil || *next.RepeatRule != "FREQ=MONTHLY" {
+		t.Errorf("follow-up not copied: %+v", next)
+	}
+	// February has no 31st, so RFC 5545 skips it rather than drifting to March 3.
+	if want := time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC); !next.DueDate.Equal(want) {
+		t.Errorf("next due = %v, want %v", next.DueDate, want)
+	}
+
+	if _, err := svc.ToggleTask(1, task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.tasks) != 2 {
+		t.Error("reopening a task must not spawn another occurrence")
+	}
+}
+
+func TestCompleteTasksSkipsMissingAndDone(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := newFakeService(repo)
+	a, _ := svc.AddTask(&models.Task{UserID: 1, Title: "a"})
+	b, _ := svc.AddTask(&models.Task{UserID: 1, Title: "b"})
+	if _, err := svc.ToggleTask(1, b.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := svc.CompleteTasks(1, []int64{a.ID, b.ID, 99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("completed %d tasks, want 1", n)
+	}
+}
+
+func TestSetRepeatRule(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := newFakeService(repo)
+	undated, _ := svc.AddTask(&models.Task{UserID: 1, Title: "someday"})
+	if _, err := svc.SetRepeatRule(1, undated.ID, "daily", 0); !errors.Is(err, ErrRepeatNeedsDueDate) {
+		t.Fatalf("expected ErrRepeatNeedsDueDate, got %v", err)
+	}
+
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	task, _ := svc.AddTask(&models.Task{UserID: 1, Title: "standup", DueDate: &due})
+	for _, bad := range []string{"", "every blue moon", "FREQ=SECONDLY"} {
+		if _, err := svc.SetRepeatRule(1, task.ID, bad, 0); !errors.Is(err, ErrInvalidRepeatRule) {
+			t.Errorf("rule %q: expected ErrInvalidRepeatRule, got %v", bad, err)
+		}
+	}
+	got, err := svc.SetRepeatRule(1, task.ID, "every weekday", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A rule that no longer parses fails completion instead of silently
+	// ending the series.
+	broken := "FREQ=FORTNIGHTLY"
+	stored, _ := repo.Create(&models.Task{UserID: 1, Title: "broken", DueDate: &due, RepeatRule: &broken})
+	if _, err := svc.ToggleTask(1, stored.ID, 0); !errors.Is(err, ErrInvalidRepeatRule) {
+		t.Fatalf("completing a task with a broken rule: got %v, want ErrInvalidRepeatRule", err)
+	}
+	if got.RepeatRule == nil || *got.RepeatRule != "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR" {
+		t.Fatalf("rule not stored canonically: %v", got.RepeatRule)
+	}
+	got, err = svc.ClearRepeatRule(1, task.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.RepeatRule != nil {
+		t.Fatalf("rule not cleared: %v", *got.RepeatRule)
+	}
+}
+
+// TestToggleTaskWithoutSeries completes a repeating task stored before series
+// existed: giving it a series must not make the caller's version stale, and a
+// stale toggle must not create the series.
+func TestToggleTaskWithoutSeries(t *testing.T) {
+	store := repository.NewMemoryStore()
+	repo := repository.NewMemoryTaskRepository(store)
+	seriesRepo := repository.NewMemorySeriesRepository(store)
+	svc := NewTaskService(repo, repository.NewMemorySubtaskRepository(store), seriesRepo, repository.NewMemoryCategoryRepository(store), repository.NewMemoryPriorityRepository(store))
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "FREQ=DAILY"
+	old, err := repo.Create(&models.Task{UserID: 1, Title: "water plants", Priority: "medium", DueDate: &due, RepeatRule: &rule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := repo.Create(&models.Task{UserID: 1, Title: "feed cat", Priority: "medium", DueDate: &due, RepeatRule: &rule})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ToggleTask(1, other.ID, other.Version+1); !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("stale toggle: got %v, want a conflict", err)
+	}
+	if cur, _ := repo.GetByID(1, other.ID); cur.SeriesID != nil || cur.Version != other.Version {
+		t.Fatalf("stale toggle wrote the task: %+v", cur)
+	}
+
+	done, err := svc.ToggleTask(1, old.ID, old.Version)
+	if err != nil {
+		t.Fatalf("toggle at the current version: %v", err)
+	}
+	if !done.Completed || done.SeriesID == nil {
+		t.Fatalf("got %+v, want a completed task in a series", done)
+	}
+	if _, err := seriesRepo.Get(1, *done.SeriesID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepeatingTaskStopsAfterCount(t *testing.T) {
+	repo := newFakeTaskRepo()
+	svc := newFakeService(repo)
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "FREQ=DAILY;COUNT=2"
+	first, err := svc.AddTask(&models.Task{UserID: 1, Title: "pill", DueDate: &due, RepeatRule: &rule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ToggleTask(1, first.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	second := repo.tasks[first.ID+1]
+	if second == nil || *second.RepeatRule != "FREQ=DAILY;COUNT=1" {
+		t.Fatalf("second occurrence missing or count not advanced: %+v", second)
+	}
+	if _, err := svc.ToggleTask(1, second.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.tasks) != 2 {
+		t.Fatalf("COUNT=2 produced %d tasks", len(repo.tasks))
+	}
+}
+
+func TestNextOccurrenceUsesLocalWallClock(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tz database not available")
+	}
+	repo := newFakeTaskRepo()
+	svc := newFakeService(repo)
+	svc.(*taskService).loc = ny
+	// 09:00 in New York the day before clocks go forward, stored as UTC the
+	// way the database returns it.
+	due := time.Date(2025, 3, 8, 9, 0, 0, 0, ny).UTC()
+	rule := "daily"
+	task, _ := svc.AddTask(&models.Task{UserID: 1, Title: "walk", DueDate: &due, RepeatRule: &rule})
+	if _, err := svc.ToggleTask(1, task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	next := repo.tasks[task.ID+1].DueDate.In(ny)
+	if next.Hour() != 9 || next.Day() != 9 {
+		t.Fatalf("next occurrence at %v, want 09:00 on March 9 local", next)
+	}
+}
+
+func TestSeriesOccurrenceCopiesTemplate(t *testing.T) {
+	svc := newMemoryService()
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "weekly"
+	first, err := svc.AddTask(&models.Task{
+		UserID: 1, Title: "review", Description: "weekly review", DueDate: &due, RepeatRule: &rule,
+		Subtasks: []models.Subtask{{Title: "inbox"}, {Title: "calendar"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.SeriesID == nil || first.OccurrenceAt == nil || !first.OccurrenceAt.Equal(due) {
+		t.Fatalf("repeating task not linked to a series: %+v", first)
+	}
+	if _, err := svc.ToggleTask(1, first.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	active, _ := svc.GetTasks(1, "active", "")
+	if len(active) != 1 {
+		t.Fatalf("want one open occurrence, got %d", len(active))
+	}
+	next := active[0]
+	if next.Description != "weekly review" || next.SeriesID == nil || *next.SeriesID != *first.SeriesID {
+		t.Errorf("next occurrence lost description or series: %+v", next)
+	}
+	subtasks, _ := svc.GetSubtasks(1, next.ID)
+	if len(subtasks) != 2 || subtasks[0].Title != "inbox" || subtasks[1].Title != "calendar" {
+		t.Errorf("subtasks not copied: %+v", subtasks)
+	}
+}
+
+func TestUpdateOccurrenceScopes(t *testing.T) {
+	svc := newMemoryService()
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "daily"
+	first, _ := svc.AddTask(&models.Task{UserID: 1, Title: "walk", DueDate: &due, RepeatRule: &rule})
+
+	// Moving one occurrence leaves the template and the schedule alone.
+	moved := due.Add(6 * time.Hour)
+	first.Title, first.DueDate = "long walk", &moved
+	if _, err := svc.UpdateOccurrence(first, ScopeThis); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ToggleTask(1, first.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	active, _ := svc.GetTasks(1, "active", "")
+	second := active[0]
+	if want := due.AddDate(0, 0, 1); second.Title != "walk" || !second.DueDate.Equal(want) {
+		t.Fatalf("second occurrence = %q at %v, want %q at %v", second.Title, second.DueDate, "walk", want)
+	}
+
+	later := second.DueDate.Add(time.Hour)
+	second.Title, second.DueDate = "run", &later
+	if _, err := svc.UpdateOccurrence(&second, ScopeFuture); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ToggleTask(1, second.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	active, _ = svc.GetTasks(1, "active", "")
+	if want := later.AddDate(0, 0, 1); active[0].Title != "run" || !active[0].DueDate.Equal(want) {
+		t.Fatalf("third occurrence = %q at %v, want %q at %v", active[0].Title, active[0].DueDate, "run", want)
+	}
+
+	if _, err := svc.UpdateOccurrence(&active[0], "all"); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("expected ErrInvalidScope, got %v", err)
+	}
+}
+
+func TestSkipOccurrenceAndHistory(t *testing.T) {
+	svc := newMemoryService()
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "daily"
+	first, _ := svc.AddTask(&models.Task{UserID: 1, Title: "stretch", DueDate: &due, RepeatRule: &rule})
+	if _, err := svc.ToggleTask(1, first.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SkipOccurrence(1, first.ID, 0); !errors.Is(err, ErrOccurrenceCompleted) {
+		t.Fatalf("expected ErrOccurrenceCompleted, got %v", err)
+	}
+	active, _ := svc.GetTasks(1, "active", "")
+	third, err := svc.SkipOccurrence(1, active[0].ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := due.AddDate(0, 0, 2); third == nil || !third.DueDate.Equal(want) {
+		t.Fatalf("skip returned %+v, want an occurrence due %v", third, want)
+	}
+	if _, err := svc.GetTask(1, active[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("skipped occurrence still exists: %v", err)
+	}
+
+	history, err := svc.SeriesHistory(1, *first.SeriesID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{models.OccurrenceCompleted, models.OccurrenceSkipped, models.OccurrenceOpen}
+	if len(history) != len(want) {
+		t.Fatalf("history has %d entries, want %d: %+v", len(history), len(want), history)
+	}
+	for i, o := range history {
+		if o.Status != want[i] || !o.OccurrenceAt.Equal(due.AddDate(0, 0, i)) {
+			t.Errorf("history[%d] = %s at %v, want %s at %v", i, o.Status, o.OccurrenceAt, want[i], due.AddDate(0, 0, i))
+		}
+	}
+	if _, err := svc.SeriesHistory(2, *first.SeriesID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("another user's series: expected ErrNotFound, got %v", err)
+	}
+
+	plain, _ := svc.AddTask(&models.Task{UserID: 1, Title: "once", DueDate: &due})
+	if _, err := svc.SkipOccurrence(1, plain.ID, 0); !errors.Is(err, ErrNotRepeating) {
+		t.Errorf("expected ErrNotRepeating, got %v", err)
+	}
+}
+
+func TestClearRepeatRuleEndsSeries(t *testing.T) {
+	svc := newMemoryService()
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "daily"
+	task, _ := svc.AddTask(&models.Task{UserID: 1, Title: "water plants", DueDate: &due, RepeatRule: &rule})
+	if _, err := svc.ClearRepeatRule(1, task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ToggleTask(1, task.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if active, _ := svc.GetTasks(1, "active", ""); len(active) != 0 {
+		t.Fatalf("ended series spawned %d occurrences", len(active))
+	}
+	history, err := svc.SeriesHistory(1, *task.SeriesID)
+	if err != nil || len(history) != 1 || history[0].Status != models.OccurrenceCompleted {
+		t.Fatalf("history of ended series = %+v, %v", history, err)
+	}
+}
+
+func TestSearchTasksUsesQuerySyntax(t *testing.T) {
+	svc := newMemoryService()
+	due := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC)
+	report, _ := svc.AddTask(&models.Task{UserID: 1, Title: "Quarterly report", Priority: "high", DueDate: &due, Tags: []string{"work"}})
+	done, _ := svc.AddTask(&models.Task{UserID: 1, Title: "Monthly report", Priority: "high", Tags: []string{"work"}})
+	svc.AddTask(&models.Task{UserID: 1, Title: "Quarterly taxes", Priority: "high", Tags: []string{"home"}})
+	if _, err := svc.ToggleTask(1, done.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.SearchTasks(1, `tag:work priority:high due<2026-11-01 -is:done "quarterly report"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != report.ID {
+		t.Fatalf("search returned %v", got)
+	}
+
+	_, err = svc.SearchTasks(1, "tag:work priority:urgent")
+	var qe *query.Error
+	if !errors.As(err, &qe) || !errors.Is(err, ErrInvalidQuery) || qe.Token != "priority:urgent" {
+		t.Fatalf("expected a query error at priority:urgent, got %v", err)
+	}
+	if _, err := svc.QueryTasks(models.TaskFilter{UserID: 1, Sort: "random"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+}
+
+func TestSearchRanksTermsAndEscapesFilters(t *testing.T) {
+	svc := newMemoryService()
+	notes, _ := svc.AddTask(&models.Task{UserID: 1, Title: "Notes", Description: "draft the report", Tags: []string{"work"}})
+	report, _ := svc.AddTask(&models.Task{UserID: 1, Title: "Report <draft>", Tags: []string{"work"}})
+
+	res, err := svc.Search(1, "repo tag:work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Task.ID != report.ID || res[1].Task.ID != notes.ID {
+		t.Fatalf("search returned %+v", res)
+	}
+	if res[0].Title != "<mark>Repo</mark>rt &lt;draft&gt;" {
+		t.Errorf("title = %q", res[0].Title)
+	}
+
+	// Without words the query only filters, newest first, with plain titles.
+	res, err = svc.Search(1, "tag:work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Task.ID != report.ID || res[0].Rank != 0 || res[0].Title != "Report &lt;draft&gt;" {
+		t.Fatalf("filter-only search returned %+v", res)
+	}
+}
+
+func TestListTasksPagesBothWays(t *testing.T) {
+	svc := newMemoryService()
+	var created []int64
+	for i := 0; i < 5; i++ {
+		task, err := svc.AddTask(&models.Task{UserID: 1, Title: fmt.Sprint("task ", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append([]int64{task.ID}, created...)
+	}
+	pageIDs := func(p *models.TaskPage) []int64 {
+		var res []int64
+		for _, task := range p.Items {
+			res = append(res, task.ID)
+		}
+		return res
+	}
+
+	first, err := svc.ListTasks(1, "all", "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.ListTasks(1, "all", first.Next, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := svc.ListTasks(1, "all", second.Next, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(pageIDs(first), pageIDs(second), pageIDs(last)) != fmt.Sprint(created[:2], created[2:4], created[4:]) {
+		t.Fatalf("pages %v %v %v, want %v", pageIDs(first), pageIDs(second), pageIDs(last), created)
+	}
+	if first.Prev != "" || last.Next != "" {
+		t.Fatalf("cursors past the ends: prev %q, next %q", first.Prev, last.Next)
+	}
+
+	back, err := svc.ListTasks(1, "all", last.Prev, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(pageIDs(back)) != fmt.Sprint(created[2:4]) || back.Next != second.Next {
+		t.Fatalf("prev page %v, want %v", pageIDs(back), created[2:4])
+	}
+	start, err := svc.ListTasks(1, "all", back.Prev, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(pageIDs(start)) != fmt.Sprint(created[:2]) || start.Prev != "" {
+		t.Fatalf("first page again %v prev %q", pageIDs(start), start.Prev)
+	}
+
+	if _, err := svc.ListTasks(1, "all", "garbage!", 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := svc.ListTasks(1, "someday", "", 2); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+}
+
+func TestViews(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tasks := NewTaskService(
+		repository.NewMemoryTaskRepository(store),
+		repository.NewMemorySubtaskRepository(store),
+		repository.NewMemorySeriesRepository(store),
+		repository.NewMemoryCategoryRepository(store),
+		repository.NewMemoryPriorityRepository(store),
+	)
+	views := NewViewService(repository.NewMemoryViewRepository(store), tasks, repository.NewMemoryPriorityRepository(store))
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	later, _ := tasks.AddTask(&models.Task{UserID: 1, Title: "later", DueDate: &tomorrow, Tags: []string{"work"}})
+	someday, _ := tasks.AddTask(&models.Task{UserID: 1, Title: "someday"})
+
+	upcoming, err := views.Tasks(1, -3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upcoming) != 1 || upcoming[0].ID != later.ID {
+		t.Fatalf("Upcoming = %v", upcoming)
+	}
+	if ts, _ := views.Tasks(1, -4); len(ts) != 1 || ts[0].ID != someday.ID {
+		t.Fatalf("Someday = %v", ts)
+	}
+
+	if _, err := views.Create(1, " ", models.TaskFilter{}); !errors.Is(err, ErrNameRequired) {
+		t.Fatalf("expected ErrNameRequired, got %v", err)
+	}
+	if _, err := views.Create(1, "bad", models.TaskFilter{Sort: "random"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+	work, err := views.Create(1, " Work ", models.TaskFilter{Tags: []string{" work "}})
+	if err != nil || work.Name != "Work" {
+		t.Fatalf("create: %+v, %v", work, err)
+	}
+	if ts, _ := views.Tasks(1, work.ID); len(ts) != 1 || ts[0].ID != later.ID {
+		t.Fatalf("Work = %v", ts)
+	}
+	if _, err := views.Tasks(2, work.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("another user's view: %v", err)
+	}
+	if renamed, err := views.Rename(1, work.ID, "Job"); err != nil || renamed.Name != "Job" || len(renamed.Filter.Tags) != 1 {
+		t.Fatalf("rename: %+v, %v", renamed, err)
+	}
+	if _, err := views.Rename(1, -1, "Mine"); !errors.Is(err, ErrBuiltInView) {
+		t.Fatalf("renaming a built-in view: %v", err)
+	}
+	if err := views.Delete(1, -2); !errors.Is(err, ErrBuiltInView) {
+		t.Fatalf("deleting a built-in view: %v", err)
+	}
+
+	home, _ := views.Create(1, "Home", models.TaskFilter{})
+	if err := views.Reorder(1, []int64{home.ID}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("partial order: %v", err)
+	}
+	if err := views.Reorder(1, []int64{home.ID, home.ID}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("duplicate ids: %v", err)
+	}
+	if err := views.Reorder(1, []int64{home.ID, work.ID}); err != nil {
+		t.Fatal(err)
+	}
+	all, err := views.List(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, v := range all {
+		names = append(names, v.Name)
+	}
+	if fmt.Sprint(names) != "[Inbox Today Upcoming Someday Home Job]" || !all[0].BuiltIn || all[4].BuiltIn {
+		t.Fatalf("views = %v", all)
+	}
+}
+
+func TestConvertChecklist(t *testing.T) {
+	svc := newMemoryService()
+	task, err := svc.AddTask(&models.Task{UserID: 1, Title: "Trip", Description: "Pack:\n\n- [ ] passport\n- [x] tickets\n\n```\n- [ ] not an item\n```"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err = svc.ConvertChecklist(1, task.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Description != "Pack:\n\n```\n- [ ] not an item\n```" {
+		t.Errorf("description = %q", task.Description)
+	}
+	subs, err := svc.GetSubtasks(1, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].Title != "passport" || subs[0].Completed || subs[1].Title != "tickets" || !subs[1].Completed {
+		t.Fatalf("subtasks = %+v", subs)
+	}
+	if _, err := svc.ConvertChecklist(2, task.ID, 0); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("another user's task: %v", err)
+	}
+}
+
+func TestPatchTask(t *testing.T) {
+	svc := newMemoryService()
+	due := time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC)
+	cat, err := svc.(*taskService).categories.Create(models.Category{UserID: 1, Name: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := svc.AddTask(&models.Task{UserID: 1, Title: "Report", Description: "draft", Priority: "high", DueDate: &due, CategoryID: &cat.ID, Tags: []string{"work"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title := "Final report"
+	task, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, Title: &title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != title || task.Description != "draft" || task.Priority != "high" || task.DueDate == nil || task.CategoryID == nil || len(task.Tags) != 1 {
+		t.Fatalf("unset fields changed: %+v", task)
+	}
+
+	task, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, ClearDueDate: true, ClearCategory: true, Tags: []string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.DueDate != nil || task.CategoryID != nil || len(task.Tags) != 0 || task.Title != title {
+		t.Fatalf("fields not cleared: %+v", task)
+	}
+
+	blank, bad, rule := " ", "urgent", "every day"
+	_, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, Title: &blank, Priority: &bad, RepeatRule: &rule})
+	var fe FieldErrors
+	if !errors.As(err, &fe) || len(fe) != 2 || fe["title"] == "" || fe["priority"] == "" {
+		t.Fatalf("expected title and priority errors, got %v", err)
+	}
+	_, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, RepeatRule: &rule})
+	if !errors.As(err, &fe) || fe["repeatRule"] != ErrRepeatNeedsDueDate.Error() {
+		t.Fatalf("rule without due date: %v", err)
+	}
+	if got, _ := svc.GetTask(1, task.ID); got.Title != title || got.Priority != "high" {
+		t.Fatalf("invalid patch was saved: %+v", got)
+	}
+
+	task, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, DueDate: &due, RepeatRule: &rule})
+	if err != nil || task.RepeatRule == nil || task.SeriesID == nil {
+		t.Fatalf("repeat: %+v, %v", task, err)
+	}
+	none := ""
+	task, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, RepeatRule: &none})
+	if err != nil || task.RepeatRule != nil {
+		t.Fatalf("clear rule: %+v, %v", task, err)
+	}
+}
+
+func TestStaleEditReturnsCurrentState(t *testing.T) {
+	svc := newMemoryService()
+	task, err := svc.AddTask(&models.Task{UserID: 1, Title: "draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := *task
+	title := "from the app"
+	if _, err := svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, Title: &title, Version: seen.Version}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := "from the CLI"
+	_, err = svc.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, Title: &other, Version: seen.Version})
+	var ce *ConflictError
+	if !errors.As(err, &ce) || !errors.Is(err, repository.ErrConflict) {
+		t.Fatalf("expected ConflictError, got %v", err)
+	}
+	if cur, ok := ce.Current.(*models.Task); !ok || cur.Title != title || cur.Version != seen.Version+1 {
+		t.Fatalf("current = %+v", ce.Current)
+	}
+
+	seen.Title = other
+	if _, err := svc.UpdateTask(&seen); !errors.As(err, &ce) {
+		t.Fatalf("UpdateTask with a stale version: %v", err)
+	}
+	if got, _ := svc.GetTask(1, task.ID); got.Title != title {
+		t.Fatalf("stale edit was saved: %+v", got)
+	}
+}
+
+// record runs fn as a command of user 1's session 0, with svc writing
+// through the step.
+func record(h *History, svc TaskService, label string, fn func(svc TaskService) error) error {
+	return h.Record(1, 0, label, func(tasks repository.TaskRepository, subtasks repository.SubtaskRepository) error {
+		return fn(svc.With(tasks, subtasks))
+	})
+}
+
+func TestUndoRedo(t *testing.T) {
+	store := repository.NewMemoryStore()
+	h := NewHistory(repository.NewMemoryTaskRepository(store), repository.NewMemorySubtaskRepository(store), 2)
+	svc := NewTaskService(repository.NewMemoryTaskRepository(store), repository.NewMemorySubtaskRepository(store), repository.NewMemorySeriesRepository(store), repository.NewMemoryCategoryRepository(store), repository.NewMemoryPriorityRepository(store))
+	svc.(*taskService).loc = time.UTC
+	count := func() int {
+		ts, err := svc.GetTasks(1, "all", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(ts)
+	}
+
+	due := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	rule := "daily"
+	var daily, plain *models.Task
+	err := record(h, svc, "Add task", func(svc TaskService) (err error) {
+		daily, err = svc.AddTask(&models.Task{UserID: 1, Title: "standup", DueDate: &due, RepeatRule: &rule})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err = svc.AddTask(&models.Task{UserID: 1, Title: "plain", Subtasks: []models.Subtask{{Title: "a"}, {Title: "b"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := record(h, svc, "Toggle task", func(svc TaskService) error {
+		_, err := svc.ToggleTask(1, daily.ID, 0)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if count() != 3 {
+		t.Fatalf("completing a daily task should add the next occurrence")
+	}
+	if label, err := h.Undo(1, 0); err != nil || label != "Toggle task" {
+		t.Fatalf("Undo = %q, %v", label, err)
+	}
+	if got, _ := svc.GetTask(1, daily.ID); got.Completed || count() != 2 {
+		t.Fatalf("undo left %+v and %d tasks", got, count())
+	}
+	if _, err := h.Redo(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.GetTask(1, daily.ID); !got.Completed || count() != 3 {
+		t.Fatalf("redo left %+v and %d tasks", got, count())
+	}
+
+	if err := record(h, svc, "Delete tasks", func(svc TaskService) error {
+		_, err := svc.DeleteTasks(1, []int64{plain.ID})
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Undo(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if subs, _ := svc.GetSubtasks(1, plain.ID); len(subs) != 2 {
+		t.Fatalf("undo restored subtasks %+v", subs)
+	}
+
+	// The limit of 2 dropped "Add task", and the Delete undone above sits on
+	// the redo stack.
+	if undo, redo := h.Labels(1, 0); undo != "Toggle task" || redo != "Delete tasks" {
+		t.Fatalf("Labels = %q, %q", undo, redo)
+	}
+	if _, err := h.Undo(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Undo(1, 0); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	// A change made outside the history makes the step stale.
+	if err := record(h, svc, "Toggle task", func(svc TaskService) error {
+		_, err := svc.ToggleTask(1, plain.ID, 0)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AddSubtask(1, plain.ID, "late"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Undo(1, 0); !errors.Is(err, ErrHistoryStale) {
+		t.Fatalf("expected ErrHistoryStale, got %v", err)
+	}
+	if got, _ := svc.GetTask(1, plain.ID); !got.Completed {
+		t.Fatal("a stale undo was applied")
+	}
+}
+
+// TestUndoAfterMove checks that moving a task, which is not recorded, does
+// not stale the edit recorded before it.
+func TestUndoAfterMove(t *testing.T) {
+	store := repository.NewMemoryStore()
+	h := NewHistory(repository.NewMemoryTaskRepository(store), repository.NewMemorySubtaskRepository(store), DefaultHistoryLimit)
+	svc := NewTaskService(repository.NewMemoryTaskRepository(store), repository.NewMemorySubtaskRepository(store), repository.NewMemorySeriesRepository(store), repository.NewMemoryCategoryRepository(store), repository.NewMemoryPriorityRepository(store))
+	a, err := svc.AddTask(&models.Task{UserID: 1, Title: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := svc.AddTask(&models.Task{UserID: 1, Title: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := *a
+	edited.Title = "renamed"
+	if err := record(h, svc, "Edit task", func(svc TaskService) (err error) {
+		a, err = svc.UpdateTask(&edited)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := svc.MoveTask(1, a.ID, 0, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Version != a.Version {
+		t.Fatalf("move bumped the version from %d to %d", a.Version, moved.Version)
+	}
+	if _, err := h.Undo(1, 0); err != nil {
+		t.Fatalf("undo after a move: %v", err)
+	}
+	got, _ := svc.GetTask(1, a.ID)
+	if got.Title != "a" || got.Position != moved.Position {
+		t.Fatalf("undo left %+v, want the old title at the new position %q", got, moved.Position)
+	}
+}
+
+// TestHistorySessions checks that a step holds only its own command's
+// writes, and that each session undoes only its own commands.
+func TestHistorySessions(t *testing.T) {
+	store := repository.NewMemoryStore()
+	h := NewHistory(repository.NewMemoryTaskRepository(store), repository.NewMemorySubtaskRepository(store), DefaultHistoryLimit)
+	svc := NewTaskService(repository.NewMemoryTaskRepository(store), repository.NewMemorySubtaskRepository(store), repository.NewMemorySeriesRepository(store), repository.NewMemoryCategoryRepository(store), repository.NewMemoryPriorityRepository(store))
+	mine, err := svc.AddTask(&models.Task{UserID: 1, Title: "mine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := svc.AddTask(&models.Task{UserID: 1, Title: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other is toggled by another client of the same user while the
+	// command runs.
+	if err := record(h, svc, "Toggle task", func(s TaskService) error {
+		if _, err := svc.ToggleTask(1, other.ID, 0); err != nil {
+			return err
+		}
+		_, err := s.ToggleTask(1, mine.ID, 0)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Undo(1, 1); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("another session undid the command: %v", err)
+	}
+	if _, err := h.Undo(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.GetTask(1, mine.ID); got.Completed {
+		t.Fatal("undo left the command's task completed")
+	}
+	if got, _ := svc.GetTask(1, other.ID); !got.Completed {
+		t.Fatal("undo reverted a write made outside the command")
+	}
+}
+
+func TestCategoryTree(t *testing.T) {
+	store := repository.NewMemoryStore()
+	tasks := NewTaskService(
+		repository.NewMemoryTaskRepository(store),
+		repository.NewMemorySubtaskRepository(store),
+		repository.NewMemorySeriesRepository(store),
+		repository.NewMemoryCategoryRepository(store),
+		repository.NewMemoryPriorityRepository(store),
+	)
+	cats := NewCategoryService(repository.NewMemoryCategoryRepository(store))
+	work, err := cats.Create(1, models.Category{Name: " Work ", Color: "#FF8800"})
+	if err != nil || work.Name != "Work" || work.Color != "#ff8800" {
+		t.Fatalf("Create = %+v, %v", work, err)
+	}
+	home, _ := cats.Create(1, models.Category{Name: "Home"})
+	reports, err := cats.Create(1, models.Category{Name: "Reports", ParentID: &work.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cats.Create(1, models.Category{Name: "work"}); !errors.Is(err, ErrCategoryExists) {
+		t.Fatalf("duplicate name: got %v", err)
+	}
+	if _, err := cats.Create(1, models.Category{Name: "Work", ParentID: &home.ID}); err != nil {
+		t.Fatalf("same name under another parent: %v", err)
+	}
+	if _, err := cats.Create(1, models.Category{Name: "x", Color: "orange"}); !errors.Is(err, ErrInvalidColor) {
+		t.Fatalf("bad color: got %v", err)
+	}
+	missing := int64(999)
+	if _, err := cats.Create(1, models.Category{Name: "x", ParentID: &missing}); !errors.Is(err, ErrParentNotFound) {
+		t.Fatalf("missing parent: got %v", err)
+	}
+	if _, err := cats.Move(1, work.ID, &reports.ID, 0, 0); !errors.Is(err, ErrCategoryCycle) {
+		t.Fatalf("move below itself: got %v", err)
+	}
+	if _, err := cats.Move(1, work.ID, &work.ID, 0, 0); !errors.Is(err, ErrCategoryCycle) {
+		t.Fatalf("move into itself: got %v", err)
+	}
+
+	tasks.AddTask(&models.Task{UserID: 1, Title: "plan", CategoryID: &work.ID})
+	tasks.AddTask(&models.Task{UserID: 1, Title: "q1", CategoryID: &reports.ID})
+	tasks.AddTask(&models.Task{UserID: 1, Title: "q2", CategoryID: &reports.ID})
+	list, err := cats.List(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range list {
+		got = append(got, fmt.Sprintf("%s %d/%d", c.Name, c.TaskCount, c.TotalCount))
+	}
+	if want := "[Work 1/3 Reports 2/2 Home 0/0 Work 0/0]"; fmt.Sprint(got) != want {
+		t.Fatalf("List = %v, want %s", got, want)
+	}
+}
+
+func TestCategoryDelete(t *testing.T) {
+	store := repository.NewMemoryStore()
+	categories := repository.NewMemoryCategoryRepository(store)
+	tasks := NewTaskService(
+		repository.NewMemoryTaskRepository(store),
+		repository.NewMemorySubtaskRepository(store),
+		repository.NewMemorySeriesRepository(store),
+		categories,
+		repository.NewMemoryPriorityRepository(store),
+	)
+	cats := NewCategoryService(categories)
+	work, _ := cats.Create(1, models.Category{Name: "Work"})
+	home, _ := cats.Create(1, models.Category{Name: "Home"})
+	theirs, _ := cats.Create(2, models.Category{Name: "Theirs"})
+
+	if _, err := tasks.AddTask(&models.Task{UserID: 1, Title: "x", CategoryID: &theirs.ID}); !errors.Is(err, ErrCategoryNotFound) {
+		t.Fatalf("another user's category: got %v", err)
+	}
+	task, err := tasks.AddTask(&models.Task{UserID: 1, Title: "plan", CategoryID: &work.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tasks.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, CategoryID: &theirs.ID})
+	var fe FieldErrors
+	if !errors.As(err, &fe) || fe["categoryId"] == "" {
+		t.Fatalf("patch to another user's category: got %v", err)
+	}
+
+	if err := cats.Delete(1, work.ID, "drop", nil, 0); !errors.Is(err, ErrInvalidDeletion) {
+		t.Fatalf("unknown strategy: got %v", err)
+	}
+	for _, to := range []*int64{nil, &work.ID, &theirs.ID} {
+		if err := cats.Delete(1, work.ID, models.CategoryMoveTasks, to, 0); !errors.Is(err, ErrMoveTarget) {
+			t.Fatalf("move to %v: got %v", to, err)
+		}
+	}
+	if err := cats.Delete(1, work.ID, models.CategoryMoveTasks, &home.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	got, err := tasks.GetTask(1, task.ID)
+	if err != nil || got.CategoryID == nil || *got.CategoryID != home.ID {
+		t.Fatalf("moved task = %+v, %v", got, err)
+	}
+	if err := cats.Delete(1, home.ID, models.CategoryDeleteTasks, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tasks.GetTask(1, task.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("task of deleted category: got %v", err)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	svc := newMemoryService()
+	ids := map[string]int64{}
+	for _, title := range []string{"c", "b", "a"} {
+		task, err := svc.AddTask(&models.Task{UserID: 1, Title: title})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = task.ID
+	}
+	order := func() string {
+		t.Helper()
+		ts, err := svc.GetTasks(1, "all", "manual")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s string
+		for _, task := range ts {
+			s += task.Title
+		}
+		return s
+	}
+	move := func(title, before, after string) {
+		t.Helper()
+		if _, err := svc.MoveTask(1, ids[title], ids[before], ids[after]); err != nil {
+			t.Fatalf("move %s between %q and %q: %v", title, before, after, err)
+		}
+	}
+	move("a", "b", "c")
+	if got := order(); got != "bac" {
+		t.Fatalf("order = %s", got)
+	}
+	move("b", "c", "")
+	move("c", "", "a")
+	if got := order(); got != "cab" {
+		t.Fatalf("order = %s", got)
+	}
+	// Keep dropping into the same gap until the keys are spread out again.
+	for i := 0; i < 300; i++ {
+		if i%2 == 0 {
+			move("b", "c", "a")
+		} else {
+			move("c", "b", "a")
+		}
+	}
+	if got := order(); got != "bca" {
+		t.Fatalf("order after many moves = %s", got)
+	}
+
+	for _, c := range [][3]int64{{ids["a"], ids["c"], ids["b"]}, {ids["a"], ids["a"], 0}, {ids["a"], ids["b"], ids["b"]}} {
+		if _, err := svc.MoveTask(1, c[0], c[1], c[2]); !errors.Is(err, ErrInvalidMove) {
+			t.Fatalf("MoveTask%v: got %v", c, err)
+		}
+	}
+	if _, err := svc.MoveTask(2, ids["a"], 0, 0); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("another user's task: got %v", err)
+	}
+	if _, err := svc.GetTasks(1, "all", "random"); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("unknown sort: got %v", err)
+	}
+}
+
+func TestPriorityScale(t *testing.T) {
+	store := repository.NewMemoryStore()
+	priorities := repository.NewMemoryPriorityRepository(store)
+	tasks := NewTaskService(
+		repository.NewMemoryTaskRepository(store),
+		repository.NewMemorySubtaskRepository(store),
+		repository.NewMemorySeriesRepository(store),
+		repository.NewMemoryCategoryRepository(store),
+		priorities,
+	)
+	svc := NewPriorityService(priorities)
+
+	for _, c := range []struct {
+		p    models.Priority
+		want error
+	}{
+		{models.Priority{Name: " "}, ErrNameRequired},
+		{models.Priority{Name: "right now"}, ErrPriorityName},
+		{models.Priority{Name: "p0", Weight: MaxWeight + 1}, ErrInvalidWeight},
+		{models.Priority{Name: "p0", Color: "red"}, ErrInvalidColor},
+		{models.Priority{Name: "High"}, ErrPriorityExists},
+	} {
+		if _, err := svc.Create(1, c.p); !errors.Is(err, c.want) {
+			t.Errorf("Create(%+v) = %v, want %v", c.p, err, c.want)
+		}
+	}
+	p0, err := svc.Create(1, models.Priority{Name: " P0 ", Weight: 500})
+	if err != nil || p0.Name != "p0" || p0.Label != "p0" {
+		t.Fatalf("create = %+v, %v", p0, err)
+	}
+
+	task, err := tasks.AddTask(&models.Task{UserID: 1, Title: "fire", Priority: "P0"})
+	if err != nil || task.Priority != "p0" {
+		t.Fatalf("add at p0 = %+v, %v", task, err)
+	}
+	if got, err := tasks.SearchTasks(1, "priority:p0"); err != nil || len(got) != 1 {
+		t.Fatalf("search priority:p0 = %v, %v", got, err)
+	}
+	if _, err := tasks.SearchTasks(2, "priority:p0"); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("another user's level in a query: %v", err)
+	}
+	bad := "urgent"
+	_, err = tasks.PatchTask(models.UpdateTaskInput{UserID: 1, ID: task.ID, Priority: &bad})
+	var fe FieldErrors
+	if !errors.As(err, &fe) || fe["priority"] != "must be one of p0, high, medium, low" {
+		t.Fatalf("patch to an unknown level: %v", err)
+	}
+
+	ps, _ := svc.List(1)
+	medium := ps[2]
+	medium.Default = false
+	if _, err := svc.Update(1, medium); !errors.Is(err, ErrDefaultPriority) {
+		t.Fatalf("dropping the default: %v", err)
+	}
+	if err := svc.Delete(1, p0.ID, p0.ID, 0); !errors.Is(err, ErrPriorityTarget) {
+		t.Fatalf("delete into itself: %v", err)
+	}
+	if err := svc.Delete(1, p0.ID, medium.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := tasks.GetTask(1, task.ID); got.Priority != "medium" {
+		t.Fatalf("task not moved: %+v", got)
+	}
+}
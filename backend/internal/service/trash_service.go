@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+type TrashService struct {
+	repo      repository.TrashRepository
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewTrashService keeps trashed items for retention; 0 or less keeps them
+// until the trash is emptied by hand.
+func NewTrashService(r repository.TrashRepository, retention time.Duration) *TrashService {
+	return &TrashService{repo: r, retention: retention, now: time.Now}
+}
+
+func (s *TrashService) List(userID int64) (models.Trash, error) {
+	return s.repo.List(userID)
+}
+
+func (s *TrashService) RestoreTask(userID, id int64) (*models.Task, error) {
+	return s.repo.RestoreTask(userID, id)
+}
+
+func (s *TrashService) RestoreSubtask(userID, id int64) (*models.Subtask, error) {
+	return s.repo.RestoreSubtask(userID, id)
+}
+
+func (s *TrashService) RestoreCategory(userID, id int64) (models.Category, error) {
+	return s.repo.RestoreCategory(userID, id)
+}
+
+func (s *TrashService) Purge(userID int64) (int64, error) {
+	return s.repo.Purge(userID)
+}
+
+// PurgeExpired permanently deletes what has been in the trash for longer
+// than the retention period.
+func (s *TrashService) PurgeExpired() (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	return s.repo.PurgeBefore(s.now().Add(-s.retention))
+}
+
+// RunPurge calls PurgeExpired right away and then every interval until ctx
+// is done.
+func (s *TrashService) RunPurge(ctx context.Context, every time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+	runEvery(ctx, every, "trash: purge", s.PurgeExpired)
+}
+
+// runEvery calls job right away and then every interval until ctx is done,
+// logging its errors under name.
+func runEvery(ctx context.Context, every time.Duration, name string, job func() (int64, error)) {
+	tick := time.NewTicker(every)
+	defer tick.Stop()
+	for {
+		if _, err := job(); err != nil {
+			log.Printf("%s: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
+)
+
+var (
+	ErrBuiltInView  = errors.New("built-in views cannot be changed")
+	ErrInvalidOrder = errors.New("order must list every saved view once")
+)
+
+// builtInViews come before the user's own views. They are plain filters like
+// any saved view; negative ids keep them apart from stored ones.
+var builtInViews = []models.SavedView{
+	{ID: -1, Name: "Inbox", Filter: models.TaskFilter{Status: "active"}},
+	{ID: -2, Name: "Today", Filter: models.TaskFilter{Status: "active", DateFilter: "today", Sort: "priority"}},
+	{ID: -3, Name: "Upcoming", Filter: models.TaskFilter{Status: "active", DateFilter: "upcoming", Sort: "due"}},
+	{ID: -4, Name: "Someday", Filter: models.TaskFilter{Status: "active", DateFilter: "none"}},
+}
+
+type ViewService struct {
+	repo       repository.ViewRepository
+	tasks      TaskService
+	priorities repository.PriorityRepository
+}
+
+func NewViewService(r repository.ViewRepository, tasks TaskService, priorities repository.PriorityRepository) *ViewService {
+	return &ViewService{repo: r, tasks: tasks, priorities: priorities}
+}
+
+func builtInView(userID, id int64) (*models.SavedView, bool) {
+	for i, v := range builtInViews {
+		if v.ID == id {
+			v.UserID, v.Filter.UserID, v.Position, v.BuiltIn = userID, userID, i, true
+			return &v, true
+		}
+	}
+	return nil, false
+}
+
+// List returns the built-in views followed by the user's saved views.
+func (s *ViewService) List(userID int64) ([]models.SavedView, error) {
+	saved, err := s.repo.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	var res []models.SavedView
+	for _, b := range builtInViews {
+		v, _ := builtInView(userID, b.ID)
+		res = append(res, *v)
+	}
+	return append(res, saved...), nil
+}
+
+func (s *ViewService) Get(userID, id int64) (*models.SavedView, error) {
+	if v, ok := builtInView(userID, id); ok {
+		return v, nil
+	}
+	return s.repo.Get(userID, id)
+}
+
+func (s *ViewService) validate(v *models.SavedView) error {
+	v.Name = strings.TrimSpace(v.Name)
+	if v.Name == "" {
+		return ErrNameRequired
+	}
+	v.Filter.UserID = v.UserID
+	ps, err := s.priorities.List(v.UserID)
+	if err != nil {
+		return err
+	}
+	f, err := normalizeFilter(v.Filter, ps)
+	if err != nil {
+		return err
+	}
+	v.Filter = f
+	return nil
+}
+
+func (s *ViewService) Create(userID int64, name string, f models.TaskFilter) (*models.SavedView, error) {
+	v := &models.SavedView{UserID: userID, Name: name, Filter: f}
+	if err := s.validate(v); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(v)
+}
+
+// Update replaces the view's name and filter.
+func (s *ViewService) Update(userID, id int64, name string, f models.TaskFilter) (*models.SavedView, error) {
+	if _, ok := builtInView(userID, id); ok {
+		return nil, ErrBuiltInView
+	}
+	v := &models.SavedView{ID: id, UserID: userID, Name: name, Filter: f}
+	if err := s.validate(v); err != nil {
+		return nil, err
+	}
+	return s.repo.Update(v)
+}
+
+func (s *ViewService) Rename(userID, id int64, name string) (*models.SavedView, error) {
+	v, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Update(userID, id, name, v.Filter)
+}
+
+// Reorder puts the saved views in the order of ids, which must list each of
+// them once. Built-in views always stay first.
+func (s *ViewService) Reorder(userID int64, ids []int64) error {
+	saved, err := s.repo.List(userID)
+	if err != nil {
+		return err
+	}
+	want := map[int64]bool{}
+	for _, v := range saved {
+		want[v.ID] = true
+	}
+	if len(ids) != len(want) {
+		return ErrInvalidOrder
+	}
+	for _, id := range ids {
+		if !want[id] {
+			return ErrInvalidOrder
+		}
+		delete(want, id)
+	}
+	return s.repo.Reorder(userID, ids)
+}
+
+func (s *ViewService) Delete(userID, id int64) error {
+	if _, ok := builtInView(userID, id); ok {
+		return ErrBuiltInView
+	}
+	return s.repo.Delete(userID, id)
+}
+
+// Tasks evaluates the view against the user's current tasks.
+func (s *ViewService) Tasks(userID, id int64) ([]models.Task, error) {
+	v, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	v.Filter.UserID = userID
+	return s.tasks.QueryTasks(v.Filter)
+}
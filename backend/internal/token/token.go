@@ -0,0 +1,126 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid token")
+	ErrExpired = errors.New("token expired")
+)
+
+type Claims struct {
+	Subject   string `json:"sub"`
+	Email     string `json:"email"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	// SessionID is the refresh token session the access token was issued
+	// for; see models.RefreshToken.
+	SessionID int64 `json:"sid,omitempty"`
+}
+
+func (c Claims) UserID() (int64, error) {
+	id, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalid
+	}
+	return id, nil
+}
+
+// Signer issues and verifies HS256 JWT access tokens.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewSigner(secret string, ttl time.Duration) *Signer {
+	return &Signer{secret: []byte(secret), ttl: ttl, now: time.Now}
+}
+
+var header = b64(mustJSON(map[string]string{"alg": "HS256", "typ": "JWT"}))
+
+func (s *Signer) Sign(userID int64, email string, sessionID int64) (string, time.Time, error) {
+	now := s.now()
+	exp := now.Add(s.ttl)
+	payload, err := json.Marshal(Claims{
+		Subject:   strconv.FormatInt(userID, 10),
+		Email:     email,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: exp.Unix(),
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	unsigned := header + "." + b64(payload)
+	return unsigned + "." + b64(s.mac(unsigned)), exp, nil
+}
+
+func (s *Signer) Verify(tok string) (Claims, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return Claims{}, ErrInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, s.mac(parts[0]+"."+parts[1])) {
+		return Claims{}, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Claims{}, ErrInvalid
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return Claims{}, ErrInvalid
+	}
+	if _, err := c.UserID(); err != nil {
+		return Claims{}, err
+	}
+	if !s.now().Before(time.Unix(c.ExpiresAt, 0)) {
+		return Claims{}, ErrExpired
+	}
+	return c, nil
+}
+
+func (s *Signer) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// NewRefreshToken returns an opaque random token and the hash to store for it.
+func NewRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	raw := b64(buf)
+	return raw, HashRefreshToken(raw), nil
+}
+
+func HashRefreshToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func mustJSON(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	s := NewSigner("secret", 15*time.Minute)
+	tok, exp, err := s.Sign(42, "a@example.com", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.Before(time.Now()) {
+		t.Fatal("expiry in the past")
+	}
+	c, err := s.Verify(tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := c.UserID(); id != 42 || c.Email != "a@example.com" || c.SessionID != 7 {
+		t.Fatalf("unexpected claims %+v", c)
+	}
+}
+
+func TestVerifyRejectsTamperingAndOtherSecrets(t *testing.T) {
+	s := NewSigner("secret", time.Minute)
+	tok, _, _ := s.Sign(1, "a@example.com", 0)
+
+	if _, err := NewSigner("other", time.Minute).Verify(tok); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("foreign secret: got %v", err)
+	}
+	parts := strings.Split(tok, ".")
+	forged, _, _ := NewSigner("other", time.Minute).Sign(2, "b@example.com", 0)
+	parts[1] = strings.Split(forged, ".")[1]
+	if _, err := s.Verify(strings.Join(parts, ".")); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("swapped payload: got %v", err)
+	}
+	if _, err := s.Verify("garbage"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("garbage: got %v", err)
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	s := NewSigner("secret", time.Minute)
+	start := time.Now()
+	s.now = func() time.Time { return start }
+	tok, _, _ := s.Sign(1, "a@example.com", 0)
+	s.now = func() time.Time { return start.Add(2 * time.Minute) }
+	if _, err := s.Verify(tok); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+}
+
+func TestRefreshTokenHash(t *testing.T) {
+	raw, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw == hash || HashRefreshToken(raw) != hash {
+		t.Fatal("hash must be derived from, and differ from, the raw token")
+	}
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"todo-app/backend/internal/service"
+)
+
+type ArchiveUsecase interface {
+	// RunArchive archives every user's long-completed tasks every interval
+	// until ctx is done.
+	RunArchive(ctx context.Context, every time.Duration)
+}
+
+type archiveUsecase struct {
+	svc *service.ArchiveService
+}
+
+func NewArchiveUsecase(s *service.ArchiveService) ArchiveUsecase {
+	return &archiveUsecase{svc: s}
+}
+
+func (u *archiveUsecase) RunArchive(ctx context.Context, every time.Duration) {
+	u.svc.RunArchive(ctx, every)
+}
@@ -7,7 +7,10 @@ import (
 
 type AuthUsecase interface {
 	Register(email, password string) (models.UserPublic, error)
-	Login(email, password string) (models.UserPublic, error)
+	Login(email, password string) (models.Session, error)
+	Refresh(refreshToken string) (models.Session, error)
+	Logout(refreshToken string) error
+	Verify(accessToken string) (models.UserPublic, error)
 	GetProfile(userID int64) (models.UserPublic, error)
 }
 
@@ -23,10 +26,22 @@ func (u *authUsecase) Register(email, password string) (models.UserPublic, error
 	return u.svc.Register(email, password)
 }
 
-func (u *authUsecase) Login(email, password string) (models.UserPublic, error) {
+func (u *authUsecase) Login(email, password string) (models.Session, error) {
 	return u.svc.Login(email, password)
 }
 
+func (u *authUsecase) Refresh(refreshToken string) (models.Session, error) {
+	return u.svc.Refresh(refreshToken)
+}
+
+func (u *authUsecase) Logout(refreshToken string) error {
+	return u.svc.Logout(refreshToken)
+}
+
+func (u *authUsecase) Verify(accessToken string) (models.UserPublic, error) {
+	return u.svc.Verify(accessToken)
+}
+
 func (u *authUsecase) GetProfile(userID int64) (models.UserPublic, error) {
 	return u.svc.GetProfile(userID)
 }
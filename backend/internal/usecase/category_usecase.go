@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+type CategoryUsecase interface {
+	List(userID int64) ([]models.Category, error)
+	Create(userID int64, c models.Category) (models.Category, error)
+	Update(userID int64, c models.Category) (models.Category, error)
+	Rename(userID, id int64, name string, version int64) (models.Category, error)
+	Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error)
+	Delete(userID, id int64, tasks string, moveTo *int64, version int64) error
+}
+
+type categoryUsecase struct {
+	svc *service.CategoryService
+}
+
+func NewCategoryUsecase(s *service.CategoryService) CategoryUsecase {
+	return &categoryUsecase{svc: s}
+}
+
+func (u *categoryUsecase) List(userID int64) ([]models.Category, error) {
+	return u.svc.List(userID)
+}
+
+func (u *categoryUsecase) Create(userID int64, c models.Category) (models.Category, error) {
+	return u.svc.Create(userID, c)
+}
+
+func (u *categoryUsecase) Update(userID int64, c models.Category) (models.Category, error) {
+	return u.svc.Update(userID, c)
+}
+
+func (u *categoryUsecase) Rename(userID, id int64, name string, version int64) (models.Category, error) {
+	return u.svc.Rename(userID, id, name, version)
+}
+
+func (u *categoryUsecase) Move(userID, id int64, parentID *int64, position int, version int64) (models.Category, error) {
+	return u.svc.Move(userID, id, parentID, position, version)
+}
+
+func (u *categoryUsecase) Delete(userID, id int64, tasks string, moveTo *int64, version int64) error {
+	return u.svc.Delete(userID, id, tasks, moveTo, version)
+}
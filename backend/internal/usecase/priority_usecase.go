@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+type PriorityUsecase interface {
+	List(userID int64) ([]models.Priority, error)
+	Create(userID int64, p models.Priority) (models.Priority, error)
+	Update(userID int64, p models.Priority) (models.Priority, error)
+	Delete(userID, id, moveTo, version int64) error
+}
+
+type priorityUsecase struct {
+	svc *service.PriorityService
+}
+
+func NewPriorityUsecase(s *service.PriorityService) PriorityUsecase {
+	return &priorityUsecase{svc: s}
+}
+
+func (u *priorityUsecase) List(userID int64) ([]models.Priority, error) {
+	return u.svc.List(userID)
+}
+
+func (u *priorityUsecase) Create(userID int64, p models.Priority) (models.Priority, error) {
+	return u.svc.Create(userID, p)
+}
+
+func (u *priorityUsecase) Update(userID int64, p models.Priority) (models.Priority, error) {
+	return u.svc.Update(userID, p)
+}
+
+func (u *priorityUsecase) Delete(userID, id, moveTo, version int64) error {
+	return u.svc.Delete(userID, id, moveTo, version)
+}
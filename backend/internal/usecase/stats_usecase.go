@@ -2,14 +2,18 @@ package usecase
 
 import "todo-app/backend/internal/service"
 
-type StatsUsecase struct {
+type StatsUsecase interface {
+	Snapshot(userID int64) (service.Stats, error)
+}
+
+type statsUsecase struct {
 	svc *service.StatsService
 }
 
-func NewStatsUsecase(s *service.StatsService) *StatsUsecase {
-	return &StatsUsecase{svc: s}
+func NewStatsUsecase(s *service.StatsService) StatsUsecase {
+	return &statsUsecase{svc: s}
 }
 
-func (u *StatsUsecase) Snapshot() (service.Stats, error) {
-	return u.svc.Snapshot()
+func (u *statsUsecase) Snapshot(userID int64) (service.Stats, error) {
+	return u.svc.Snapshot(userID)
 }
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+type TagUsecase interface {
+	List(userID int64) ([]string, error)
+	Tags(userID int64) ([]models.Tag, error)
+	Suggest(userID int64, prefix string, limit int) ([]models.Tag, error)
+	Create(userID int64, name, color string) (models.Tag, error)
+	Update(userID, id int64, name, color string, version int64) (models.Tag, error)
+	Merge(userID, from, into, version int64) (models.Tag, error)
+	Delete(userID, id, version int64) error
+}
+
+type tagUsecase struct {
+	svc *service.TagService
+}
+
+func NewTagUsecase(s *service.TagService) TagUsecase {
+	return &tagUsecase{svc: s}
+}
+
+func (u *tagUsecase) List(userID int64) ([]string, error) {
+	return u.svc.List(userID)
+}
+
+func (u *tagUsecase) Tags(userID int64) ([]models.Tag, error) {
+	return u.svc.Tags(userID)
+}
+
+func (u *tagUsecase) Suggest(userID int64, prefix string, limit int) ([]models.Tag, error) {
+	return u.svc.Suggest(userID, prefix, limit)
+}
+
+func (u *tagUsecase) Create(userID int64, name, color string) (models.Tag, error) {
+	return u.svc.Create(userID, name, color)
+}
+
+func (u *tagUsecase) Update(userID, id int64, name, color string, version int64) (models.Tag, error) {
+	return u.svc.Update(userID, id, name, color, version)
+}
+
+func (u *tagUsecase) Merge(userID, from, into, version int64) (models.Tag, error) {
+	return u.svc.Merge(userID, from, into, version)
+}
+
+func (u *tagUsecase) Delete(userID, id, version int64) error {
+	return u.svc.Delete(userID, id, version)
+}
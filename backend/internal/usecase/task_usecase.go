@@ -2,53 +2,290 @@ package usecase
 
 import (
 	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/repository"
 	"todo-app/backend/internal/service"
 )
 
 type TaskUsecase interface {
-	GetTasks(userID int64, filter string) ([]models.Task, error)
-	AddTask(userID int64, title, desc, priority string) (*models.Task, error)
+	GetTasks(userID int64, filter, sort string) ([]models.Task, error)
+	ListTasks(userID int64, filter, cursor string, limit int) (*models.TaskPage, error)
+	SearchTasks(userID int64, query string) ([]models.Task, error)
+	Search(userID int64, query string) ([]models.SearchResult, error)
+	QueryTasks(userID int64, f models.TaskFilter) ([]models.Task, error)
+	GetTask(userID, id int64) (*models.Task, error)
+	AddTask(userID int64, in models.CreateTaskInput) (*models.Task, error)
 	UpdateTask(userID int64, task models.Task) (*models.Task, error)
-	ToggleTask(userID, id int64) (*models.Task, error)
-	DeleteTask(userID, id int64) error
+	PatchTask(userID int64, in models.UpdateTaskInput) (*models.Task, error)
+	ToggleTask(userID, id, version int64) (*models.Task, error)
+	MoveTask(userID, id, beforeID, afterID int64) (*models.Task, error)
+	DeleteTask(userID, id, version int64) error
 	ClearCompleted(userID int64) (int64, error)
+	Unarchive(userID, id, version int64) (*models.Task, error)
+	// SetTags, SetDescription and AssignCategory fail with a ConflictError
+	// unless version is 0 or the task's stored version.
+	SetTags(userID, id int64, tags []string, version int64) (*models.Task, error)
+	SetDescription(userID, id int64, description string, version int64) (*models.Task, error)
+	ConvertChecklist(userID, id, version int64) (*models.Task, error)
+	AssignCategory(userID, id int64, categoryID *int64, version int64) (*models.Task, error)
+	SetRepeatRule(userID, id int64, rule string, version int64) (*models.Task, error)
+	ClearRepeatRule(userID, id, version int64) (*models.Task, error)
+	UpdateOccurrence(userID int64, task models.Task, scope string) (*models.Task, error)
+	SkipOccurrence(userID, id, version int64) (*models.Task, error)
+	GetSeriesHistory(userID, seriesID int64) ([]models.SeriesOccurrence, error)
+	BulkComplete(userID int64, ids []int64) (int64, error)
+	BulkDelete(userID int64, ids []int64) (int64, error)
+	GetSubtasks(userID, taskID int64) ([]models.Subtask, error)
+	AddSubtask(userID, taskID int64, title string) (*models.Subtask, error)
+	UpdateSubtask(userID int64, st models.Subtask) (*models.Subtask, error)
+	ToggleSubtask(userID, id, version int64) (*models.Subtask, error)
+	DeleteSubtask(userID, id, version int64) error
+	Undo(userID int64) (string, error)
+	Redo(userID int64) (string, error)
+	HistoryLabels(userID int64) (undo, redo string)
+	ClearHistory(userID int64)
+	// Session returns the usecase as seen by one sign-in of a user: commands
+	// are recorded in, and Undo and Redo act on, that session's history.
+	Session(id int64) TaskUsecase
 }
 
 type taskUsecase struct {
 	service service.TaskService
+	history *service.History
+	session int64
 }
 
-func NewTaskUsecase(s service.TaskService) TaskUsecase {
-	return &taskUsecase{service: s}
+// NewTaskUsecase records task changes in h for undo and redo; h must be built
+// over the repositories s writes through. With a nil h nothing is recorded.
+func NewTaskUsecase(s service.TaskService, h *service.History) TaskUsecase {
+	return &taskUsecase{service: s, history: h}
 }
 
-func (u *taskUsecase) GetTasks(userID int64, filter string) ([]models.Task, error) {
-	return u.service.GetTasks(userID, filter)
+func (u *taskUsecase) Session(id int64) TaskUsecase {
+	c := *u
+	c.session = id
+	return &c
 }
 
-func (u *taskUsecase) AddTask(userID int64, title, desc, priority string) (*models.Task, error) {
+// record runs fn as one command of the session's history, with a copy of
+// the service whose writes the command captures.
+func (u *taskUsecase) record(userID int64, label string, fn func(s service.TaskService) error) error {
+	if u.history == nil {
+		return fn(u.service)
+	}
+	return u.history.Record(userID, u.session, label, func(tasks repository.TaskRepository, subtasks repository.SubtaskRepository) error {
+		return fn(u.service.With(tasks, subtasks))
+	})
+}
+
+func (u *taskUsecase) recordTask(userID int64, label string, fn func(s service.TaskService) (*models.Task, error)) (t *models.Task, err error) {
+	err = u.record(userID, label, func(s service.TaskService) error {
+		t, err = fn(s)
+		return err
+	})
+	return t, err
+}
+
+func (u *taskUsecase) recordSubtask(userID int64, label string, fn func(s service.TaskService) (*models.Subtask, error)) (st *models.Subtask, err error) {
+	err = u.record(userID, label, func(s service.TaskService) error {
+		st, err = fn(s)
+		return err
+	})
+	return st, err
+}
+
+func (u *taskUsecase) recordCount(userID int64, label string, fn func(s service.TaskService) (int64, error)) (n int64, err error) {
+	err = u.record(userID, label, func(s service.TaskService) error {
+		n, err = fn(s)
+		return err
+	})
+	return n, err
+}
+
+func (u *taskUsecase) GetTasks(userID int64, filter, sort string) ([]models.Task, error) {
+	return u.service.GetTasks(userID, filter, sort)
+}
+
+func (u *taskUsecase) ListTasks(userID int64, filter, cursor string, limit int) (*models.TaskPage, error) {
+	return u.service.ListTasks(userID, filter, cursor, limit)
+}
+
+func (u *taskUsecase) SearchTasks(userID int64, query string) ([]models.Task, error) {
+	return u.service.SearchTasks(userID, query)
+}
+
+func (u *taskUsecase) Search(userID int64, query string) ([]models.SearchResult, error) {
+	return u.service.Search(userID, query)
+}
+
+func (u *taskUsecase) QueryTasks(userID int64, f models.TaskFilter) ([]models.Task, error) {
+	f.UserID = userID
+	return u.service.QueryTasks(f)
+}
+
+func (u *taskUsecase) GetTask(userID, id int64) (*models.Task, error) {
+	return u.service.GetTask(userID, id)
+}
+
+func (u *taskUsecase) AddTask(userID int64, in models.CreateTaskInput) (*models.Task, error) {
 	t := &models.Task{
 		UserID:      userID,
-		Title:       title,
-		Description: desc,
-		Priority:    priority,
+		Title:       in.Title,
+		Description: in.Description,
+		Priority:    in.Priority,
+		DueDate:     in.DueDate,
+		RepeatRule:  in.RepeatRule,
+		CategoryID:  in.CategoryID,
+		Tags:        in.Tags,
 	}
-	return u.service.AddTask(t)
+	return u.recordTask(userID, "Add task", func(s service.TaskService) (*models.Task, error) { return s.AddTask(t) })
 }
 
 func (u *taskUsecase) UpdateTask(userID int64, task models.Task) (*models.Task, error) {
 	task.UserID = userID
-	return u.service.UpdateTask(&task)
+	return u.recordTask(userID, "Edit task", func(s service.TaskService) (*models.Task, error) { return s.UpdateTask(&task) })
+}
+
+func (u *taskUsecase) PatchTask(userID int64, in models.UpdateTaskInput) (*models.Task, error) {
+	in.UserID = userID
+	return u.recordTask(userID, "Edit task", func(s service.TaskService) (*models.Task, error) { return s.PatchTask(in) })
 }
 
-func (u *taskUsecase) ToggleTask(userID, id int64) (*models.Task, error) {
-	return u.service.ToggleTask(userID, id)
+func (u *taskUsecase) ToggleTask(userID, id, version int64) (*models.Task, error) {
+	return u.recordTask(userID, "Toggle task", func(s service.TaskService) (*models.Task, error) { return s.ToggleTask(userID, id, version) })
 }
 
-func (u *taskUsecase) DeleteTask(userID, id int64) error {
-	return u.service.DeleteTask(userID, id)
+// MoveTask is not recorded: undo restores a task's content but leaves its
+// position, which may have been respread since.
+func (u *taskUsecase) MoveTask(userID, id, beforeID, afterID int64) (*models.Task, error) {
+	return u.service.MoveTask(userID, id, beforeID, afterID)
+}
+
+func (u *taskUsecase) DeleteTask(userID, id, version int64) error {
+	return u.record(userID, "Delete task", func(s service.TaskService) error { return s.DeleteTask(userID, id, version) })
 }
 
 func (u *taskUsecase) ClearCompleted(userID int64) (int64, error) {
-	return u.service.ClearCompleted(userID)
+	return u.recordCount(userID, "Clear completed", func(s service.TaskService) (int64, error) { return s.ClearCompleted(userID) })
+}
+
+func (u *taskUsecase) Unarchive(userID, id, version int64) (*models.Task, error) {
+	return u.recordTask(userID, "Unarchive task", func(s service.TaskService) (*models.Task, error) { return s.Unarchive(userID, id, version) })
+}
+
+func (u *taskUsecase) SetTags(userID, id int64, tags []string, version int64) (*models.Task, error) {
+	return u.recordTask(userID, "Set tags", func(s service.TaskService) (*models.Task, error) {
+		t, err := s.GetTask(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		t.Tags, t.Version = tags, version
+		return s.UpdateTask(t)
+	})
+}
+
+func (u *taskUsecase) SetDescription(userID, id int64, description string, version int64) (*models.Task, error) {
+	return u.recordTask(userID, "Edit description", func(s service.TaskService) (*models.Task, error) {
+		t, err := s.GetTask(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		t.Description, t.Version = description, version
+		return s.UpdateTask(t)
+	})
+}
+
+func (u *taskUsecase) ConvertChecklist(userID, id, version int64) (*models.Task, error) {
+	return u.recordTask(userID, "Convert checklist", func(s service.TaskService) (*models.Task, error) { return s.ConvertChecklist(userID, id, version) })
+}
+
+func (u *taskUsecase) AssignCategory(userID, id int64, categoryID *int64, version int64) (*models.Task, error) {
+	return u.recordTask(userID, "Set category", func(s service.TaskService) (*models.Task, error) {
+		t, err := s.GetTask(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		t.CategoryID, t.Version = categoryID, version
+		return s.UpdateTask(t)
+	})
+}
+
+// SetRepeatRule and ClearRepeatRule are not recorded: they also start, change
+// or end the task's series, which the history does not restore.
+func (u *taskUsecase) SetRepeatRule(userID, id int64, rule string, version int64) (*models.Task, error) {
+	return u.service.SetRepeatRule(userID, id, rule, version)
+}
+
+func (u *taskUsecase) ClearRepeatRule(userID, id, version int64) (*models.Task, error) {
+	return u.service.ClearRepeatRule(userID, id, version)
+}
+
+func (u *taskUsecase) UpdateOccurrence(userID int64, task models.Task, scope string) (*models.Task, error) {
+	task.UserID = userID
+	return u.recordTask(userID, "Edit task", func(s service.TaskService) (*models.Task, error) { return s.UpdateOccurrence(&task, scope) })
+}
+
+// SkipOccurrence is not recorded: it also advances the series, which the
+// history does not restore.
+func (u *taskUsecase) SkipOccurrence(userID, id, version int64) (*models.Task, error) {
+	return u.service.SkipOccurrence(userID, id, version)
+}
+
+func (u *taskUsecase) GetSeriesHistory(userID, seriesID int64) ([]models.SeriesOccurrence, error) {
+	return u.service.SeriesHistory(userID, seriesID)
+}
+
+func (u *taskUsecase) BulkComplete(userID int64, ids []int64) (int64, error) {
+	return u.recordCount(userID, "Complete tasks", func(s service.TaskService) (int64, error) { return s.CompleteTasks(userID, ids) })
+}
+
+func (u *taskUsecase) BulkDelete(userID int64, ids []int64) (int64, error) {
+	return u.recordCount(userID, "Delete tasks", func(s service.TaskService) (int64, error) { return s.DeleteTasks(userID, ids) })
+}
+
+func (u *taskUsecase) GetSubtasks(userID, taskID int64) ([]models.Subtask, error) {
+	return u.service.GetSubtasks(userID, taskID)
+}
+
+func (u *taskUsecase) AddSubtask(userID, taskID int64, title string) (*models.Subtask, error) {
+	return u.recordSubtask(userID, "Add subtask", func(s service.TaskService) (*models.Subtask, error) { return s.AddSubtask(userID, taskID, title) })
+}
+
+func (u *taskUsecase) UpdateSubtask(userID int64, st models.Subtask) (*models.Subtask, error) {
+	st.UserID = userID
+	return u.recordSubtask(userID, "Edit subtask", func(s service.TaskService) (*models.Subtask, error) { return s.UpdateSubtask(&st) })
+}
+
+func (u *taskUsecase) ToggleSubtask(userID, id, version int64) (*models.Subtask, error) {
+	return u.recordSubtask(userID, "Toggle subtask", func(s service.TaskService) (*models.Subtask, error) { return s.ToggleSubtask(userID, id, version) })
+}
+
+func (u *taskUsecase) DeleteSubtask(userID, id, version int64) error {
+	return u.record(userID, "Delete subtask", func(s service.TaskService) error { return s.DeleteSubtask(userID, id, version) })
+}
+
+func (u *taskUsecase) Undo(userID int64) (string, error) {
+	if u.history == nil {
+		return "", service.ErrNothingToUndo
+	}
+	return u.history.Undo(userID, u.session)
+}
+
+func (u *taskUsecase) Redo(userID int64) (string, error) {
+	if u.history == nil {
+		return "", service.ErrNothingToRedo
+	}
+	return u.history.Redo(userID, u.session)
+}
+
+func (u *taskUsecase) HistoryLabels(userID int64) (undo, redo string) {
+	if u.history == nil {
+		return "", ""
+	}
+	return u.history.Labels(userID, u.session)
+}
+
+func (u *taskUsecase) ClearHistory(userID int64) {
+	if u.history != nil {
+		u.history.Clear(userID, u.session)
+	}
 }
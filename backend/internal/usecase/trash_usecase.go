@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+type TrashUsecase interface {
+	List(userID int64) (models.Trash, error)
+	RestoreTask(userID, id int64) (*models.Task, error)
+	RestoreSubtask(userID, id int64) (*models.Subtask, error)
+	RestoreCategory(userID, id int64) (models.Category, error)
+	Purge(userID int64) (int64, error)
+	// RunPurge empties expired items out of every user's trash every
+	// interval until ctx is done.
+	RunPurge(ctx context.Context, every time.Duration)
+}
+
+type trashUsecase struct {
+	svc *service.TrashService
+}
+
+func NewTrashUsecase(s *service.TrashService) TrashUsecase {
+	return &trashUsecase{svc: s}
+}
+
+func (u *trashUsecase) List(userID int64) (models.Trash, error) {
+	return u.svc.List(userID)
+}
+
+func (u *trashUsecase) RestoreTask(userID, id int64) (*models.Task, error) {
+	return u.svc.RestoreTask(userID, id)
+}
+
+func (u *trashUsecase) RestoreSubtask(userID, id int64) (*models.Subtask, error) {
+	return u.svc.RestoreSubtask(userID, id)
+}
+
+func (u *trashUsecase) RestoreCategory(userID, id int64) (models.Category, error) {
+	return u.svc.RestoreCategory(userID, id)
+}
+
+func (u *trashUsecase) Purge(userID int64) (int64, error) {
+	return u.svc.Purge(userID)
+}
+
+func (u *trashUsecase) RunPurge(ctx context.Context, every time.Duration) {
+	u.svc.RunPurge(ctx, every)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+)
+
+type ViewUsecase interface {
+	List(userID int64) ([]models.SavedView, error)
+	Create(userID int64, name string, f models.TaskFilter) (*models.SavedView, error)
+	Update(userID, id int64, name string, f models.TaskFilter) (*models.SavedView, error)
+	Rename(userID, id int64, name string) (*models.SavedView, error)
+	Reorder(userID int64, ids []int64) error
+	Delete(userID, id int64) error
+	Tasks(userID, id int64) ([]models.Task, error)
+}
+
+type viewUsecase struct {
+	svc *service.ViewService
+}
+
+func NewViewUsecase(s *service.ViewService) ViewUsecase {
+	return &viewUsecase{svc: s}
+}
+
+func (u *viewUsecase) List(userID int64) ([]models.SavedView, error) {
+	return u.svc.List(userID)
+}
+
+func (u *viewUsecase) Create(userID int64, name string, f models.TaskFilter) (*models.SavedView, error) {
+	return u.svc.Create(userID, name, f)
+}
+
+func (u *viewUsecase) Update(userID, id int64, name string, f models.TaskFilter) (*models.SavedView, error) {
+	return u.svc.Update(userID, id, name, f)
+}
+
+func (u *viewUsecase) Rename(userID, id int64, name string) (*models.SavedView, error) {
+	return u.svc.Rename(userID, id, name)
+}
+
+func (u *viewUsecase) Reorder(userID int64, ids []int64) error {
+	return u.svc.Reorder(userID, ids)
+}
+
+func (u *viewUsecase) Delete(userID, id int64) error {
+	return u.svc.Delete(userID, id)
+}
+
+func (u *viewUsecase) Tasks(userID, id int64) ([]models.Task, error) {
+	return u.svc.Tasks(userID, id)
+}
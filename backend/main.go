@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"embed"
 	"log"
-	"os"
+	"time"
+
+	"todo-app/backend/internal/api"
+	"todo-app/backend/internal/bootstrap"
+	"todo-app/backend/internal/config"
 
-	"github.com/lib/pq"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -16,28 +18,23 @@ import (
 //go:embed frontend/dist/*
 var assets embed.FS
 
-func mustDB() *sql.DB {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
-		dsn = "host=127.0.0.1 port=5432 user=postgres password=postgres dbname=todoapp sslmode=disable"
-	}
-	db, err := sql.Open("postgres", dsn)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if err := db.Ping(); err != nil {
+func main() {
+	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
 		log.Fatal(err)
 	}
-	if err := ensureSchema(db); err != nil {
+	db, err := bootstrap.OpenDB(cfg)
+	if err != nil {
 		log.Fatal(err)
 	}
-	return db
-}
-
-func main() {
-	_ = pq.Array
-	db := mustDB()
-	app := NewApp(db)
+	uc := bootstrap.NewUsecases(db, cfg)
+	app := NewApp(uc.Auth, uc.Tasks, uc.Categories, uc.Priorities, uc.Tags, uc.Stats, uc.Views, uc.Trash)
+	// The API gets usecases of its own so that its clients and the window
+	// cannot undo each other's changes.
+	apiServer := startAPI(cfg.HTTPAddr, api.Usecases(bootstrap.NewUsecases(db, cfg)))
+	jobs, stopJobs := context.WithCancel(context.Background())
+	go uc.Trash.RunPurge(jobs, time.Hour)
+	go uc.Archive.RunArchive(jobs, time.Hour)
 
 	appOptions := &options.App{
 		Title:  "Todo App",
@@ -46,6 +43,10 @@ func main() {
 		OnStartup: func(ctx context.Context) {
 			app.startup(ctx)
 		},
+		OnShutdown: func(ctx context.Context) {
+			stopJobs()
+			stopAPI(apiServer)
+		},
 		AssetServer: &assetserver.Options{
 			Assets:  assets,
 			Handler: nil,
@@ -59,7 +60,7 @@ func main() {
 		WindowStartState: options.Normal,
 	}
 
-	err := wails.Run(appOptions)
+	err = wails.Run(appOptions)
 	if err != nil {
 		log.Fatal(err)
 	}
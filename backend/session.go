@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"sync"
+
+	"todo-app/backend/internal/models"
+	"todo-app/backend/internal/service"
+	"todo-app/backend/internal/usecase"
+)
+
+var ErrNotAuthenticated = errors.New("not logged in")
+
+// session holds the token pair of the user logged into this window. Access
+// tokens are verified on every binding call and refreshed once they expire.
+type session struct {
+	mu   sync.Mutex
+	auth usecase.AuthUsecase
+	cur  *models.Session
+}
+
+func (s *session) set(sess models.Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur = &sess
+}
+
+// clear forgets the session and returns its refresh token for revocation.
+func (s *session) clear() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur == nil {
+		return ""
+	}
+	rt := s.cur.RefreshToken
+	s.cur = nil
+	return rt
+}
+
+func (s *session) user() *models.UserPublic {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur == nil {
+		return nil
+	}
+	u := s.cur.User
+	return &u
+}
+
+func (s *session) userID() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur == nil {
+		return 0, ErrNotAuthenticated
+	}
+	u, err := s.auth.Verify(s.cur.AccessToken)
+	if err == nil {
+		return u.ID, nil
+	}
+	if !errors.Is(err, service.ErrTokenExpired) {
+		s.cur = nil
+		return 0, err
+	}
+	next, err := s.auth.Refresh(s.cur.RefreshToken)
+	if err != nil {
+		s.cur = nil
+		return 0, err
+	}
+	s.cur = &next
+	return next.User.ID, nil
+}
@@ -2,11 +2,13 @@ import React, { useEffect, useMemo, useState } from "react";
 import "./index.css";
 import ThemeToggle from "./components/ThemeToggle";
 import Select from "./components/Select";
-import { GetTasks, AddTask, ToggleTask, DeleteTask, ClearCompleted, GetStats } from "./wailsjs/go/main/App";
+import AuthScreen from "./components/AuthScreen";
+import { GetTasks, AddTask, ToggleTask, DeleteTask, ClearCompleted, GetStats, CurrentUser, Logout } from "./wailsjs/go/main/App";
 import type { main as NS } from "./wailsjs/go/models";
 
 type TaskDTO = NS.TaskDTO;
 type StatsDTO = NS.StatsDTO;
+type UserDTO = NS.UserDTO;
 type Priority = "high" | "medium" | "low";
 type Filter = "all" | "active" | "completed" | "overdue" | "today" | "week";
 type SortOrder = "date" | "priority";
@@ -29,7 +31,37 @@ function saveMeta(m: MetaMap) {
   localStorage.setItem(META_KEY, JSON.stringify(m));
 }
 
-export default function App(): JSX.Element {
+// App shows the sign-in screen until the window has a session.
+export default function App(): JSX.Element | null {
+  const [user, setUser] = useState<UserDTO | null>(null);
+  const [checked, setChecked] = useState(false);
+
+  useEffect(() => {
+    CurrentUser()
+      .then(u => setUser(u ?? null))
+      .catch(() => setUser(null))
+      .finally(() => setChecked(true));
+  }, []);
+
+  async function onLogout() {
+    try {
+      await Logout();
+    } finally {
+      setUser(null);
+    }
+  }
+
+  if (!checked) return null;
+  if (!user) return <AuthScreen onSignedIn={setUser} />;
+  return <TaskManager key={user.id} user={user} onLogout={onLogout} />;
+}
+
+interface TaskManagerProps {
+  user: UserDTO;
+  onLogout: () => void;
+}
+
+function TaskManager({ user, onLogout }: TaskManagerProps): JSX.Element {
   const [tasks, setTasks] = useState<TaskDTO[]>([]);
   const [stats, setStats] = useState<StatsDTO | null>(null);
   const [title, setTitle] = useState("");
@@ -123,7 +155,7 @@ export default function App(): JSX.Element {
 
   async function onToggle(id: number) {
     const before = tasks.find(x => x.id === id);
-    const updated = await ToggleTask(id);
+    const updated = await ToggleTask(id, before?.version ?? 0);
     setTasks(prev => prev.map(t => (t.id === updated.id ? updated : t)));
     setStats(await GetStats());
     if (before && before.completed !== updated.completed) {
@@ -136,7 +168,7 @@ export default function App(): JSX.Element {
   }
 
   async function onDelete(id: number) {
-    await DeleteTask(id);
+    await DeleteTask(id, tasks.find(x => x.id === id)?.version ?? 0);
     setMeta(prev => {
       const n = { ...prev };
       delete n[id];
@@ -180,9 +212,9 @@ export default function App(): JSX.Element {
 
   async function completeSelected() {
     const ids = Array.from(selected);
-    const need = tasks.filter(t => ids.includes(t.id) && !t.completed).map(t => t.id);
-    for (const id of need) {
-      await ToggleTask(id);
+    const need = tasks.filter(t => ids.includes(t.id) && !t.completed);
+    for (const t of need) {
+      await ToggleTask(t.id, t.version);
     }
     await refresh(filter);
     setSelected(new Set());
@@ -191,7 +223,7 @@ export default function App(): JSX.Element {
   async function deleteSelected() {
     const ids = Array.from(selected);
     for (const id of ids) {
-      await DeleteTask(id);
+      await DeleteTask(id, tasks.find(x => x.id === id)?.version ?? 0);
     }
     const m: MetaMap = { ...meta };
     ids.forEach(id => delete m[id]);
@@ -240,6 +272,8 @@ export default function App(): JSX.Element {
             <input className="search-input" placeholder="Search by title..." value={search} onChange={e => setSearch(e.target.value)} />
             <button className="btn btn-clear" onClick={onClearCompleted}>Clear Completed</button>
             <ThemeToggle />
+            <span className="user-email">{user.email}</span>
+            <button className="btn btn-clear" onClick={onLogout}>Sign Out</button>
           </div>
         </header>
 
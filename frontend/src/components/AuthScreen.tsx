@@ -0,0 +1,79 @@
+import React, { useState } from "react";
+import ThemeToggle from "./ThemeToggle";
+import { Login, Register } from "../wailsjs/go/main/App";
+import type { main as NS } from "../wailsjs/go/models";
+
+type Mode = "login" | "register";
+
+interface AuthScreenProps {
+  onSignedIn: (user: NS.UserDTO) => void;
+}
+
+// AuthScreen signs the window in; every other binding needs a session.
+export default function AuthScreen({ onSignedIn }: AuthScreenProps): JSX.Element {
+  const [mode, setMode] = useState<Mode>("login");
+  const [email, setEmail] = useState("");
+  const [password, setPassword] = useState("");
+  const [error, setError] = useState("");
+  const [busy, setBusy] = useState(false);
+
+  async function onSubmit(e: React.FormEvent) {
+    e.preventDefault();
+    if (!email.trim() || !password) return;
+    setBusy(true);
+    setError("");
+    try {
+      const user = mode === "login" ? await Login(email.trim(), password) : await Register(email.trim(), password);
+      onSignedIn(user);
+    } catch (err) {
+      setError(String(err));
+    } finally {
+      setBusy(false);
+    }
+  }
+
+  function switchMode() {
+    setMode(mode === "login" ? "register" : "login");
+    setError("");
+  }
+
+  return (
+    <div className="app">
+      <div className="container">
+        <header className="app-header">
+          <h1 className="app-title">Task Manager</h1>
+          <div className="header-controls">
+            <ThemeToggle />
+          </div>
+        </header>
+
+        <form className="card auth-card" onSubmit={onSubmit}>
+          <h2 className="auth-title">{mode === "login" ? "Sign in" : "Create an account"}</h2>
+          <input
+            type="email"
+            className="task-input"
+            placeholder="Email"
+            autoComplete="email"
+            value={email}
+            onChange={e => setEmail(e.target.value)}
+          />
+          <input
+            type="password"
+            className="task-input"
+            placeholder="Password"
+            autoComplete={mode === "login" ? "current-password" : "new-password"}
+            value={password}
+            onChange={e => setPassword(e.target.value)}
+          />
+          {error && <div className="auth-error">{error}</div>}
+          <button type="submit" className="btn btn-primary" disabled={busy || !email.trim() || !password}>
+            {mode === "login" ? "Sign In" : "Register"}
+          </button>
+          <button type="button" className="btn btn-clear" onClick={switchMode}>
+            {mode === "login" ? "No account? Register" : "Have an account? Sign in"}
+          </button>
+        </form>
+      </div>
+    </div>
+  );
+}
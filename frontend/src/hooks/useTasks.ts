@@ -66,7 +66,8 @@ export const useTasks = () => {
     setLoading(true);
     setError(null);
     try {
-      const updatedTask = await App.ToggleTask(id) as TaskDTO;
+      const version = tasks.find(t => t.id === id)?.version ?? 0;
+      const updatedTask = await App.ToggleTask(id, version) as TaskDTO;
       await fetchTasks();
     } catch (err) {
       setError({
@@ -78,13 +79,14 @@ export const useTasks = () => {
     } finally {
       setLoading(false);
     }
-  }, [fetchTasks]);
+  }, [fetchTasks, tasks]);
 
   const removeTask = useCallback(async (id: number) => {
     setLoading(true);
     setError(null);
     try {
-      await App.DeleteTask(id);
+      const version = tasks.find(t => t.id === id)?.version ?? 0;
+      await App.DeleteTask(id, version);
       await fetchTasks();
     } catch (err) {
       setError({
@@ -96,7 +98,7 @@ export const useTasks = () => {
     } finally {
       setLoading(false);
     }
-  }, [fetchTasks]);
+  }, [fetchTasks, tasks]);
 
   const clearCompletedTasks = useCallback(async () => {
     setLoading(true);
@@ -131,7 +133,8 @@ export const useTasks = () => {
         id,
         updates.title || '',
         updates.priority || 'medium',
-        dueDate
+        dueDate,
+        tasks.find(t => t.id === id)?.version ?? 0
       ) as TaskDTO;
       await fetchTasks();
     } catch (err) {
@@ -144,7 +147,7 @@ export const useTasks = () => {
     } finally {
       setLoading(false);
     }
-  }, [fetchTasks]);
+  }, [fetchTasks, tasks]);
 
   useEffect(() => {
     fetchTasks();
@@ -12,6 +12,7 @@ declare namespace wails {
         createdAt: string;
         completedAt: string | null;
         dueDate: string | null;
+        version: number;
       }
 
       interface StatsDTO {
@@ -23,10 +24,10 @@ declare namespace wails {
       interface App {
         GetTasks(filter: 'all' | 'active' | 'completed'): Promise<TaskDTO[]>;
         AddTask(title: string, priority: 'low' | 'medium' | 'high', dueISO: string): Promise<TaskDTO>;
-        ToggleTask(id: number): Promise<TaskDTO>;
-        DeleteTask(id: number): Promise<void>;
+        ToggleTask(id: number, version: number): Promise<TaskDTO>;
+        DeleteTask(id: number, version: number): Promise<void>;
         ClearCompleted(): Promise<number>;
-        UpdateTask(id: number, title: string, priority: 'low' | 'medium' | 'high', dueISO: string): Promise<TaskDTO>;
+        UpdateTask(id: number, title: string, priority: 'low' | 'medium' | 'high', dueISO: string, version: number): Promise<TaskDTO>;
         GetStats(): Promise<StatsDTO>;
       }
     }
@@ -10,6 +10,7 @@ export interface TaskDTO {
   createdAt: string;
   completedAt: string | null;
   dueDate: string | Date | null;
+  version: number;
   [key: string]: unknown; // Allow additional properties
 }
 
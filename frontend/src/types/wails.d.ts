@@ -10,6 +10,7 @@ interface TaskDTO {
   createdAt: string;
   completedAt: string | null;
   dueDate: string | null;
+  version: number;
 }
 
 interface StatsDTO {
@@ -22,10 +23,10 @@ declare module "wailsjs/go/main/App" {
   export interface App {
     GetTasks(filter: 'all' | 'active' | 'completed'): Promise<TaskDTO[]>;
     AddTask(title: string, priority: 'low' | 'medium' | 'high', dueISO: string): Promise<TaskDTO>;
-    ToggleTask(id: number): Promise<TaskDTO>;
-    DeleteTask(id: number): Promise<void>;
+    ToggleTask(id: number, version: number): Promise<TaskDTO>;
+    DeleteTask(id: number, version: number): Promise<void>;
     ClearCompleted(): Promise<number>;
-    UpdateTask(id: number, title: string, priority: 'low' | 'medium' | 'high', dueISO: string): Promise<TaskDTO>;
+    UpdateTask(id: number, title: string, priority: 'low' | 'medium' | 'high', dueISO: string, version: number): Promise<TaskDTO>;
     GetStats(): Promise<StatsDTO>;
   }
 }
@@ -38,10 +39,10 @@ declare global {
         interface App {
           GetTasks(filter: 'all' | 'active' | 'completed'): Promise<TaskDTO[]>;
           AddTask(title: string, priority: 'low' | 'medium' | 'high', dueISO: string): Promise<TaskDTO>;
-          ToggleTask(id: number): Promise<TaskDTO>;
-          DeleteTask(id: number): Promise<void>;
+          ToggleTask(id: number, version: number): Promise<TaskDTO>;
+          DeleteTask(id: number, version: number): Promise<void>;
           ClearCompleted(): Promise<number>;
-          UpdateTask(id: number, title: string, priority: 'low' | 'medium' | 'high', dueISO: string): Promise<TaskDTO>;
+          UpdateTask(id: number, title: string, priority: 'low' | 'medium' | 'high', dueISO: string, version: number): Promise<TaskDTO>;
           GetStats(): Promise<StatsDTO>;
         }
       }
@@ -8,36 +8,136 @@ export function AddSubtask(arg1:number,arg2:string):Promise<main.SubtaskDTO>;
 
 export function AddTask(arg1:string,arg2:string,arg3:string):Promise<main.TaskDTO>;
 
-export function AssignCategory(arg1:number,arg2:number):Promise<main.TaskDTO>;
+export function AddTaskWithDescription(arg1:string,arg2:string,arg3:string,arg4:string):Promise<main.TaskDTO>;
+
+export function AssignCategory(arg1:number,arg2:number,arg3:number):Promise<main.TaskDTO>;
 
 export function BulkComplete(arg1:Array<number>):Promise<number>;
 
 export function BulkDelete(arg1:Array<number>):Promise<number>;
 
-export function ClearCategory(arg1:number):Promise<main.TaskDTO>;
+export function ClearCategory(arg1:number,arg2:number):Promise<main.TaskDTO>;
 
 export function ClearCompleted():Promise<number>;
 
-export function DeleteCategory(arg1:number):Promise<void>;
+export function ClearRepeatRule(arg1:number,arg2:number):Promise<main.TaskDTO>;
+
+export function ConvertChecklist(arg1:number,arg2:number):Promise<main.TaskDTO>;
+
+export function CreateCategory(arg1:number,arg2:string,arg3:string,arg4:string):Promise<main.CategoryDTO>;
+
+export function CreatePriority(arg1:string,arg2:string,arg3:string,arg4:number,arg5:boolean):Promise<main.PriorityDTO>;
+
+export function CreateTag(arg1:string,arg2:string):Promise<main.TagDTO>;
+
+export function CreateView(arg1:string,arg2:main.TaskFilterDTO):Promise<main.ViewDTO>;
+
+export function CurrentUser():Promise<main.UserDTO>;
 
-export function DeleteSubtask(arg1:number):Promise<void>;
+export function DeleteCategory(arg1:number,arg2:string,arg3:number,arg4:number):Promise<void>;
 
-export function DeleteTask(arg1:number):Promise<void>;
+export function DeletePriority(arg1:number,arg2:number,arg3:number):Promise<void>;
+
+export function DeleteSubtask(arg1:number,arg2:number):Promise<void>;
+
+export function DeleteTag(arg1:number,arg2:number):Promise<void>;
+
+export function DeleteTask(arg1:number,arg2:number):Promise<void>;
+
+export function DeleteView(arg1:number):Promise<void>;
 
 export function GetCategories():Promise<Array<main.CategoryDTO>>;
 
+export function GetHistory():Promise<main.HistoryDTO>;
+
+export function GetPriorities():Promise<Array<main.PriorityDTO>>;
+
+export function GetSeriesHistory(arg1:number):Promise<Array<main.OccurrenceDTO>>;
+
+export function GetSortedTasks(arg1:string,arg2:string):Promise<Array<main.TaskDTO>>;
+
 export function GetStats():Promise<main.StatsDTO>;
 
 export function GetSubtasks(arg1:number):Promise<Array<main.SubtaskDTO>>;
 
+export function GetTags():Promise<Array<main.TagDTO>>;
+
 export function GetTasks(arg1:string):Promise<Array<main.TaskDTO>>;
 
+export function GetTasksPage(arg1:string,arg2:string,arg3:number):Promise<main.TaskPageDTO>;
+
+export function GetTrash():Promise<main.TrashDTO>;
+
+export function GetViewTasks(arg1:number):Promise<Array<main.TaskDTO>>;
+
+export function GetViews():Promise<Array<main.ViewDTO>>;
+
+export function Login(arg1:string,arg2:string):Promise<main.UserDTO>;
+
+export function Logout():Promise<void>;
+
+export function MergeTags(arg1:number,arg2:number,arg3:number):Promise<main.TagDTO>;
+
+export function MoveCategory(arg1:number,arg2:number,arg3:number,arg4:number):Promise<main.CategoryDTO>;
+
+export function MoveTask(arg1:number,arg2:number,arg3:number):Promise<main.TaskDTO>;
+
+export function PatchTask(arg1:number,arg2:main.TaskPatchDTO):Promise<main.TaskDTO>;
+
+export function PreviewDescription(arg1:string):Promise<string>;
+
+export function PurgeTrash():Promise<number>;
+
+export function QueryTasks(arg1:main.TaskFilterDTO):Promise<Array<main.TaskDTO>>;
+
+export function Redo():Promise<string>;
+
+export function Register(arg1:string,arg2:string):Promise<main.UserDTO>;
+
+export function RenameCategory(arg1:number,arg2:string,arg3:number):Promise<main.CategoryDTO>;
+
+export function RenameView(arg1:number,arg2:string):Promise<main.ViewDTO>;
+
+export function ReorderViews(arg1:Array<number>):Promise<void>;
+
+export function RestoreCategory(arg1:number):Promise<main.CategoryDTO>;
+
+export function RestoreSubtask(arg1:number):Promise<main.SubtaskDTO>;
+
+export function RestoreTask(arg1:number):Promise<main.TaskDTO>;
+
 export function SearchTasks(arg1:string):Promise<Array<main.TaskDTO>>;
 
-export function SetTaskTags(arg1:number,arg2:Array<string>):Promise<main.TaskDTO>;
+export function SearchTasksRanked(arg1:string):Promise<Array<main.SearchResultDTO>>;
+
+export function SetRepeatRule(arg1:number,arg2:string,arg3:number):Promise<main.TaskDTO>;
+
+export function SetTaskDescription(arg1:number,arg2:string,arg3:number):Promise<main.TaskDTO>;
+
+export function SetTaskTags(arg1:number,arg2:Array<string>,arg3:number):Promise<main.TaskDTO>;
+
+export function SkipOccurrence(arg1:number,arg2:number):Promise<main.TaskDTO>;
+
+export function SuggestTags(arg1:string,arg2:number):Promise<Array<main.TagDTO>>;
+
+export function ToggleSubtask(arg1:number,arg2:number):Promise<main.SubtaskDTO>;
+
+export function ToggleTask(arg1:number,arg2:number):Promise<main.TaskDTO>;
+
+export function UnarchiveTask(arg1:number,arg2:number):Promise<main.TaskDTO>;
+
+export function Undo():Promise<string>;
+
+export function UpdateCategory(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number):Promise<main.CategoryDTO>;
+
+export function UpdateOccurrence(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string,arg6:number):Promise<main.TaskDTO>;
+
+export function UpdatePriority(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number,arg6:boolean,arg7:number):Promise<main.PriorityDTO>;
+
+export function UpdateSubtask(arg1:number,arg2:string,arg3:boolean,arg4:number):Promise<main.SubtaskDTO>;
 
-export function ToggleSubtask(arg1:number):Promise<main.SubtaskDTO>;
+export function UpdateTag(arg1:number,arg2:string,arg3:string,arg4:number):Promise<main.TagDTO>;
 
-export function ToggleTask(arg1:number):Promise<main.TaskDTO>;
+export function UpdateTask(arg1:number,arg2:string,arg3:string,arg4:string,arg5:number):Promise<main.TaskDTO>;
 
-export function UpdateTask(arg1:number,arg2:string,arg3:string,arg4:string):Promise<main.TaskDTO>;
+export function UpdateView(arg1:number,arg2:string,arg3:main.TaskFilterDTO):Promise<main.ViewDTO>;
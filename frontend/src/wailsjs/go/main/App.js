@@ -14,8 +14,12 @@ export function AddTask(arg1, arg2, arg3) {
   return window['go']['main']['App']['AddTask'](arg1, arg2, arg3);
 }
 
-export function AssignCategory(arg1, arg2) {
-  return window['go']['main']['App']['AssignCategory'](arg1, arg2);
+export function AddTaskWithDescription(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['AddTaskWithDescription'](arg1, arg2, arg3, arg4);
+}
+
+export function AssignCategory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AssignCategory'](arg1, arg2, arg3);
 }
 
 export function BulkComplete(arg1) {
@@ -26,30 +30,86 @@ export function BulkDelete(arg1) {
   return window['go']['main']['App']['BulkDelete'](arg1);
 }
 
-export function ClearCategory(arg1) {
-  return window['go']['main']['App']['ClearCategory'](arg1);
+export function ClearCategory(arg1, arg2) {
+  return window['go']['main']['App']['ClearCategory'](arg1, arg2);
 }
 
 export function ClearCompleted() {
   return window['go']['main']['App']['ClearCompleted']();
 }
 
-export function DeleteCategory(arg1) {
-  return window['go']['main']['App']['DeleteCategory'](arg1);
+export function ClearRepeatRule(arg1, arg2) {
+  return window['go']['main']['App']['ClearRepeatRule'](arg1, arg2);
+}
+
+export function ConvertChecklist(arg1, arg2) {
+  return window['go']['main']['App']['ConvertChecklist'](arg1, arg2);
 }
 
-export function DeleteSubtask(arg1) {
-  return window['go']['main']['App']['DeleteSubtask'](arg1);
+export function CreateCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['CreateCategory'](arg1, arg2, arg3, arg4);
 }
 
-export function DeleteTask(arg1) {
-  return window['go']['main']['App']['DeleteTask'](arg1);
+export function CreatePriority(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['CreatePriority'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function CreateTag(arg1, arg2) {
+  return window['go']['main']['App']['CreateTag'](arg1, arg2);
+}
+
+export function CreateView(arg1, arg2) {
+  return window['go']['main']['App']['CreateView'](arg1, arg2);
+}
+
+export function CurrentUser() {
+  return window['go']['main']['App']['CurrentUser']();
+}
+
+export function DeleteCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DeleteCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function DeletePriority(arg1, arg2, arg3) {
+  return window['go']['main']['App']['DeletePriority'](arg1, arg2, arg3);
+}
+
+export function DeleteSubtask(arg1, arg2) {
+  return window['go']['main']['App']['DeleteSubtask'](arg1, arg2);
+}
+
+export function DeleteTag(arg1, arg2) {
+  return window['go']['main']['App']['DeleteTag'](arg1, arg2);
+}
+
+export function DeleteTask(arg1, arg2) {
+  return window['go']['main']['App']['DeleteTask'](arg1, arg2);
+}
+
+export function DeleteView(arg1) {
+  return window['go']['main']['App']['DeleteView'](arg1);
 }
 
 export function GetCategories() {
   return window['go']['main']['App']['GetCategories']();
 }
 
+export function GetHistory() {
+  return window['go']['main']['App']['GetHistory']();
+}
+
+export function GetPriorities() {
+  return window['go']['main']['App']['GetPriorities']();
+}
+
+export function GetSeriesHistory(arg1) {
+  return window['go']['main']['App']['GetSeriesHistory'](arg1);
+}
+
+export function GetSortedTasks(arg1, arg2) {
+  return window['go']['main']['App']['GetSortedTasks'](arg1, arg2);
+}
+
 export function GetStats() {
   return window['go']['main']['App']['GetStats']();
 }
@@ -58,26 +118,166 @@ export function GetSubtasks(arg1) {
   return window['go']['main']['App']['GetSubtasks'](arg1);
 }
 
+export function GetTags() {
+  return window['go']['main']['App']['GetTags']();
+}
+
 export function GetTasks(arg1) {
   return window['go']['main']['App']['GetTasks'](arg1);
 }
 
+export function GetTasksPage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['GetTasksPage'](arg1, arg2, arg3);
+}
+
+export function GetTrash() {
+  return window['go']['main']['App']['GetTrash']();
+}
+
+export function GetViewTasks(arg1) {
+  return window['go']['main']['App']['GetViewTasks'](arg1);
+}
+
+export function GetViews() {
+  return window['go']['main']['App']['GetViews']();
+}
+
+export function Login(arg1, arg2) {
+  return window['go']['main']['App']['Login'](arg1, arg2);
+}
+
+export function Logout() {
+  return window['go']['main']['App']['Logout']();
+}
+
+export function MergeTags(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MergeTags'](arg1, arg2, arg3);
+}
+
+export function MoveCategory(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['MoveCategory'](arg1, arg2, arg3, arg4);
+}
+
+export function MoveTask(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveTask'](arg1, arg2, arg3);
+}
+
+export function PatchTask(arg1, arg2) {
+  return window['go']['main']['App']['PatchTask'](arg1, arg2);
+}
+
+export function PreviewDescription(arg1) {
+  return window['go']['main']['App']['PreviewDescription'](arg1);
+}
+
+export function PurgeTrash() {
+  return window['go']['main']['App']['PurgeTrash']();
+}
+
+export function QueryTasks(arg1) {
+  return window['go']['main']['App']['QueryTasks'](arg1);
+}
+
+export function Redo() {
+  return window['go']['main']['App']['Redo']();
+}
+
+export function Register(arg1, arg2) {
+  return window['go']['main']['App']['Register'](arg1, arg2);
+}
+
+export function RenameCategory(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RenameCategory'](arg1, arg2, arg3);
+}
+
+export function RenameView(arg1, arg2) {
+  return window['go']['main']['App']['RenameView'](arg1, arg2);
+}
+
+export function ReorderViews(arg1) {
+  return window['go']['main']['App']['ReorderViews'](arg1);
+}
+
+export function RestoreCategory(arg1) {
+  return window['go']['main']['App']['RestoreCategory'](arg1);
+}
+
+export function RestoreSubtask(arg1) {
+  return window['go']['main']['App']['RestoreSubtask'](arg1);
+}
+
+export function RestoreTask(arg1) {
+  return window['go']['main']['App']['RestoreTask'](arg1);
+}
+
 export function SearchTasks(arg1) {
   return window['go']['main']['App']['SearchTasks'](arg1);
 }
 
-export function SetTaskTags(arg1, arg2) {
-  return window['go']['main']['App']['SetTaskTags'](arg1, arg2);
+export function SearchTasksRanked(arg1) {
+  return window['go']['main']['App']['SearchTasksRanked'](arg1);
+}
+
+export function SetRepeatRule(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetRepeatRule'](arg1, arg2, arg3);
+}
+
+export function SetTaskDescription(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetTaskDescription'](arg1, arg2, arg3);
+}
+
+export function SetTaskTags(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetTaskTags'](arg1, arg2, arg3);
+}
+
+export function SkipOccurrence(arg1, arg2) {
+  return window['go']['main']['App']['SkipOccurrence'](arg1, arg2);
+}
+
+export function SuggestTags(arg1, arg2) {
+  return window['go']['main']['App']['SuggestTags'](arg1, arg2);
+}
+
+export function ToggleSubtask(arg1, arg2) {
+  return window['go']['main']['App']['ToggleSubtask'](arg1, arg2);
+}
+
+export function ToggleTask(arg1, arg2) {
+  return window['go']['main']['App']['ToggleTask'](arg1, arg2);
+}
+
+export function UnarchiveTask(arg1, arg2) {
+  return window['go']['main']['App']['UnarchiveTask'](arg1, arg2);
+}
+
+export function Undo() {
+  return window['go']['main']['App']['Undo']();
+}
+
+export function UpdateCategory(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['UpdateCategory'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function UpdateOccurrence(arg1, arg2, arg3, arg4, arg5, arg6) {
+  return window['go']['main']['App']['UpdateOccurrence'](arg1, arg2, arg3, arg4, arg5, arg6);
+}
+
+export function UpdatePriority(arg1, arg2, arg3, arg4, arg5, arg6, arg7) {
+  return window['go']['main']['App']['UpdatePriority'](arg1, arg2, arg3, arg4, arg5, arg6, arg7);
+}
+
+export function UpdateSubtask(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateSubtask'](arg1, arg2, arg3, arg4);
 }
 
-export function ToggleSubtask(arg1) {
-  return window['go']['main']['App']['ToggleSubtask'](arg1);
+export function UpdateTag(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateTag'](arg1, arg2, arg3, arg4);
 }
 
-export function ToggleTask(arg1) {
-  return window['go']['main']['App']['ToggleTask'](arg1);
+export function UpdateTask(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['UpdateTask'](arg1, arg2, arg3, arg4, arg5);
 }
 
-export function UpdateTask(arg1, arg2, arg3, arg4) {
-  return window['go']['main']['App']['UpdateTask'](arg1, arg2, arg3, arg4);
+export function UpdateView(arg1, arg2, arg3) {
+  return window['go']['main']['App']['UpdateView'](arg1, arg2, arg3);
 }
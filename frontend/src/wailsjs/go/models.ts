@@ -1,8 +1,129 @@
+export namespace dto {
+	
+	export class Priority {
+	    id: number;
+	    name: string;
+	    label: string;
+	    color: string;
+	    weight: number;
+	    default: boolean;
+	    count: number;
+	    version: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Priority(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.color = source["color"];
+	        this.weight = source["weight"];
+	        this.default = source["default"];
+	        this.count = source["count"];
+	        this.version = source["version"];
+	    }
+	}
+	export class Task {
+	    id: number;
+	    title: string;
+	    description: string;
+	    descriptionHtml?: string;
+	    priority: string;
+	    completed: boolean;
+	    createdAt: string;
+	    completedAt?: string;
+	    dueDate?: string;
+	    repeatRule?: string;
+	    categoryId?: number;
+	    tags?: string[];
+	    seriesId?: number;
+	    archivedAt?: string;
+	    deletedAt?: string;
+	    position?: string;
+	    version: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Task(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.descriptionHtml = source["descriptionHtml"];
+	        this.priority = source["priority"];
+	        this.completed = source["completed"];
+	        this.createdAt = source["createdAt"];
+	        this.completedAt = source["completedAt"];
+	        this.dueDate = source["dueDate"];
+	        this.repeatRule = source["repeatRule"];
+	        this.categoryId = source["categoryId"];
+	        this.tags = source["tags"];
+	        this.seriesId = source["seriesId"];
+	        this.archivedAt = source["archivedAt"];
+	        this.deletedAt = source["deletedAt"];
+	        this.position = source["position"];
+	        this.version = source["version"];
+	    }
+	}
+	export class TaskFilter {
+	    status: string;
+	    priority: string;
+	    dateFilter: string;
+	    search: string;
+	    categoryId?: number;
+	    categoryName?: string;
+	    includeSubcategories?: boolean;
+	    from?: string;
+	    to?: string;
+	    sort: string;
+	    terms?: string[];
+	    excludeTerms?: string[];
+	    tags?: string[];
+	    excludeTags?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.dateFilter = source["dateFilter"];
+	        this.search = source["search"];
+	        this.categoryId = source["categoryId"];
+	        this.categoryName = source["categoryName"];
+	        this.includeSubcategories = source["includeSubcategories"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.sort = source["sort"];
+	        this.terms = source["terms"];
+	        this.excludeTerms = source["excludeTerms"];
+	        this.tags = source["tags"];
+	        this.excludeTags = source["excludeTags"];
+	    }
+	}
+
+}
+
 export namespace main {
 	
 	export class CategoryDTO {
 	    id: number;
 	    name: string;
+	    parentId?: number;
+	    position: number;
+	    color: string;
+	    icon: string;
+	    taskCount: number;
+	    totalCount: number;
+	    version: number;
+	    deletedAt?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new CategoryDTO(source);
@@ -12,13 +133,120 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.name = source["name"];
+	        this.parentId = source["parentId"];
+	        this.position = source["position"];
+	        this.color = source["color"];
+	        this.icon = source["icon"];
+	        this.taskCount = source["taskCount"];
+	        this.totalCount = source["totalCount"];
+	        this.version = source["version"];
+	        this.deletedAt = source["deletedAt"];
+	    }
+	}
+	export class HistoryDTO {
+	    undo: string;
+	    redo: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.undo = source["undo"];
+	        this.redo = source["redo"];
+	    }
+	}
+	export class OccurrenceDTO {
+	    occurrenceAt: string;
+	    status: string;
+	    taskId?: number;
+	    title?: string;
+	    dueDate?: string;
+	    completedAt?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OccurrenceDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.occurrenceAt = source["occurrenceAt"];
+	        this.status = source["status"];
+	        this.taskId = source["taskId"];
+	        this.title = source["title"];
+	        this.dueDate = source["dueDate"];
+	        this.completedAt = source["completedAt"];
+	    }
+	}
+	export class PriorityDTO {
+	    id: number;
+	    name: string;
+	    label: string;
+	    color: string;
+	    weight: number;
+	    default: boolean;
+	    count: number;
+	    version: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PriorityDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.label = source["label"];
+	        this.color = source["color"];
+	        this.weight = source["weight"];
+	        this.default = source["default"];
+	        this.count = source["count"];
+	        this.version = source["version"];
 	    }
 	}
+	export class SearchResultDTO {
+	    task: dto.Task;
+	    rank: number;
+	    title: string;
+	    snippet?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResultDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.task = this.convertValues(source["task"], dto.Task);
+	        this.rank = source["rank"];
+	        this.title = source["title"];
+	        this.snippet = source["snippet"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class StatsDTO {
 	    total: number;
 	    active: number;
 	    completed: number;
 	    overdue: number;
+	    priorities: dto.Priority[];
 	
 	    static createFrom(source: any = {}) {
 	        return new StatsDTO(source);
@@ -30,7 +258,26 @@ export namespace main {
 	        this.active = source["active"];
 	        this.completed = source["completed"];
 	        this.overdue = source["overdue"];
+	        this.priorities = this.convertValues(source["priorities"], dto.Priority);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class SubtaskDTO {
 	    id: number;
@@ -38,6 +285,8 @@ export namespace main {
 	    title: string;
 	    completed: boolean;
 	    createdAt: string;
+	    version: number;
+	    deletedAt?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new SubtaskDTO(source);
@@ -50,18 +299,48 @@ export namespace main {
 	        this.title = source["title"];
 	        this.completed = source["completed"];
 	        this.createdAt = source["createdAt"];
+	        this.version = source["version"];
+	        this.deletedAt = source["deletedAt"];
+	    }
+	}
+	export class TagDTO {
+	    id: number;
+	    name: string;
+	    color: string;
+	    count: number;
+	    version: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.count = source["count"];
+	        this.version = source["version"];
 	    }
 	}
 	export class TaskDTO {
 	    id: number;
 	    title: string;
+	    description: string;
+	    descriptionHtml?: string;
 	    priority: string;
 	    completed: boolean;
 	    createdAt: string;
 	    completedAt?: string;
 	    dueDate?: string;
+	    repeatRule?: string;
 	    categoryId?: number;
 	    tags?: string[];
+	    seriesId?: number;
+	    archivedAt?: string;
+	    deletedAt?: string;
+	    position?: string;
+	    version: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new TaskDTO(source);
@@ -71,15 +350,207 @@ export namespace main {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.descriptionHtml = source["descriptionHtml"];
 	        this.priority = source["priority"];
 	        this.completed = source["completed"];
 	        this.createdAt = source["createdAt"];
 	        this.completedAt = source["completedAt"];
 	        this.dueDate = source["dueDate"];
+	        this.repeatRule = source["repeatRule"];
+	        this.categoryId = source["categoryId"];
+	        this.tags = source["tags"];
+	        this.seriesId = source["seriesId"];
+	        this.archivedAt = source["archivedAt"];
+	        this.deletedAt = source["deletedAt"];
+	        this.position = source["position"];
+	        this.version = source["version"];
+	    }
+	}
+	export class TaskFilterDTO {
+	    status: string;
+	    priority: string;
+	    dateFilter: string;
+	    search: string;
+	    categoryId?: number;
+	    categoryName?: string;
+	    includeSubcategories?: boolean;
+	    from?: string;
+	    to?: string;
+	    sort: string;
+	    terms?: string[];
+	    excludeTerms?: string[];
+	    tags?: string[];
+	    excludeTags?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskFilterDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.priority = source["priority"];
+	        this.dateFilter = source["dateFilter"];
+	        this.search = source["search"];
+	        this.categoryId = source["categoryId"];
+	        this.categoryName = source["categoryName"];
+	        this.includeSubcategories = source["includeSubcategories"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.sort = source["sort"];
+	        this.terms = source["terms"];
+	        this.excludeTerms = source["excludeTerms"];
+	        this.tags = source["tags"];
+	        this.excludeTags = source["excludeTags"];
+	    }
+	}
+	export class TaskPageDTO {
+	    items: dto.Task[];
+	    next?: string;
+	    prev?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPageDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.items = this.convertValues(source["items"], dto.Task);
+	        this.next = source["next"];
+	        this.prev = source["prev"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPatchDTO {
+	    title?: string;
+	    description?: string;
+	    priority?: string;
+	    dueDate?: string;
+	    repeatRule?: string;
+	    categoryId?: number;
+	    tags?: string[];
+	    version?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPatchDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.priority = source["priority"];
+	        this.dueDate = source["dueDate"];
+	        this.repeatRule = source["repeatRule"];
 	        this.categoryId = source["categoryId"];
 	        this.tags = source["tags"];
+	        this.version = source["version"];
+	    }
+	}
+	export class TrashDTO {
+	    tasks: TaskDTO[];
+	    subtasks: SubtaskDTO[];
+	    categories: CategoryDTO[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TrashDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tasks = this.convertValues(source["tasks"], TaskDTO);
+	        this.subtasks = this.convertValues(source["subtasks"], SubtaskDTO);
+	        this.categories = this.convertValues(source["categories"], CategoryDTO);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UserDTO {
+	    id: number;
+	    email: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UserDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.email = source["email"];
 	    }
 	}
+	export class ViewDTO {
+	    id: number;
+	    name: string;
+	    filter: dto.TaskFilter;
+	    position: number;
+	    builtIn: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ViewDTO(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.filter = this.convertValues(source["filter"], dto.TaskFilter);
+	        this.position = source["position"];
+	        this.builtIn = source["builtIn"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 